@@ -0,0 +1,309 @@
+// Package acme Solves ACME DNS-01 challenges with Aurora DNS.
+//
+// Present creates the TXT record of the challenge (_acme-challenge.<domain>) in the zone of the domain,
+// and waits until the authoritative name servers of the zone serve it.
+// CleanUp deletes the record created by Present.
+//
+// The methods have the same signatures as the lego challenge.Provider interface:
+//
+//	provider, err := acme.NewProvider(client, nil)
+//	if err != nil {
+//		return err
+//	}
+//
+//	err = provider.Present(domain, token, keyAuth)
+package acme
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/nrdcg/auroradns"
+	"github.com/nrdcg/auroradns/propagation"
+)
+
+// Default values of the configuration.
+const (
+	DefaultTTL                = 300
+	DefaultPropagationTimeout = 2 * time.Minute
+	DefaultPollingInterval    = 2 * time.Second
+	DefaultDNSTimeout         = propagation.DefaultTimeout
+	DefaultPort               = propagation.DefaultPort
+)
+
+const challengeLabel = "_acme-challenge"
+
+// Config the configuration of a Provider.
+type Config struct {
+	// TTL of the TXT records (DefaultTTL by default).
+	TTL int
+
+	// PropagationTimeout maximum time to wait for the name servers to serve the TXT record (DefaultPropagationTimeout by default).
+	PropagationTimeout time.Duration
+
+	// PollingInterval time between two checks of the name servers (DefaultPollingInterval by default).
+	PollingInterval time.Duration
+
+	// DisablePropagationCheck Present returns as soon as the record is created.
+	DisablePropagationCheck bool
+
+	// Resolvers the recursive resolvers ("host:port") used to resolve the names of the name servers.
+	// By default, the resolvers of /etc/resolv.conf.
+	Resolvers []string
+
+	// Nameservers the name servers ("host:port") to poll.
+	// By default, the name servers of the apex NS records of the zone (see propagation.Checker.Nameservers).
+	Nameservers []string
+
+	// Port of the name servers of the zone (DefaultPort by default).
+	Port string
+
+	// DNSTimeout timeout of a DNS query (DefaultDNSTimeout by default).
+	DNSTimeout time.Duration
+
+	// Owner identifies the issuer of the challenges, without spaces (the host name by default).
+	// Present creates an ownership marker next to each challenge record (see Marker and Sweep):
+	// the challenges left behind by crashed runs can be deleted by Sweep.
+	Owner string
+}
+
+// Provider Solves ACME DNS-01 challenges.
+// A Provider is safe for concurrent use: several challenges for the same name can be presented at the same time.
+type Provider struct {
+	client  *auroradns.Client
+	config  Config
+	checker *propagation.Checker
+
+	mu      sync.Mutex
+	records map[challengeKey][]challengeRecord
+}
+
+// challengeKey identifies a challenge: several challenges for the same name (e.g. example.com and *.example.com) have different values.
+type challengeKey struct {
+	fqdn  string
+	value string
+}
+
+type challengeRecord struct {
+	zoneID   string
+	recordID string
+	markerID string
+}
+
+// NewProvider Creates a new Provider.
+// A nil configuration uses the default values.
+func NewProvider(client *auroradns.Client, config *Config) (*Provider, error) {
+	if client == nil {
+		return nil, errors.New("the client is required")
+	}
+
+	if config != nil && strings.ContainsFunc(config.Owner, unicode.IsSpace) {
+		return nil, fmt.Errorf("invalid owner %q: must not contain spaces", config.Owner)
+	}
+
+	cfg := Config{}
+	if config != nil {
+		cfg = *config
+	}
+
+	if cfg.Owner == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("the owner is required: %w", err)
+		}
+
+		cfg.Owner = strings.Join(strings.Fields(hostname), "-")
+	}
+
+	if cfg.TTL <= 0 {
+		cfg.TTL = DefaultTTL
+	}
+
+	if cfg.PropagationTimeout <= 0 {
+		cfg.PropagationTimeout = DefaultPropagationTimeout
+	}
+
+	if cfg.PollingInterval <= 0 {
+		cfg.PollingInterval = DefaultPollingInterval
+	}
+
+	if cfg.DNSTimeout <= 0 {
+		cfg.DNSTimeout = DefaultDNSTimeout
+	}
+
+	if cfg.Port == "" {
+		cfg.Port = DefaultPort
+	}
+
+	return &Provider{
+		client: client,
+		config: cfg,
+		checker: propagation.NewChecker(client, &propagation.Config{
+			Resolvers:       cfg.Resolvers,
+			Nameservers:     cfg.Nameservers,
+			Port:            cfg.Port,
+			Timeout:         cfg.DNSTimeout,
+			InitialInterval: cfg.PollingInterval,
+			MaxInterval:     cfg.PollingInterval,
+		}),
+		records: make(map[challengeKey][]challengeRecord),
+	}, nil
+}
+
+// ChallengeRecord Returns the name (fully qualified, without the trailing dot) and the value of the TXT record of a challenge.
+func ChallengeRecord(domain, keyAuth string) (string, string) {
+	domain = auroradns.NormalizeZoneName(strings.TrimPrefix(domain, "*."))
+
+	sum := sha256.Sum256([]byte(keyAuth))
+
+	return challengeLabel + "." + domain, base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// Present Creates the TXT record of a challenge and waits until the name servers serve it.
+func (p *Provider) Present(domain, token, keyAuth string) error {
+	return p.PresentWithContext(context.Background(), domain, token, keyAuth)
+}
+
+// PresentWithContext Creates the TXT record of a challenge and waits until the name servers serve it.
+func (p *Provider) PresentWithContext(ctx context.Context, domain, _, keyAuth string) error {
+	fqdn, value := ChallengeRecord(domain, keyAuth)
+
+	zone, err := p.findZone(ctx, fqdn)
+	if err != nil {
+		return err
+	}
+
+	record := auroradns.Record{
+		RecordType: auroradns.RecordTypeTXT,
+		Name:       auroradns.NormalizeName(fqdn, zone.Name),
+		Content:    value,
+		TTL:        p.config.TTL,
+	}
+
+	created, _, err := p.client.CreateRecordWithContext(ctx, zone.ID, record)
+	if err != nil {
+		return fmt.Errorf("failed to create the TXT record %s: %w", fqdn, err)
+	}
+
+	challenge := challengeRecord{zoneID: zone.ID, recordID: created.ID}
+
+	marker := Marker{Owner: p.config.Owner, Created: time.Now().UTC().Truncate(time.Second), RecordID: created.ID}
+
+	record.Content = marker.String()
+
+	createdMarker, _, err := p.client.CreateRecordWithContext(ctx, zone.ID, record)
+	if err != nil {
+		_, _, _ = p.client.DeleteRecordWithContext(ctx, zone.ID, created.ID)
+
+		return fmt.Errorf("failed to create the ownership marker of %s: %w", fqdn, err)
+	}
+
+	challenge.markerID = createdMarker.ID
+
+	key := challengeKey{fqdn: fqdn, value: value}
+
+	p.mu.Lock()
+	p.records[key] = append(p.records[key], challenge)
+	p.mu.Unlock()
+
+	if p.config.DisablePropagationCheck {
+		return nil
+	}
+
+	return p.waitForRecord(ctx, zone, fqdn, value)
+}
+
+// CleanUp Deletes the TXT record created by Present for a challenge.
+// The records of the other challenges for the same name are kept.
+func (p *Provider) CleanUp(domain, token, keyAuth string) error {
+	return p.CleanUpWithContext(context.Background(), domain, token, keyAuth)
+}
+
+// CleanUpWithContext Deletes the TXT record created by Present for a challenge.
+// The records of the other challenges for the same name are kept.
+func (p *Provider) CleanUpWithContext(ctx context.Context, domain, _, keyAuth string) error {
+	fqdn, value := ChallengeRecord(domain, keyAuth)
+	key := challengeKey{fqdn: fqdn, value: value}
+
+	p.mu.Lock()
+	records, ok := p.records[key]
+	delete(p.records, key)
+	p.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("unknown challenge for %s", fqdn)
+	}
+
+	var errs []error
+
+	for _, record := range records {
+		_, _, err := p.client.DeleteRecordWithContext(ctx, record.zoneID, record.recordID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to delete the TXT record %s (%s): %w", fqdn, record.recordID, err))
+
+			// keeps the records that have not been deleted, to allow another attempt.
+			p.mu.Lock()
+			p.records[key] = append(p.records[key], record)
+			p.mu.Unlock()
+
+			continue
+		}
+
+		// a marker left behind is removed by Sweep.
+		_, _, err = p.client.DeleteRecordWithContext(ctx, record.zoneID, record.markerID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to delete the ownership marker of %s (%s): %w", fqdn, record.markerID, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// findZone Returns the zone with the longest name containing fqdn.
+func (p *Provider) findZone(ctx context.Context, fqdn string) (auroradns.Zone, error) {
+	zones, _, err := p.client.ListZonesWithContext(ctx)
+	if err != nil {
+		return auroradns.Zone{}, fmt.Errorf("failed to list the zones: %w", err)
+	}
+
+	var found auroradns.Zone
+
+	for _, zone := range zones {
+		name := auroradns.NormalizeZoneName(zone.Name)
+
+		if !strings.HasSuffix(fqdn, "."+name) || len(name) <= len(auroradns.NormalizeZoneName(found.Name)) {
+			continue
+		}
+
+		found = zone
+	}
+
+	if found.ID == "" {
+		return auroradns.Zone{}, fmt.Errorf("no zone found for %s", fqdn)
+	}
+
+	return found, nil
+}
+
+// waitForRecord Polls the name servers of the zone until all of them serve the TXT record.
+func (p *Provider) waitForRecord(ctx context.Context, zone auroradns.Zone, fqdn, value string) error {
+	ctx, cancel := context.WithTimeout(ctx, p.config.PropagationTimeout)
+	defer cancel()
+
+	rrset := propagation.RRset{Name: fqdn, Type: auroradns.RecordTypeTXT, Contents: []string{value}}
+
+	_, err := p.checker.WaitForPropagation(ctx, zone, rrset)
+	if err != nil {
+		return fmt.Errorf("the challenge is not propagated after %s: %w", p.config.PropagationTimeout, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,210 @@
+package acme
+
+import (
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nrdcg/auroradns"
+	"github.com/nrdcg/auroradns/acme/internal/fakedns"
+	"github.com/nrdcg/auroradns/internal/fakeapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTest(t *testing.T, options ...func(*Config)) (*Provider, *fakeapi.Server, *fakedns.Server) {
+	t.Helper()
+
+	api := fakeapi.NewServer("", "")
+	t.Cleanup(api.Close)
+
+	client, err := auroradns.NewClient(nil, auroradns.WithBaseURL(api.URL))
+	require.NoError(t, err)
+
+	dnsServer, err := fakedns.NewServer(api)
+	require.NoError(t, err)
+
+	t.Cleanup(dnsServer.Close)
+
+	config := &Config{
+		PropagationTimeout: time.Second,
+		PollingInterval:    10 * time.Millisecond,
+		Resolvers:          []string{dnsServer.Addr},
+		Port:               dnsServer.Port(),
+	}
+
+	for _, option := range options {
+		option(config)
+	}
+
+	provider, err := NewProvider(client, config)
+	require.NoError(t, err)
+
+	return provider, api, dnsServer
+}
+
+func txtRecords(api *fakeapi.Server, zoneID string) []fakeapi.Record {
+	var records []fakeapi.Record
+
+	for _, record := range api.Records(zoneID) {
+		if record.Type == auroradns.RecordTypeTXT {
+			records = append(records, record)
+		}
+	}
+
+	return records
+}
+
+// challengeRecords Returns the TXT records of the zone, without the ownership markers.
+func challengeRecords(api *fakeapi.Server, zoneID string) []fakeapi.Record {
+	var records []fakeapi.Record
+
+	for _, record := range txtRecords(api, zoneID) {
+		if _, ok := ParseMarker(record.Content); !ok {
+			records = append(records, record)
+		}
+	}
+
+	return records
+}
+
+func TestChallengeRecord(t *testing.T) {
+	fqdn, value := ChallengeRecord("*.Example.COM.", "token.thumbprint")
+
+	assert.Equal(t, "_acme-challenge.example.com", fqdn)
+	assert.Equal(t, "61rBZ_4knHblO0MNoxFsXZ_eTFUHum0B6IVRbhvUn5I", value)
+}
+
+func TestProvider(t *testing.T) {
+	provider, api, dnsServer := setupTest(t)
+
+	api.AddZone("example.com")
+	zoneID := api.AddZone("sub.example.com")
+
+	dnsServer.SetLag(3)
+
+	err := provider.PresentWithContext(t.Context(), "www.sub.example.com", "token", "keyAuth")
+	require.NoError(t, err)
+
+	_, value := ChallengeRecord("www.sub.example.com", "keyAuth")
+
+	expected := []fakeapi.Record{{ID: "record-7", Type: "TXT", Name: "_acme-challenge.www", Content: value, TTL: DefaultTTL}}
+	assert.Equal(t, expected, challengeRecords(api, zoneID))
+
+	assert.Greater(t, dnsServer.Queries(), 3)
+
+	err = provider.CleanUpWithContext(t.Context(), "www.sub.example.com", "token", "keyAuth")
+	require.NoError(t, err)
+
+	assert.Empty(t, txtRecords(api, zoneID))
+
+	err = provider.CleanUpWithContext(t.Context(), "www.sub.example.com", "token", "keyAuth")
+	require.EqualError(t, err, "unknown challenge for _acme-challenge.www.sub.example.com")
+}
+
+func TestProvider_concurrent(t *testing.T) {
+	provider, api, _ := setupTest(t)
+
+	zoneID := api.AddZone("example.com")
+
+	domains := []string{"example.com", "*.example.com"}
+
+	var wg sync.WaitGroup
+
+	for _, domain := range domains {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			assert.NoError(t, provider.PresentWithContext(t.Context(), domain, "token", "keyAuth-"+domain))
+		}()
+	}
+
+	wg.Wait()
+
+	require.Len(t, challengeRecords(api, zoneID), 2)
+
+	err := provider.CleanUpWithContext(t.Context(), "*.example.com", "token", "keyAuth-*.example.com")
+	require.NoError(t, err)
+
+	_, value := ChallengeRecord("example.com", "keyAuth-example.com")
+
+	records := challengeRecords(api, zoneID)
+	require.Len(t, records, 1)
+	assert.Equal(t, value, records[0].Content)
+}
+
+func TestProvider_timeout(t *testing.T) {
+	provider, api, dnsServer := setupTest(t, func(config *Config) {
+		config.PropagationTimeout = 100 * time.Millisecond
+	})
+
+	zoneID := api.AddZone("example.com")
+
+	dnsServer.SetLag(1000)
+
+	err := provider.PresentWithContext(t.Context(), "example.com", "token", "keyAuth")
+	require.ErrorContains(t, err, "the challenge is not propagated after 100ms: TXT _acme-challenge.example.com is not served by 127.0.0.1:"+dnsServer.Port())
+
+	// the record can be deleted.
+	err = provider.CleanUpWithContext(t.Context(), "example.com", "token", "keyAuth")
+	require.NoError(t, err)
+
+	assert.Empty(t, txtRecords(api, zoneID))
+}
+
+func TestProvider_nameservers(t *testing.T) {
+	provider, api, _ := setupTest(t, func(config *Config) {
+		// the name servers of the zone are not used.
+		config.Nameservers = config.Resolvers
+		config.Resolvers = []string{"127.0.0.1:1"}
+	})
+
+	api.AddZone("example.com")
+
+	err := provider.PresentWithContext(t.Context(), "example.com", "token", "keyAuth")
+	require.NoError(t, err)
+}
+
+func TestProvider_noZone(t *testing.T) {
+	provider, api, _ := setupTest(t)
+
+	api.AddZone("example.com")
+
+	err := provider.PresentWithContext(t.Context(), "example.org", "token", "keyAuth")
+	require.EqualError(t, err, "no zone found for _acme-challenge.example.org")
+
+	assert.Empty(t, api.Mutations())
+}
+
+func TestProvider_owner(t *testing.T) {
+	provider, api, _ := setupTest(t, func(config *Config) {
+		config.Owner = "host1"
+	})
+
+	zoneID := api.AddZone("example.com")
+
+	err := provider.PresentWithContext(t.Context(), "example.com", "token", "keyAuth")
+	require.NoError(t, err)
+
+	require.Len(t, txtRecords(api, zoneID), 2)
+
+	err = provider.CleanUpWithContext(t.Context(), "example.com", "token", "keyAuth")
+	require.NoError(t, err)
+
+	assert.Empty(t, txtRecords(api, zoneID))
+
+	_, err = NewProvider(provider.client, &Config{Owner: "host 1"})
+	require.EqualError(t, err, `invalid owner "host 1": must not contain spaces`)
+
+	hostname, err := os.Hostname()
+	require.NoError(t, err)
+
+	provider, err = NewProvider(provider.client, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, strings.Join(strings.Fields(hostname), "-"), provider.config.Owner)
+}
@@ -0,0 +1,213 @@
+// Package fakedns A DNS server serving the records of a fakeapi.Server, for the tests.
+package fakedns
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"sync/atomic"
+
+	"github.com/miekg/dns"
+	"github.com/nrdcg/auroradns"
+	"github.com/nrdcg/auroradns/internal/fakeapi"
+)
+
+// Server An authoritative DNS server for the zones of a fakeapi.Server, listening on 127.0.0.1 over UDP and TCP.
+//
+// The name servers of the zones (the content of the apex NS records) are resolved to 127.0.0.1,
+// so the server can also be used as a resolver.
+type Server struct {
+	// Addr the address of the server ("127.0.0.1:port").
+	Addr string
+
+	api     *fakeapi.Server
+	servers []*dns.Server
+
+	lag     atomic.Int64
+	queries atomic.Int64
+}
+
+// NewServer Starts a server.
+func NewServer(api *fakeapi.Server) (*Server, error) {
+	s := &Server{api: api}
+
+	var err error
+
+	// the TCP port can be used by another process over UDP.
+	for range 10 {
+		err = s.listen()
+		if err == nil {
+			return s, nil
+		}
+	}
+
+	return nil, err
+}
+
+func (s *Server) listen() error {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return err
+	}
+
+	conn, err := net.ListenPacket("udp", listener.Addr().String())
+	if err != nil {
+		_ = listener.Close()
+		return err
+	}
+
+	s.Addr = listener.Addr().String()
+	s.servers = []*dns.Server{
+		{Listener: listener, Handler: s},
+		{PacketConn: conn, Handler: s},
+	}
+
+	for _, server := range s.servers {
+		started := make(chan struct{})
+		server.NotifyStartedFunc = func() { close(started) }
+
+		go func() { _ = server.ActivateAndServe() }()
+
+		<-started
+	}
+
+	return nil
+}
+
+// Close Stops the server.
+func (s *Server) Close() {
+	for _, server := range s.servers {
+		_ = server.Shutdown()
+	}
+}
+
+// Port Returns the port of the server.
+func (s *Server) Port() string {
+	_, port, _ := net.SplitHostPort(s.Addr)
+	return port
+}
+
+// SetLag Serves the records only after n queries, to simulate a server that is not up to date.
+// The name servers of the zones and their addresses are always served.
+func (s *Server) SetLag(n int) {
+	s.lag.Store(int64(n))
+}
+
+// Queries Returns the number of queries for the records.
+func (s *Server) Queries() int {
+	return int(s.queries.Load())
+}
+
+// ServeDNS Answers a query.
+func (s *Server) ServeDNS(w dns.ResponseWriter, query *dns.Msg) {
+	msg := new(dns.Msg)
+	msg.SetReply(query)
+	msg.Authoritative = true
+
+	if len(query.Question) != 1 {
+		msg.Rcode = dns.RcodeFormatError
+		_ = w.WriteMsg(msg)
+
+		return
+	}
+
+	question := query.Question[0]
+	name := auroradns.NormalizeZoneName(question.Name)
+	qtype := dns.TypeToString[question.Qtype]
+
+	answer, err := s.answer(name, qtype)
+
+	switch {
+	case errors.Is(err, errNotFound):
+		msg.Rcode = dns.RcodeNameError
+	case err != nil:
+		msg.Rcode = dns.RcodeRefused
+	}
+
+	for _, rr := range answer {
+		rr.Header().Name = question.Name
+		msg.Answer = append(msg.Answer, rr)
+	}
+
+	_ = w.WriteMsg(msg)
+}
+
+var errNotFound = errors.New("not found")
+
+func (s *Server) answer(name, qtype string) ([]dns.RR, error) {
+	var zone *fakeapi.Zone
+
+	for _, z := range s.api.Zones() {
+		if name == z.Name || strings.HasSuffix(name, "."+z.Name) {
+			if zone == nil || len(z.Name) > len(zone.Name) {
+				zone = &z
+			}
+		}
+
+		if qtype == dns.TypeToString[dns.TypeA] && s.isNameserver(z, name) {
+			return []dns.RR{&dns.A{Hdr: header(dns.TypeA, 300), A: net.IPv4(127, 0, 0, 1)}}, nil
+		}
+	}
+
+	if zone == nil {
+		return nil, errors.New("refused")
+	}
+
+	records := s.api.Records(zone.ID)
+
+	isNS := qtype == auroradns.RecordTypeNS && name == zone.Name
+
+	if !isNS && s.queries.Add(1) <= s.lag.Load() {
+		return nil, nil
+	}
+
+	var answer []dns.RR
+
+	exists := name == zone.Name
+
+	for _, record := range records {
+		if auroradns.NormalizeName(record.Name, zone.Name) != auroradns.NormalizeName(name, zone.Name) {
+			continue
+		}
+
+		exists = true
+
+		if !strings.EqualFold(record.Type, qtype) {
+			continue
+		}
+
+		content := record.Content
+		if strings.EqualFold(record.Type, auroradns.RecordTypeTXT) {
+			content = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(content) + `"`
+		}
+
+		rr, err := dns.NewRR(". " + record.Type + " " + content)
+		if err != nil {
+			continue
+		}
+
+		rr.Header().Ttl = uint32(max(record.TTL, 0))
+
+		answer = append(answer, rr)
+	}
+
+	if !exists {
+		return nil, errNotFound
+	}
+
+	return answer, nil
+}
+
+func (s *Server) isNameserver(zone fakeapi.Zone, name string) bool {
+	for _, record := range s.api.Records(zone.ID) {
+		if strings.EqualFold(record.Type, auroradns.RecordTypeNS) && auroradns.NormalizeZoneName(record.Content) == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func header(rrtype uint16, ttl uint32) dns.RR_Header {
+	return dns.RR_Header{Rrtype: rrtype, Class: dns.ClassINET, Ttl: ttl}
+}
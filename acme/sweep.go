@@ -0,0 +1,299 @@
+package acme
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/nrdcg/auroradns"
+)
+
+const markerPrefix = "auroradns-acme"
+
+// Marker The ownership marker of a challenge record.
+//
+// The API does not expose the creation time of the records:
+// Present creates a TXT record next to the challenge record,
+// with the owner, the creation time and the ID of the challenge record
+// (e.g. "auroradns-acme owner=host1 created=2024-01-02T03:04:05Z record=record-42").
+// The ACME servers ignore the TXT records that do not match the challenge.
+type Marker struct {
+	Owner    string    `json:"owner"`
+	Created  time.Time `json:"created"`
+	RecordID string    `json:"record_id"`
+}
+
+// String Returns the content of the marker record.
+func (m Marker) String() string {
+	return fmt.Sprintf("%s owner=%s created=%s record=%s", markerPrefix, m.Owner, m.Created.UTC().Format(time.RFC3339), m.RecordID)
+}
+
+// ParseMarker Parses the content of a marker record.
+// It returns false if the content is not a marker.
+func ParseMarker(content string) (Marker, bool) {
+	fields := strings.Fields(auroradns.NormalizeContent(auroradns.RecordTypeTXT, content))
+	if len(fields) != 4 || fields[0] != markerPrefix {
+		return Marker{}, false
+	}
+
+	var marker Marker
+
+	for _, field := range fields[1:] {
+		key, value, _ := strings.Cut(field, "=")
+
+		switch key {
+		case "owner":
+			marker.Owner = value
+		case "created":
+			created, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return Marker{}, false
+			}
+
+			marker.Created = created
+		case "record":
+			marker.RecordID = value
+		}
+	}
+
+	if marker.Owner == "" || marker.Created.IsZero() || marker.RecordID == "" {
+		return Marker{}, false
+	}
+
+	return marker, true
+}
+
+// SweepOptions The options of Sweep.
+type SweepOptions struct {
+	// MaxAge the age from which a challenge is stale (required).
+	MaxAge time.Duration
+
+	// Owner selects the markers by owner (all the owners by default).
+	Owner func(owner string) bool
+
+	// DryRun reports the stale challenges without deleting them.
+	DryRun bool
+
+	// Now returns the current time (time.Now by default).
+	Now func() time.Time
+}
+
+// SweepReport The result of Sweep.
+type SweepReport struct {
+	DryRun bool `json:"dry_run"`
+
+	// Stale the stale challenges: deleted, or to delete with DryRun.
+	Stale []StaleChallenge `json:"stale,omitempty"`
+
+	// Unmarked the challenge records without ownership marker.
+	// Their age is unknown, so they are kept.
+	Unmarked []auroradns.ZoneRecord `json:"unmarked,omitempty"`
+}
+
+// StaleChallenge A challenge older than SweepOptions.MaxAge.
+type StaleChallenge struct {
+	Zone   auroradns.Zone `json:"zone"`
+	Marker Marker         `json:"marker"`
+	Age    time.Duration  `json:"age"`
+
+	// MarkerRecord the marker record.
+	MarkerRecord auroradns.Record `json:"marker_record"`
+
+	// Record the challenge record, nil if it does not exist anymore.
+	Record *auroradns.Record `json:"record,omitempty"`
+
+	// Deleted true if the records have been deleted.
+	Deleted bool `json:"deleted"`
+}
+
+// Sweep Finds the challenge TXT records (_acme-challenge and _acme-challenge.*) of all the zones,
+// and deletes the challenges with an ownership marker older than options.MaxAge.
+// The challenge records without marker (created by other clients) are reported, but never deleted.
+//
+// The deletion errors do not stop the sweep: they are returned together with the report.
+func Sweep(ctx context.Context, client *auroradns.Client, options SweepOptions) (*SweepReport, error) {
+	if options.MaxAge <= 0 {
+		return nil, errors.New("the maximum age must be positive")
+	}
+
+	now := time.Now
+	if options.Now != nil {
+		now = options.Now
+	}
+
+	results, err := client.SearchRecords(ctx, auroradns.RecordQuery{
+		Types:    []string{auroradns.RecordTypeTXT},
+		NameGlob: challengeLabel + "*",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the challenge records: %w", err)
+	}
+
+	report := &SweepReport{DryRun: options.DryRun}
+
+	var markers []auroradns.ZoneRecord
+
+	challenges := make(map[string]auroradns.ZoneRecord)
+
+	for _, result := range results {
+		if !isChallengeName(result.Record.Name) {
+			continue
+		}
+
+		if _, ok := ParseMarker(result.Record.Content); ok {
+			markers = append(markers, result)
+			continue
+		}
+
+		challenges[result.Zone.ID+"/"+result.Record.ID] = result
+	}
+
+	var errs []error
+
+	for _, result := range markers {
+		marker, _ := ParseMarker(result.Record.Content)
+
+		challenge, found := challenges[result.Zone.ID+"/"+marker.RecordID]
+		delete(challenges, result.Zone.ID+"/"+marker.RecordID)
+
+		age := now().Sub(marker.Created)
+
+		if age < options.MaxAge || (options.Owner != nil && !options.Owner(marker.Owner)) {
+			continue
+		}
+
+		stale := StaleChallenge{Zone: result.Zone, Marker: marker, Age: age, MarkerRecord: result.Record}
+
+		if found {
+			stale.Record = &challenge.Record
+		}
+
+		if !options.DryRun {
+			err = deleteChallenge(ctx, client, stale)
+			if err != nil {
+				errs = append(errs, err)
+			}
+
+			stale.Deleted = err == nil
+		}
+
+		report.Stale = append(report.Stale, stale)
+	}
+
+	for _, challenge := range challenges {
+		report.Unmarked = append(report.Unmarked, challenge)
+	}
+
+	slices.SortFunc(report.Stale, func(a, b StaleChallenge) int {
+		return cmp.Or(
+			cmp.Compare(a.Zone.Name, b.Zone.Name),
+			cmp.Compare(a.MarkerRecord.Name, b.MarkerRecord.Name),
+			a.Marker.Created.Compare(b.Marker.Created),
+			cmp.Compare(a.MarkerRecord.ID, b.MarkerRecord.ID),
+		)
+	})
+
+	slices.SortFunc(report.Unmarked, func(a, b auroradns.ZoneRecord) int {
+		return cmp.Or(
+			cmp.Compare(a.Zone.Name, b.Zone.Name),
+			cmp.Compare(a.Record.Name, b.Record.Name),
+			cmp.Compare(a.Record.ID, b.Record.ID),
+		)
+	})
+
+	return report, errors.Join(errs...)
+}
+
+// deleteChallenge Deletes the challenge record, then its marker.
+func deleteChallenge(ctx context.Context, client *auroradns.Client, stale StaleChallenge) error {
+	if stale.Record != nil {
+		_, _, err := client.DeleteRecordWithContext(ctx, stale.Zone.ID, stale.Record.ID)
+		if err != nil {
+			return fmt.Errorf("failed to delete the challenge record %s of %s: %w", stale.Record.ID, stale.Zone.Name, err)
+		}
+	}
+
+	_, _, err := client.DeleteRecordWithContext(ctx, stale.Zone.ID, stale.MarkerRecord.ID)
+	if err != nil {
+		return fmt.Errorf("failed to delete the ownership marker %s of %s: %w", stale.MarkerRecord.ID, stale.Zone.Name, err)
+	}
+
+	return nil
+}
+
+// String Returns the text rendering of the report (see WriteText).
+func (r *SweepReport) String() string {
+	buf := new(strings.Builder)
+
+	_ = r.WriteText(buf)
+
+	return buf.String()
+}
+
+// WriteText Writes one line per stale challenge and per unmarked record, followed by a summary
+// (e.g. `deleted _acme-challenge.www.example.com record-42 (owner host1, age 72h0m0s)`).
+func (r *SweepReport) WriteText(w io.Writer) error {
+	action := "deleted"
+	if r.DryRun {
+		action = "would delete"
+	}
+
+	var lines []string
+
+	for _, stale := range r.Stale {
+		recordID := stale.MarkerRecord.ID
+		if stale.Record != nil {
+			recordID = stale.Record.ID + " " + recordID
+		}
+
+		prefix := action
+		if !r.DryRun && !stale.Deleted {
+			prefix = "failed to delete"
+		}
+
+		lines = append(lines, fmt.Sprintf("%s %s %s (owner %s, age %s)",
+			prefix, fqdn(stale.Zone, stale.MarkerRecord), recordID, stale.Marker.Owner, stale.Age.Round(time.Second)))
+	}
+
+	for _, unmarked := range r.Unmarked {
+		lines = append(lines, fmt.Sprintf("kept %s %s (no ownership marker)", fqdn(unmarked.Zone, unmarked.Record), unmarked.Record.ID))
+	}
+
+	summary := fmt.Sprintf("%d stale challenges %s, %d unmarked records kept.", len(r.Stale), action, len(r.Unmarked))
+	if r.DryRun {
+		summary = fmt.Sprintf("%d stale challenges to delete (dry run), %d unmarked records kept.", len(r.Stale), len(r.Unmarked))
+	}
+
+	for _, line := range append(lines, summary) {
+		_, err := fmt.Fprintln(w, line)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WriteJSON Writes the report in JSON.
+func (r *SweepReport) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(r)
+}
+
+func isChallengeName(name string) bool {
+	name = auroradns.NormalizeName(name, "")
+
+	return name == challengeLabel || strings.HasPrefix(name, challengeLabel+".")
+}
+
+func fqdn(zone auroradns.Zone, record auroradns.Record) string {
+	return auroradns.NormalizeName(record.Name, "") + "." + auroradns.NormalizeZoneName(zone.Name)
+}
@@ -0,0 +1,152 @@
+package acme
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nrdcg/auroradns"
+	"github.com/nrdcg/auroradns/internal/fakeapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var sweepTime = time.Date(2024, time.January, 10, 12, 0, 0, 0, time.UTC)
+
+func setupSweepTest(t *testing.T) (*auroradns.Client, *fakeapi.Server) {
+	t.Helper()
+
+	api := fakeapi.NewServer("", "")
+	t.Cleanup(api.Close)
+
+	client, err := auroradns.NewClient(nil, auroradns.WithBaseURL(api.URL))
+	require.NoError(t, err)
+
+	marker := func(owner string, age time.Duration, recordID string) string {
+		return Marker{Owner: owner, Created: sweepTime.Add(-age), RecordID: recordID}.String()
+	}
+
+	zoneID := api.AddZone("example.com")
+
+	// stale.
+	api.AddRecord(zoneID, fakeapi.Record{Type: "TXT", Name: "_acme-challenge", Content: "abc"})
+	api.AddRecord(zoneID, fakeapi.Record{Type: "TXT", Name: "_acme-challenge", Content: marker("host1", 72*time.Hour, "record-4")})
+
+	// unmarked.
+	api.AddRecord(zoneID, fakeapi.Record{Type: "TXT", Name: "_acme-challenge.www", Content: "def"})
+
+	// stale marker without challenge record.
+	api.AddRecord(zoneID, fakeapi.Record{Type: "TXT", Name: "_acme-challenge.api", Content: marker("host2", 48*time.Hour, "record-42")})
+
+	// recent.
+	api.AddRecord(zoneID, fakeapi.Record{Type: "TXT", Name: "_acme-challenge.new", Content: "ghi"})
+	api.AddRecord(zoneID, fakeapi.Record{Type: "TXT", Name: "_acme-challenge.new", Content: marker("host1", time.Hour, "record-8")})
+
+	// not a challenge.
+	api.AddRecord(zoneID, fakeapi.Record{Type: "TXT", Name: "_acme-challenges", Content: "jkl"})
+	api.AddRecord(zoneID, fakeapi.Record{Type: "A", Name: "_acme-challenge.www", Content: "192.0.2.1"})
+
+	return client, api
+}
+
+func TestMarker(t *testing.T) {
+	marker := Marker{Owner: "host1", Created: time.Date(2024, time.January, 2, 3, 4, 5, 0, time.UTC), RecordID: "record-42"}
+
+	assert.Equal(t, "auroradns-acme owner=host1 created=2024-01-02T03:04:05Z record=record-42", marker.String())
+
+	parsed, ok := ParseMarker(`"auroradns-acme owner=host1 created=2024-01-02T03:04:05Z record=record-42"`)
+	require.True(t, ok)
+	assert.Equal(t, marker, parsed)
+
+	for _, content := range []string{
+		"abc",
+		"auroradns-acme owner=host1 created=yesterday record=record-42",
+		"auroradns-acme owner=host1 created=2024-01-02T03:04:05Z",
+		"auroradns-acme owner= created=2024-01-02T03:04:05Z record=record-42",
+	} {
+		_, ok = ParseMarker(content)
+		assert.False(t, ok, content)
+	}
+}
+
+func TestSweep_dryRun(t *testing.T) {
+	client, api := setupSweepTest(t)
+
+	report, err := Sweep(t.Context(), client, SweepOptions{
+		MaxAge: 24 * time.Hour,
+		DryRun: true,
+		Now:    func() time.Time { return sweepTime },
+	})
+	require.NoError(t, err)
+
+	assert.Empty(t, api.Mutations())
+
+	expected := `would delete _acme-challenge.example.com record-4 record-5 (owner host1, age 72h0m0s)
+would delete _acme-challenge.api.example.com record-7 (owner host2, age 48h0m0s)
+kept _acme-challenge.www.example.com record-6 (no ownership marker)
+2 stale challenges to delete (dry run), 1 unmarked records kept.
+`
+	assert.Equal(t, expected, report.String())
+}
+
+func TestSweep(t *testing.T) {
+	client, api := setupSweepTest(t)
+
+	report, err := Sweep(t.Context(), client, SweepOptions{
+		MaxAge: 24 * time.Hour,
+		Owner:  func(owner string) bool { return owner == "host1" },
+		Now:    func() time.Time { return sweepTime },
+	})
+	require.NoError(t, err)
+
+	require.Len(t, report.Stale, 1)
+	assert.True(t, report.Stale[0].Deleted)
+
+	expected := []string{
+		"DELETE /zones/zone-1/records/record-4",
+		"DELETE /zones/zone-1/records/record-5",
+	}
+	assert.Equal(t, expected, api.Mutations())
+
+	assert.Equal(t, "deleted _acme-challenge.example.com record-4 record-5 (owner host1, age 72h0m0s)\n"+
+		"kept _acme-challenge.www.example.com record-6 (no ownership marker)\n"+
+		"1 stale challenges deleted, 1 unmarked records kept.\n", report.String())
+}
+
+func TestSweep_provider(t *testing.T) {
+	provider, api, _ := setupTest(t, func(config *Config) {
+		config.Owner = "host1"
+	})
+
+	zoneID := api.AddZone("example.com")
+
+	err := provider.PresentWithContext(t.Context(), "example.com", "token", "keyAuth")
+	require.NoError(t, err)
+
+	records := txtRecords(api, zoneID)
+	require.Len(t, records, 2)
+
+	marker, ok := ParseMarker(records[1].Content)
+	require.True(t, ok)
+	assert.Equal(t, records[0].ID, marker.RecordID)
+
+	report, err := Sweep(t.Context(), provider.client, SweepOptions{MaxAge: time.Hour})
+	require.NoError(t, err)
+	assert.Empty(t, report.Stale)
+	assert.Empty(t, report.Unmarked)
+
+	report, err = Sweep(t.Context(), provider.client, SweepOptions{
+		MaxAge: time.Hour,
+		Now:    func() time.Time { return time.Now().Add(2 * time.Hour) },
+	})
+	require.NoError(t, err)
+	require.Len(t, report.Stale, 1)
+
+	assert.Empty(t, txtRecords(api, zoneID))
+}
+
+func TestSweep_invalid(t *testing.T) {
+	client, _ := setupSweepTest(t)
+
+	_, err := Sweep(t.Context(), client, SweepOptions{})
+	require.EqualError(t, err, "the maximum age must be positive")
+}
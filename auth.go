@@ -1,20 +1,35 @@
 package auroradns
 
 import (
+	"bytes"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"time"
 )
 
+const (
+	dateHeader          = "X-AuroraDNS-Date"
+	authorizationHeader = "Authorization"
+)
+
+const dateFormat = "20060102T150405Z"
+
 // TokenTransport HTTP transport for API authentication.
 type TokenTransport struct {
 	apiKey string
 	secret string
 
+	// SignQueryAndBody includes the raw query string and a SHA-256 hash of the request body in the signed message.
+	// The Aurora DNS API only verifies method, path and date:
+	// enable this only when talking to an endpoint that accepts the extended message.
+	SignQueryAndBody bool
+
 	// Transport is the underlying HTTP transport to use when making requests.
 	// It will default to http.DefaultTransport if nil.
 	Transport http.RoundTripper
@@ -30,6 +45,7 @@ func NewTokenTransport(apiKey, secret string) (*TokenTransport, error) {
 }
 
 // RoundTrip executes a single HTTP transaction.
+// The request is never sent if it cannot be signed.
 func (t *TokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	enrichedReq := &http.Request{}
 	*enrichedReq = *req
@@ -39,21 +55,67 @@ func (t *TokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 		enrichedReq.Header[k] = append([]string(nil), s...)
 	}
 
-	if t.apiKey != "" && t.secret != "" {
-		timestamp := time.Now().UTC()
-
-		fmtTime := timestamp.Format("20060102T150405Z")
-		enrichedReq.Header.Set("X-AuroraDNS-Date", fmtTime)
-
-		token, err := newToken(t.apiKey, t.secret, req.Method, req.URL.Path, timestamp)
-		if err == nil {
-			enrichedReq.Header.Set("Authorization", fmt.Sprintf("AuroraDNSv1 %s", token))
+	err := t.Sign(enrichedReq, time.Now())
+	if err != nil {
+		if req.Body != nil {
+			_ = req.Body.Close()
 		}
+
+		return nil, fmt.Errorf("failed to sign request: %w", err)
 	}
 
 	return t.transport().RoundTrip(enrichedReq)
 }
 
+// Sign Adds the X-AuroraDNS-Date and Authorization headers to the request.
+func (t *TokenTransport) Sign(req *http.Request, timestamp time.Time) error {
+	if t.apiKey == "" || t.secret == "" {
+		return errors.New("credentials missing")
+	}
+
+	message, err := t.CanonicalString(req, timestamp)
+	if err != nil {
+		return err
+	}
+
+	token, err := newToken(t.apiKey, t.secret, message)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set(dateHeader, timestamp.UTC().Format(dateFormat))
+	req.Header.Set(authorizationHeader, "AuroraDNSv1 "+token)
+
+	return nil
+}
+
+// CanonicalString Returns the message signed for the request.
+// Comparing it with the message computed by the server is the quickest way to debug a signature mismatch.
+//
+// The message is the method, the path and the date (20060102T150405Z).
+// With SignQueryAndBody, the raw query string follows the path (prefixed with "?")
+// and the hex-encoded SHA-256 hash of the body is appended after the date.
+// The body is read through req.GetBody: without GetBody, req.Body is read, and replaced with a copy that can be read again.
+func (t *TokenTransport) CanonicalString(req *http.Request, timestamp time.Time) (string, error) {
+	fmtTime := timestamp.UTC().Format(dateFormat)
+
+	if !t.SignQueryAndBody {
+		return req.Method + req.URL.Path + fmtTime, nil
+	}
+
+	resource := req.URL.Path
+	if req.URL.RawQuery != "" {
+		resource += "?" + req.URL.RawQuery
+	}
+
+	bodyHash, err := hashBody(req)
+	if err != nil {
+		return "", err
+	}
+
+	return req.Method + resource + fmtTime + bodyHash, nil
+}
+
 // Wrap Wraps an HTTP client Transport with the TokenTransport.
 func (t *TokenTransport) Wrap(client *http.Client) *http.Client {
 	backup := client.Transport
@@ -79,11 +141,8 @@ func (t *TokenTransport) transport() http.RoundTripper {
 	return http.DefaultTransport
 }
 
-// newToken generates a token for accessing a specific method of the API.
-func newToken(apiKey, secret, method, action string, timestamp time.Time) (string, error) {
-	fmtTime := timestamp.Format("20060102T150405Z")
-	message := method + action + fmtTime
-
+// newToken generates a token from the canonical message of a request.
+func newToken(apiKey, secret, message string) (string, error) {
 	signatureHmac := hmac.New(sha256.New, []byte(secret))
 
 	_, err := signatureHmac.Write([]byte(message))
@@ -99,3 +158,53 @@ func newToken(apiKey, secret, method, action string, timestamp time.Time) (strin
 
 	return token, nil
 }
+
+// hashBody returns the hex-encoded SHA-256 hash of the request body (see readRequestBody).
+func hashBody(req *http.Request) (string, error) {
+	raw, err := readRequestBody(req)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.Sum256(raw)
+
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// readRequestBody reads the body of the request without consuming it.
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	if req.GetBody == nil {
+		raw, err := io.ReadAll(req.Body)
+
+		_ = req.Body.Close()
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to read body: %w", err)
+		}
+
+		req.Body = io.NopCloser(bytes.NewReader(raw))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(raw)), nil
+		}
+
+		return raw, nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get body: %w", err)
+	}
+
+	defer func() { _ = body.Close() }()
+
+	raw, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read body: %w", err)
+	}
+
+	return raw, nil
+}
@@ -1,9 +1,12 @@
 package auroradns
 
 import (
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -31,14 +34,175 @@ func TestTokenTransport_RoundTrip(t *testing.T) {
 	apiKey := "☺"
 	secret := "🔑"
 
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
 	transport, err := NewTokenTransport(apiKey, secret)
 	require.NoError(t, err)
 
-	req := httptest.NewRequest(http.MethodGet, "http://example.com", http.NoBody)
+	req := httptest.NewRequest(http.MethodGet, server.URL, http.NoBody)
+	req.RequestURI = ""
 
 	resp, err := transport.RoundTrip(req)
 	require.NoError(t, err)
 
+	defer func() { _ = resp.Body.Close() }()
+
 	assert.Regexp(t, `\d{8}T\d{6}Z`, resp.Request.Header.Get("X-Auroradns-Date"))
 	assert.Regexp(t, `AuroraDNSv1 \w{64}`, resp.Request.Header.Get("Authorization"))
+
+	assert.Empty(t, req.Header.Get("Authorization"))
+}
+
+func TestTokenTransport_RoundTrip_sign_error(t *testing.T) {
+	var called bool
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		called = true
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	transport := &TokenTransport{}
+
+	req := httptest.NewRequest(http.MethodGet, server.URL, http.NoBody)
+	req.RequestURI = ""
+
+	resp, err := transport.RoundTrip(req) //nolint:bodyclose // the response is nil.
+	require.EqualError(t, err, "failed to sign request: credentials missing")
+
+	assert.Nil(t, resp)
+	assert.False(t, called)
+}
+
+func TestTokenTransport_Sign(t *testing.T) {
+	transport, err := NewTokenTransport("key", "secret")
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "https://api.auroradns.eu/zones", http.NoBody)
+
+	err = transport.Sign(req, time.Date(2024, time.January, 2, 3, 4, 5, 0, time.UTC))
+	require.NoError(t, err)
+
+	assert.Equal(t, "20240102T030405Z", req.Header.Get("X-AuroraDNS-Date"))
+	assert.Equal(t, "AuroraDNSv1 a2V5OlZFRGpBdFRzVW1yUDlBdUFFUnZqVXZ6bk1hTi9ReGhjVjVycG9ZWEQxUUk9", req.Header.Get("Authorization"))
+}
+
+func TestTokenTransport_CanonicalString(t *testing.T) {
+	timestamp := time.Date(2024, time.January, 2, 3, 4, 5, 0, time.UTC)
+
+	testCases := []struct {
+		desc             string
+		signQueryAndBody bool
+		method           string
+		target           string
+		body             io.Reader
+		expected         string
+	}{
+		{
+			desc:     "default",
+			method:   http.MethodGet,
+			target:   "https://api.auroradns.eu/zones?foo=bar",
+			body:     http.NoBody,
+			expected: "GET/zones20240102T030405Z",
+		},
+		{
+			desc:     "default with body",
+			method:   http.MethodPost,
+			target:   "https://api.auroradns.eu/zones",
+			body:     strings.NewReader(`{"name":"example.com"}`),
+			expected: "POST/zones20240102T030405Z",
+		},
+		{
+			desc:             "query and empty body",
+			signQueryAndBody: true,
+			method:           http.MethodGet,
+			target:           "https://api.auroradns.eu/zones?foo=bar",
+			body:             http.NoBody,
+			expected:         "GET/zones?foo=bar20240102T030405Ze3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+		},
+		{
+			desc:             "body",
+			signQueryAndBody: true,
+			method:           http.MethodPost,
+			target:           "https://api.auroradns.eu/zones",
+			body:             strings.NewReader(`{"name":"example.com"}`),
+			expected:         "POST/zones20240102T030405Zace40fd9bfb3c70c5edba30af6677cfe6dcf9386207c8d9fb4ac6df20559cc5a",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			transport, err := NewTokenTransport("key", "secret")
+			require.NoError(t, err)
+
+			transport.SignQueryAndBody = test.signQueryAndBody
+
+			req := httptest.NewRequest(test.method, test.target, test.body)
+
+			message, err := transport.CanonicalString(req, timestamp)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, message)
+		})
+	}
+}
+
+func TestTokenTransport_CanonicalString_body_preserved(t *testing.T) {
+	transport, err := NewTokenTransport("key", "secret")
+	require.NoError(t, err)
+
+	transport.SignQueryAndBody = true
+
+	req := httptest.NewRequest(http.MethodPost, "https://api.auroradns.eu/zones", io.NopCloser(strings.NewReader(`{"name":"example.com"}`)))
+
+	_, err = transport.CanonicalString(req, time.Now())
+	require.NoError(t, err)
+
+	body, err := io.ReadAll(req.Body)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{"name":"example.com"}`, string(body))
+}
+
+type closeRecorder struct {
+	io.Reader
+
+	closed bool
+}
+
+func (c *closeRecorder) Close() error {
+	c.closed = true
+	return nil
+}
+
+func Test_readRequestBody(t *testing.T) {
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodPost, "https://api.auroradns.eu/zones", http.NoBody)
+	require.NoError(t, err)
+
+	original := &closeRecorder{Reader: strings.NewReader(`{"name":"example.org"}`)}
+	req.Body = original
+
+	raw, err := readRequestBody(req)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{"name":"example.org"}`, string(raw))
+	assert.True(t, original.closed)
+
+	for _, read := range []func() (io.ReadCloser, error){
+		func() (io.ReadCloser, error) { return req.Body, nil },
+		req.GetBody,
+		req.GetBody,
+	} {
+		body, err := read()
+		require.NoError(t, err)
+
+		raw, err := io.ReadAll(body)
+		require.NoError(t, err)
+
+		assert.JSONEq(t, `{"name":"example.org"}`, string(raw))
+	}
 }
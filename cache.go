@@ -0,0 +1,261 @@
+package auroradns
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const zonesCacheKey = "zones"
+
+type forceRefreshKey struct{}
+
+// CacheConfig The time-to-live of the cached listings.
+// A zero TTL disables the cache for the resource.
+type CacheConfig struct {
+	// ZonesTTL the TTL of the ListZones results.
+	ZonesTTL time.Duration
+
+	// RecordsTTL the TTL of the ListRecords results (per zone).
+	RecordsTTL time.Duration
+}
+
+// WithCache Caches the results of ListZones and ListRecords.
+//
+// Concurrent identical listings are de-duplicated: only one request is sent, and all callers receive its result.
+// The mutations made through the client invalidate the cached listings they affect:
+// a zone creation or deletion invalidates the zones, a change inside a zone invalidates the records of the zone.
+// A refresh can be forced with ForceRefresh.
+//
+// The cache is a middleware (see WithMiddleware).
+func WithCache(config CacheConfig) Option {
+	return WithMiddleware(newResponseCache(config).middleware)
+}
+
+// ForceRefresh Returns a context that makes the cache ignore its cached listings.
+// The fresh result replaces the cached one.
+func ForceRefresh(ctx context.Context) context.Context {
+	return context.WithValue(ctx, forceRefreshKey{}, true)
+}
+
+func isForceRefresh(ctx context.Context) bool {
+	force, _ := ctx.Value(forceRefreshKey{}).(bool)
+	return force
+}
+
+// responseCache caches the raw responses of the listings.
+type responseCache struct {
+	config CacheConfig
+
+	now func() time.Time
+
+	mu          sync.Mutex
+	entries     map[string]*cacheEntry
+	flights     map[string]*flight
+	generations map[string]uint64
+}
+
+type cacheEntry struct {
+	response *cachedResponse
+	expires  time.Time
+}
+
+// flight a request in progress, shared by the identical listings.
+type flight struct {
+	done    chan struct{}
+	waiters int
+
+	response *cachedResponse
+	err      error
+
+	// canceled the context of the request was canceled: the result is not shared.
+	canceled bool
+}
+
+type cachedResponse struct {
+	status     string
+	statusCode int
+	header     http.Header
+	body       []byte
+}
+
+func newResponseCache(config CacheConfig) *responseCache {
+	return &responseCache{
+		config:      config,
+		now:         time.Now,
+		entries:     make(map[string]*cacheEntry),
+		flights:     make(map[string]*flight),
+		generations: make(map[string]uint64),
+	}
+}
+
+func (rc *responseCache) middleware(next Handler) Handler {
+	return func(call *Call) (*http.Response, error) {
+		key, ttl := rc.key(call)
+
+		if key == "" {
+			resp, err := next(call)
+
+			rc.invalidate(call)
+
+			return resp, err
+		}
+
+		return rc.get(call, key, ttl, next)
+	}
+}
+
+// key returns the cache key and the TTL of a listing.
+func (rc *responseCache) key(call *Call) (string, time.Duration) {
+	switch call.Operation {
+	case OperationListZones:
+		if rc.config.ZonesTTL > 0 {
+			return zonesCacheKey, rc.config.ZonesTTL
+		}
+
+	case OperationListRecords:
+		if rc.config.RecordsTTL > 0 {
+			return recordsCacheKey(call.ZoneID), rc.config.RecordsTTL
+		}
+	}
+
+	return "", 0
+}
+
+func (rc *responseCache) get(call *Call, key string, ttl time.Duration, next Handler) (*http.Response, error) {
+	ctx := call.Request.Context()
+
+	for {
+		rc.mu.Lock()
+
+		entry, ok := rc.entries[key]
+		if ok && rc.now().Before(entry.expires) && !isForceRefresh(ctx) {
+			rc.mu.Unlock()
+
+			return entry.response.toResponse(call.Request), nil
+		}
+
+		f, ok := rc.flights[key]
+		if !ok {
+			break
+		}
+
+		f.waiters++
+		rc.mu.Unlock()
+
+		select {
+		case <-f.done:
+		case <-ctx.Done():
+			rc.mu.Lock()
+			f.waiters--
+			rc.mu.Unlock()
+
+			return nil, ctx.Err()
+		}
+
+		// the cancellation of the request in progress is not shared: the listing is sent again.
+		if f.canceled {
+			continue
+		}
+
+		if f.response == nil {
+			return nil, f.err
+		}
+
+		return f.response.toResponse(call.Request), f.err
+	}
+
+	f := &flight{done: make(chan struct{})}
+	rc.flights[key] = f
+	generation := rc.generations[key]
+
+	rc.mu.Unlock()
+
+	resp, err := next(call)
+
+	if resp != nil {
+		f.response = newCachedResponse(resp)
+	}
+
+	f.err = err
+	f.canceled = err != nil && ctx.Err() != nil
+
+	rc.mu.Lock()
+
+	delete(rc.flights, key)
+
+	if err == nil && f.response != nil && rc.generations[key] == generation {
+		rc.entries[key] = &cacheEntry{response: f.response, expires: rc.now().Add(ttl)}
+	}
+
+	rc.mu.Unlock()
+
+	close(f.done)
+
+	return resp, err
+}
+
+// invalidate removes the listings affected by a mutation.
+func (rc *responseCache) invalidate(call *Call) {
+	if call.Request.Method == http.MethodGet {
+		return
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if call.Operation == OperationCreateZone || call.Operation == OperationDeleteZone {
+		rc.remove(zonesCacheKey)
+	}
+
+	if call.ZoneID != "" {
+		rc.remove(recordsCacheKey(call.ZoneID))
+	}
+}
+
+// remove removes an entry, and prevents the listings in progress from storing their results.
+func (rc *responseCache) remove(key string) {
+	delete(rc.entries, key)
+
+	rc.generations[key]++
+}
+
+func recordsCacheKey(zoneID string) string {
+	return "records/" + zoneID
+}
+
+// newCachedResponse reads the body of the response and restores it.
+func newCachedResponse(resp *http.Response) *cachedResponse {
+	var body []byte
+
+	if resp.Body != nil {
+		body, _ = io.ReadAll(resp.Body)
+
+		_ = resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	return &cachedResponse{
+		status:     resp.Status,
+		statusCode: resp.StatusCode,
+		header:     resp.Header.Clone(),
+		body:       body,
+	}
+}
+
+func (r *cachedResponse) toResponse(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        r.status,
+		StatusCode:    r.statusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        r.header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(r.body)),
+		ContentLength: int64(len(r.body)),
+		Request:       req,
+	}
+}
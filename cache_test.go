@@ -0,0 +1,303 @@
+package auroradns
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupCacheTest(t *testing.T) (*Client, *http.ServeMux, *responseCache) {
+	t.Helper()
+
+	cache := newResponseCache(CacheConfig{ZonesTTL: time.Minute, RecordsTTL: time.Minute})
+
+	client, mux := setupTest(t, WithMiddleware(cache.middleware))
+
+	return client, mux, cache
+}
+
+func TestWithCache_zones(t *testing.T) {
+	client, mux, cache := setupCacheTest(t)
+
+	now := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return now }
+
+	var calls atomic.Int32
+
+	handleAPI(mux, "/zones", http.MethodGet, func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+
+		_, _ = w.Write([]byte(`[{"id":"identifier-zone-1","name":"example.com"}]`))
+	})
+
+	for range 3 {
+		zones, resp, err := client.ListZonesWithContext(t.Context())
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, []Zone{{ID: "identifier-zone-1", Name: "example.com"}}, zones)
+	}
+
+	assert.EqualValues(t, 1, calls.Load())
+
+	_, _, err := client.ListZonesWithContext(ForceRefresh(t.Context()))
+	require.NoError(t, err)
+
+	assert.EqualValues(t, 2, calls.Load())
+
+	now = now.Add(time.Minute)
+
+	_, _, err = client.ListZonesWithContext(t.Context())
+	require.NoError(t, err)
+
+	assert.EqualValues(t, 3, calls.Load())
+}
+
+func TestWithCache_invalidation(t *testing.T) {
+	client, mux, _ := setupCacheTest(t)
+
+	var zoneCalls, recordCalls atomic.Int32
+
+	handleAPI(mux, "/zones", http.MethodGet, func(w http.ResponseWriter, _ *http.Request) {
+		zoneCalls.Add(1)
+
+		_, _ = w.Write([]byte(`[]`))
+	})
+
+	handleAPI(mux, "/zones/identifier-zone-1/records", http.MethodGet, func(w http.ResponseWriter, _ *http.Request) {
+		recordCalls.Add(1)
+
+		_, _ = w.Write([]byte(`[]`))
+	})
+
+	handleAPI(mux, "/zones/identifier-zone-2/records", http.MethodGet, func(w http.ResponseWriter, _ *http.Request) {
+		recordCalls.Add(1)
+
+		_, _ = w.Write([]byte(`[]`))
+	})
+
+	handleAPI(mux, "/zones/identifier-zone-1/records/identifier-record-1", http.MethodDelete, nil)
+	handleAPI(mux, "/zones/identifier-zone-1", http.MethodDelete, nil)
+
+	list := func() {
+		t.Helper()
+
+		_, _, err := client.ListZonesWithContext(t.Context())
+		require.NoError(t, err)
+
+		_, _, err = client.ListRecordsWithContext(t.Context(), "identifier-zone-1")
+		require.NoError(t, err)
+
+		_, _, err = client.ListRecordsWithContext(t.Context(), "identifier-zone-2")
+		require.NoError(t, err)
+	}
+
+	list()
+	list()
+
+	assert.EqualValues(t, 1, zoneCalls.Load())
+	assert.EqualValues(t, 2, recordCalls.Load())
+
+	_, _, err := client.DeleteRecordWithContext(t.Context(), "identifier-zone-1", "identifier-record-1")
+	require.NoError(t, err)
+
+	list()
+
+	assert.EqualValues(t, 1, zoneCalls.Load())
+	assert.EqualValues(t, 3, recordCalls.Load())
+
+	_, _, err = client.DeleteZoneWithContext(t.Context(), "identifier-zone-1")
+	require.NoError(t, err)
+
+	list()
+
+	assert.EqualValues(t, 2, zoneCalls.Load())
+	assert.EqualValues(t, 4, recordCalls.Load())
+}
+
+func TestWithCache_error_not_cached(t *testing.T) {
+	client, mux, _ := setupCacheTest(t)
+
+	var calls atomic.Int32
+
+	handleAPI(mux, "/zones", http.MethodGet, func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"error":"AuthenticationRequiredError","errormsg":"Failed to parse Authorization header"}`))
+	})
+
+	for range 2 {
+		_, _, err := client.ListZonesWithContext(t.Context())
+		require.EqualError(t, err, "AuthenticationRequiredError - Failed to parse Authorization header")
+	}
+
+	assert.EqualValues(t, 2, calls.Load())
+}
+
+// waitForWaiters waits until n listings wait for the listing in progress.
+func waitForWaiters(t *testing.T, cache *responseCache, key string, n int) {
+	t.Helper()
+
+	require.Eventually(t, func() bool {
+		cache.mu.Lock()
+		defer cache.mu.Unlock()
+
+		f, ok := cache.flights[key]
+
+		return ok && f.waiters == n
+	}, time.Second, time.Millisecond)
+}
+
+func TestWithCache_single_flight(t *testing.T) {
+	client, mux, cache := setupCacheTest(t)
+
+	var calls atomic.Int32
+
+	release := make(chan struct{})
+
+	handleAPI(mux, "/zones/identifier-zone-1/records", http.MethodGet, func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+
+		<-release
+
+		_, _ = w.Write([]byte(`[{"id":"aaa","type":"TXT","name":"foo","ttl":300}]`))
+	})
+
+	const concurrency = 5
+
+	var wg sync.WaitGroup
+
+	results := make([][]Record, concurrency)
+
+	for i := range concurrency {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			records, _, err := client.ListRecordsWithContext(t.Context(), "identifier-zone-1")
+			assert.NoError(t, err)
+
+			results[i] = records
+		}()
+	}
+
+	waitForWaiters(t, cache, recordsCacheKey("identifier-zone-1"), concurrency-1)
+
+	close(release)
+
+	wg.Wait()
+
+	assert.EqualValues(t, 1, calls.Load())
+
+	for _, records := range results {
+		assert.Equal(t, []Record{{ID: "aaa", RecordType: RecordTypeTXT, Name: "foo", TTL: 300}}, records)
+	}
+}
+
+func TestWithCache_single_flight_waiter_canceled(t *testing.T) {
+	client, mux, cache := setupCacheTest(t)
+
+	release := make(chan struct{})
+
+	handleAPI(mux, "/zones/identifier-zone-1/records", http.MethodGet, func(w http.ResponseWriter, _ *http.Request) {
+		<-release
+
+		_, _ = w.Write([]byte(`[]`))
+	})
+
+	done := make(chan error)
+
+	go func() {
+		_, _, err := client.ListRecordsWithContext(t.Context(), "identifier-zone-1")
+		done <- err
+	}()
+
+	require.Eventually(t, func() bool {
+		cache.mu.Lock()
+		defer cache.mu.Unlock()
+
+		return cache.flights[recordsCacheKey("identifier-zone-1")] != nil
+	}, time.Second, time.Millisecond)
+
+	ctx, cancel := context.WithCancel(t.Context())
+
+	go func() {
+		waitForWaiters(t, cache, recordsCacheKey("identifier-zone-1"), 1)
+		cancel()
+	}()
+
+	// the waiter returns when its context is canceled, without waiting for the listing in progress.
+	_, _, err := client.ListRecordsWithContext(ctx, "identifier-zone-1")
+	require.ErrorIs(t, err, context.Canceled)
+
+	close(release)
+
+	require.NoError(t, <-done)
+}
+
+func TestWithCache_single_flight_leader_canceled(t *testing.T) {
+	client, mux, cache := setupCacheTest(t)
+
+	var calls atomic.Int32
+
+	handleAPI(mux, "/zones/identifier-zone-1/records", http.MethodGet, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			<-r.Context().Done()
+			return
+		}
+
+		_, _ = w.Write([]byte(`[{"id":"aaa","type":"TXT","name":"foo","ttl":300}]`))
+	})
+
+	ctx, cancel := context.WithCancel(t.Context())
+
+	done := make(chan error)
+
+	go func() {
+		_, _, err := client.ListRecordsWithContext(ctx, "identifier-zone-1")
+		done <- err
+	}()
+
+	require.Eventually(t, func() bool { return calls.Load() == 1 }, time.Second, time.Millisecond)
+
+	go func() {
+		waitForWaiters(t, cache, recordsCacheKey("identifier-zone-1"), 1)
+		cancel()
+	}()
+
+	// the cancellation of the first listing is not returned to the waiter: the listing is sent again.
+	records, _, err := client.ListRecordsWithContext(t.Context(), "identifier-zone-1")
+	require.NoError(t, err)
+
+	assert.Equal(t, []Record{{ID: "aaa", RecordType: RecordTypeTXT, Name: "foo", TTL: 300}}, records)
+	assert.EqualValues(t, 2, calls.Load())
+
+	require.ErrorIs(t, <-done, context.Canceled)
+}
+
+func TestWithCache_disabled(t *testing.T) {
+	client, mux := setupTest(t, WithCache(CacheConfig{ZonesTTL: time.Minute}))
+
+	var calls atomic.Int32
+
+	handleAPI(mux, "/zones/identifier-zone-1/records", http.MethodGet, func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+
+		_, _ = w.Write([]byte(`[]`))
+	})
+
+	for range 2 {
+		_, _, err := client.ListRecordsWithContext(t.Context(), "identifier-zone-1")
+		require.NoError(t, err)
+	}
+
+	assert.EqualValues(t, 2, calls.Load())
+}
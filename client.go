@@ -1,14 +1,17 @@
 package auroradns
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"time"
 )
 
-const defaultBaseURL = "https://api.auroradns.eu"
+// DefaultBaseURL the URL of the Aurora DNS API.
+const DefaultBaseURL = "https://api.auroradns.eu"
 
 const (
 	contentTypeHeader = "Content-Type"
@@ -24,6 +27,9 @@ type ErrorResponse = ResponseError
 type ResponseError struct {
 	ErrorCode string `json:"error"`
 	Message   string `json:"errormsg"`
+
+	// StatusCode the HTTP status code of the response.
+	StatusCode int `json:"-"`
 }
 
 func (e *ResponseError) Error() string {
@@ -38,6 +44,14 @@ type Client struct {
 	baseURL    *url.URL
 	UserAgent  string
 	httpClient *http.Client
+
+	maxRetries int
+	retryWait  time.Duration
+	limiter    *rateLimiter
+	metrics    Metrics
+
+	middlewares []Middleware
+	handler     Handler
 }
 
 // NewClient Creates a new client.
@@ -46,7 +60,7 @@ func NewClient(httpClient *http.Client, opts ...Option) (*Client, error) {
 		httpClient = http.DefaultClient
 	}
 
-	baseURL, _ := url.Parse(defaultBaseURL)
+	baseURL, _ := url.Parse(DefaultBaseURL)
 
 	client := &Client{
 		baseURL:    baseURL,
@@ -60,29 +74,22 @@ func NewClient(httpClient *http.Client, opts ...Option) (*Client, error) {
 		}
 	}
 
+	client.handler = chain(client.roundTrip, client.middlewares)
+
 	return client, nil
 }
 
-func (c *Client) do(req *http.Request, v any) (*http.Response, error) {
-	req.Header.Set(contentTypeHeader, contentTypeJSON)
-
-	if c.UserAgent != "" {
-		req.Header.Set("User-Agent", c.UserAgent)
-	}
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, err
+func (c *Client) do(call *Call, v any) (*http.Response, error) {
+	resp, err := c.stream(call)
+	if resp != nil && resp.Body != nil {
+		defer func() { _ = resp.Body.Close() }()
 	}
 
-	defer func() { _ = resp.Body.Close() }()
-
-	err = checkResponse(resp)
 	if err != nil {
 		return resp, err
 	}
 
-	if v == nil {
+	if v == nil || resp.Body == nil {
 		return resp, nil
 	}
 
@@ -99,6 +106,41 @@ func (c *Client) do(req *http.Request, v any) (*http.Response, error) {
 	return resp, nil
 }
 
+// stream executes the call without reading the response: the caller must close the body of the response.
+func (c *Client) stream(call *Call) (*http.Response, error) {
+	req := call.Request
+
+	req.Header.Set(contentTypeHeader, contentTypeJSON)
+
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+
+	resp, err := c.handler(call)
+	if err == nil && resp == nil {
+		return nil, fmt.Errorf("%s: the middlewares returned neither a response nor an error", call.Operation)
+	}
+
+	return resp, err
+}
+
+// roundTrip is the last handler of the middleware chain: it sends the request and checks the response.
+func (c *Client) roundTrip(call *Call) (*http.Response, error) {
+	resp, err := c.send(call)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkResponse(resp)
+	if err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+// checkResponse returns an error if the status code is not 2xx.
+// The body of the response is restored after reading it.
 func checkResponse(resp *http.Response) error {
 	if c := resp.StatusCode; 200 <= c && c <= 299 {
 		return nil
@@ -106,6 +148,9 @@ func checkResponse(resp *http.Response) error {
 
 	data, err := io.ReadAll(resp.Body)
 	if err == nil && data != nil {
+		_ = resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(data))
+
 		errorResponse := new(ResponseError)
 
 		err = json.Unmarshal(data, errorResponse)
@@ -113,6 +158,8 @@ func checkResponse(resp *http.Response) error {
 			return fmt.Errorf("unmarshaling ErrorResponse error: %w: %s", err, string(data))
 		}
 
+		errorResponse.StatusCode = resp.StatusCode
+
 		return errorResponse
 	}
 
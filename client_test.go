@@ -6,16 +6,17 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/nrdcg/auroradns/internal/fakeapi"
 	"github.com/stretchr/testify/require"
 )
 
-func setupTest(t *testing.T) (*Client, *http.ServeMux) {
+func setupTest(t *testing.T, opts ...Option) (*Client, *http.ServeMux) {
 	t.Helper()
 
 	apiHandler := http.NewServeMux()
 	server := httptest.NewServer(apiHandler)
 
-	client, err := NewClient(nil, WithBaseURL(server.URL))
+	client, err := NewClient(nil, append([]Option{WithBaseURL(server.URL)}, opts...)...)
 	require.NoError(t, err)
 
 	t.Cleanup(server.Close)
@@ -23,6 +24,19 @@ func setupTest(t *testing.T) (*Client, *http.ServeMux) {
 	return client, apiHandler
 }
 
+// setupFakeAPI creates a client of an in-memory API.
+func setupFakeAPI(t *testing.T, opts ...Option) (*Client, *fakeapi.Server) {
+	t.Helper()
+
+	server := fakeapi.NewServer("", "")
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(nil, append([]Option{WithBaseURL(server.URL)}, opts...)...)
+	require.NoError(t, err)
+
+	return client, server
+}
+
 func handleAPI(mux *http.ServeMux, pattern, method string, next func(w http.ResponseWriter, r *http.Request)) {
 	mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != method {
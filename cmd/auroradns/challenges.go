@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"slices"
+
+	"github.com/nrdcg/auroradns/acme"
+)
+
+func challengesSweep(ctx context.Context, a *app, args []string) error {
+	fs := a.flagSet("challenges sweep")
+	maxAge := fs.Duration("max-age", 0, "delete the challenges older than this duration (e.g. 24h)")
+	dryRun := fs.Bool("dry-run", false, "report the stale challenges without deleting them")
+
+	var owners stringList
+
+	fs.Var(&owners, "owner", "only sweep the challenges of this owner (repeatable)")
+
+	_, err := parseFlags(fs, args, 0)
+	if err != nil {
+		return err
+	}
+
+	if *maxAge <= 0 {
+		return usageErrorf("-max-age is required and must be positive")
+	}
+
+	if a.output != formatTable && a.output != formatJSON {
+		return usageErrorf("unknown output format %q", a.output)
+	}
+
+	client, err := a.newClient()
+	if err != nil {
+		return err
+	}
+
+	options := acme.SweepOptions{MaxAge: *maxAge, DryRun: *dryRun}
+
+	if len(owners) > 0 {
+		options.Owner = func(owner string) bool { return slices.Contains(owners, owner) }
+	}
+
+	report, err := acme.Sweep(ctx, client, options)
+	if report == nil {
+		return err
+	}
+
+	if a.output == formatJSON {
+		_ = report.WriteJSON(a.stdout)
+	} else {
+		_ = report.WriteText(a.stdout)
+	}
+
+	return err
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nrdcg/auroradns/acme"
+	"github.com/nrdcg/auroradns/internal/fakeapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChallengesSweep(t *testing.T) {
+	server := setupCLI(t)
+
+	zoneID := server.AddZone("example.com")
+
+	created := time.Now().Add(-48 * time.Hour).UTC().Truncate(time.Second)
+
+	server.AddRecord(zoneID, fakeapi.Record{Type: "TXT", Name: "_acme-challenge", Content: "abc"})
+	server.AddRecord(zoneID, fakeapi.Record{Type: "TXT", Name: "_acme-challenge", Content: acme.Marker{Owner: "host1", Created: created, RecordID: "record-4"}.String()})
+
+	stdout, stderr, code := runCLI(t, "challenges", "sweep", "-max-age", "24h", "-dry-run")
+	require.Equal(t, exitOK, code, stderr)
+
+	assert.Contains(t, stdout, "would delete _acme-challenge.example.com record-4 record-5 (owner host1, age 48h")
+	assert.Empty(t, server.Mutations())
+
+	_, stderr, code = runCLI(t, "challenges", "sweep", "-max-age", "24h", "-owner", "host2")
+	require.Equal(t, exitOK, code, stderr)
+
+	assert.Empty(t, server.Mutations())
+
+	stdout, stderr, code = runCLI(t, "-o", "json", "challenges", "sweep", "-max-age", "24h", "-owner", "host1")
+	require.Equal(t, exitOK, code, stderr)
+
+	assert.Contains(t, stdout, `"deleted": true`)
+	assert.Len(t, server.Mutations(), 2)
+
+	_, _, code = runCLI(t, "challenges", "sweep")
+	assert.Equal(t, exitUsage, code)
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"text/tabwriter"
+
+	"github.com/nrdcg/auroradns/ddns"
+)
+
+func ddnsCommand(ctx context.Context, a *app, args []string) error {
+	fs := a.flagSet("ddns")
+	name := fs.String("name", "@", "name of the records, relative to the zone")
+	ttl := fs.Int("ttl", ddns.DefaultTTL, "TTL of the records")
+	ipv4 := fs.Bool("4", true, "keep the A record up to date")
+	ipv6 := fs.Bool("6", false, "keep the AAAA record up to date")
+	stateFile := fs.String("state", "", "file where the published addresses are saved, to avoid redundant API calls")
+	interval := fs.Duration("interval", ddns.DefaultInterval, "time between two updates")
+	debounce := fs.Duration("debounce", 0, "time during which a new address must stay the same before being published")
+	once := fs.Bool("once", false, "update once and exit (e.g. from cron)")
+
+	var interfaces, urls stringList
+
+	fs.Var(&interfaces, "interface", "detect the addresses on this network interface (repeatable)")
+	fs.Var(&urls, "url", "detect the address with this HTTP echo endpoint (repeatable, default: "+ddns.DefaultIPv4URL+" and "+ddns.DefaultIPv6URL+")")
+
+	positional, err := parseFlags(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	if a.output != formatTable && a.output != formatJSON {
+		return usageErrorf("unknown output format %q", a.output)
+	}
+
+	if !*ipv4 && !*ipv6 {
+		return usageErrorf("-4 and -6 are both disabled")
+	}
+
+	var sources []ddns.Source
+
+	for _, iface := range interfaces {
+		sources = append(sources, &ddns.InterfaceSource{Name: iface})
+	}
+
+	for _, url := range urls {
+		sources = append(sources, &ddns.HTTPSource{URL: url})
+	}
+
+	if len(sources) == 0 {
+		sources = ddns.DefaultSources()
+	}
+
+	client, err := a.newClient()
+	if err != nil {
+		return err
+	}
+
+	updater, err := ddns.NewUpdater(client, ddns.Config{
+		Zone:      positional[0],
+		Name:      *name,
+		TTL:       *ttl,
+		IPv4:      *ipv4,
+		IPv6:      *ipv6,
+		Sources:   sources,
+		StateFile: *stateFile,
+		Debounce:  *debounce,
+		Interval:  *interval,
+		Logger:    slog.New(slog.NewTextHandler(a.stderr, nil)),
+	})
+	if err != nil {
+		return err
+	}
+
+	if !*once {
+		err = updater.Run(ctx)
+		if errors.Is(err, context.Canceled) {
+			return nil
+		}
+
+		return err
+	}
+
+	results, err := updater.Update(ctx)
+
+	_ = a.writeResults(results)
+
+	return err
+}
+
+func (a *app) writeResults(results []ddns.Result) error {
+	if a.output == formatJSON {
+		return writeJSON(a.stdout, results)
+	}
+
+	tw := tabwriter.NewWriter(a.stdout, 0, 0, 2, ' ', 0)
+
+	_, _ = fmt.Fprintln(tw, "TYPE\tNAME\tADDRESS\tACTION")
+
+	for _, result := range results {
+		address := ""
+		if result.Address.IsValid() {
+			address = result.Address.String()
+		}
+
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", result.RecordType, result.Name, address, result.Action)
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/nrdcg/auroradns/internal/fakeapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDDNS_once(t *testing.T) {
+	server := setupCLI(t)
+
+	zoneID := server.AddZone("example.com")
+
+	address := "192.0.2.1"
+
+	echo := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(address))
+	}))
+	t.Cleanup(echo.Close)
+
+	state := filepath.Join(t.TempDir(), "state.json")
+
+	stdout, stderr, code := runCLI(t, "ddns", "-once", "-name", "site1", "-url", echo.URL, "-state", state, "example.com")
+	require.Equal(t, exitOK, code, stderr)
+
+	assert.Equal(t, "TYPE  NAME               ADDRESS    ACTION\nA     site1.example.com  192.0.2.1  created\n", stdout)
+
+	stdout, stderr, code = runCLI(t, "-o", "json", "ddns", "-once", "-name", "site1", "-url", echo.URL, "-state", state, "example.com")
+	require.Equal(t, exitOK, code, stderr)
+
+	assert.JSONEq(t, `[{"type":"A","name":"site1.example.com","address":"192.0.2.1","action":"unchanged"}]`, stdout)
+
+	address = "192.0.2.2"
+
+	_, stderr, code = runCLI(t, "ddns", "-once", "-name", "site1", "-url", echo.URL, "-state", state, "example.com")
+	require.Equal(t, exitOK, code, stderr)
+
+	assert.Equal(t, fakeapi.Record{ID: "record-4", Type: "A", Name: "site1", Content: "192.0.2.2", TTL: 300}, server.Records(zoneID)[2])
+
+	// the echo endpoint does not return an IPv6 address.
+	stdout, stderr, code = runCLI(t, "ddns", "-once", "-4=false", "-6", "-url", echo.URL, "example.com")
+	assert.Equal(t, exitFailure, code)
+
+	assert.Equal(t, "TYPE  NAME         ADDRESS  ACTION\nAAAA  example.com           failed\n", stdout)
+	assert.Contains(t, stderr, "no address found for AAAA")
+
+	_, _, code = runCLI(t, "ddns", "-once", "-4=false", "example.com")
+	assert.Equal(t, exitUsage, code)
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/nrdcg/auroradns/externaldns"
+)
+
+func externalDNSCommand(ctx context.Context, a *app, args []string) error {
+	fs := a.flagSet("external-dns")
+	listen := fs.String("listen", "127.0.0.1:8888", "address to listen on (the --webhook-provider-url of external-dns)")
+	ownerID := fs.String("owner-id", "", "owner of the records (the --txt-owner-id of external-dns): the changes of the records of other owners are refused")
+	ttl := fs.Int("ttl", externaldns.DefaultTTL, "TTL of the records created from the endpoints without TTL")
+
+	var include, exclude stringList
+
+	fs.Var(&include, "domain-filter", "manage only this domain and its subdomains (repeatable)")
+	fs.Var(&exclude, "exclude-domain", "do not manage this domain and its subdomains (repeatable)")
+
+	_, err := parseFlags(fs, args, 0)
+	if err != nil {
+		return err
+	}
+
+	if *ttl <= 0 {
+		return usageErrorf("invalid TTL %d", *ttl)
+	}
+
+	client, err := a.newClient()
+	if err != nil {
+		return err
+	}
+
+	logger := slog.New(slog.NewTextHandler(a.stderr, nil))
+
+	provider, err := externaldns.NewProvider(client, externaldns.Config{
+		DomainFilter: externaldns.DomainFilter{Include: include, Exclude: exclude},
+		OwnerID:      *ownerID,
+		DefaultTTL:   *ttl,
+		Logger:       logger,
+	})
+	if err != nil {
+		return err
+	}
+
+	logger.Info("externaldns: listening", slog.String("addr", *listen))
+
+	return provider.ListenAndServe(ctx, *listen)
+}
@@ -0,0 +1,24 @@
+module github.com/nrdcg/auroradns/cmd/auroradns
+
+go 1.24.0
+
+require (
+	github.com/miekg/dns v1.1.68
+	github.com/nrdcg/auroradns v1.3.0
+	github.com/nrdcg/auroradns/acme v0.1.0
+	github.com/nrdcg/auroradns/rfc2136 v0.1.0
+	github.com/stretchr/testify v1.11.1
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/nrdcg/auroradns/propagation v0.1.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/mod v0.24.0 // indirect
+	golang.org/x/net v0.40.0 // indirect
+	golang.org/x/sync v0.14.0 // indirect
+	golang.org/x/sys v0.33.0 // indirect
+	golang.org/x/text v0.25.0 // indirect
+	golang.org/x/tools v0.33.0 // indirect
+)
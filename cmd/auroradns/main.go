@@ -0,0 +1,377 @@
+// Package main A command-line tool for the Aurora DNS API.
+//
+//	auroradns [global flags] <command> [<subcommand>] [flags] [arguments]
+//
+// The credentials are read from the flags, or from the environment and the configuration file (see auroradns.ConfigFromEnv).
+//
+// The client is guarded (see auroradns.WithGuard): the deletion of a zone containing records (without -force)
+// and the deletion of the apex NS and SOA records are refused. The global flag -no-guard disables the guard.
+//
+// Exit codes:
+//
+//	0   success
+//	1   other errors
+//	2   changes present (plan)
+//	3   authentication error (401, 403) or missing credentials
+//	4   not found (404)
+//	5   conflict (409) or refused by the guard
+//	6   invalid request (400, 422)
+//	7   server error (5xx) or rate limited (429)
+//	64  usage error
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+
+	"github.com/nrdcg/auroradns"
+)
+
+// Exit codes.
+const (
+	exitOK       = 0
+	exitFailure  = 1
+	exitChanges  = 2
+	exitAuth     = 3
+	exitNotFound = 4
+	exitConflict = 5
+	exitInvalid  = 6
+	exitServer   = 7
+	exitUsage    = 64
+)
+
+const defaultUserAgent = "auroradns-cli"
+
+// command A command (e.g. "plan") or a subcommand (e.g. "zones list").
+type command struct {
+	name    string
+	args    string
+	summary string
+	run     func(ctx context.Context, a *app, args []string) error
+}
+
+var commands = []command{
+	{name: "zones list", summary: "List the zones.", run: zonesList},
+	{name: "zones create", args: "NAME", summary: "Create a zone.", run: zonesCreate},
+	{name: "zones delete", args: "[-force] ZONE", summary: "Delete a zone (by name or ID).", run: zonesDelete},
+	{name: "records list", args: "[-type TYPE] [-name NAME] ZONE", summary: "List the records of a zone.", run: recordsList},
+	{name: "records create", args: "-type TYPE [-name NAME] -content CONTENT [-ttl TTL] ZONE", summary: "Create a record.", run: recordsCreate},
+	{name: "records update", args: "[-type TYPE] [-name NAME] [-content CONTENT] [-ttl TTL] ZONE RECORD_ID", summary: "Update a record: the unset fields keep their values.", run: recordsUpdate},
+	{name: "records delete", args: "ZONE RECORD_ID", summary: "Delete a record.", run: recordsDelete},
+	{name: "plan", args: "[-target ZONE]... [-out FILE] FILE...", summary: "Show the changes needed to reach the desired state (exit code 2 if there are changes).", run: planCommand},
+	{name: "apply", args: "[-target ZONE]... [-destroy] (-plan FILE | FILE...)", summary: "Apply the changes needed to reach the desired state.", run: applyCommand},
+	{name: "ddns", args: "[-name NAME] [-4] [-6] [-interface IFACE]... [-url URL]... [-state FILE] [-debounce DURATION] [-interval DURATION] [-once] ZONE", summary: "Keep the A and AAAA records of this host up to date with its addresses.", run: ddnsCommand},
+	{name: "challenges sweep", args: "-max-age DURATION [-owner OWNER]... [-dry-run]", summary: "Delete the ACME challenges left behind (see acme.Sweep).", run: challengesSweep},
+	{name: "external-dns", args: "[-listen ADDR] [-domain-filter DOMAIN]... [-exclude-domain DOMAIN]... [-owner-id ID] [-ttl TTL]", summary: "Serve the external-dns webhook provider protocol.", run: externalDNSCommand},
+	{name: "rfc2136", args: "[-listen ADDR] -keys FILE", summary: "Serve the DNS UPDATE (RFC 2136) messages signed with the TSIG keys, and apply them with the API.", run: rfc2136Command},
+	{name: "sign", args: "[-curl] [-data DATA] [-time TIME] METHOD PATH", summary: "Print the authentication headers of a request, or a curl command.", run: signCommand},
+	{name: "raw", args: "[-data DATA] [-include] METHOD PATH", summary: "Send a signed request and print the response.", run: rawCommand},
+}
+
+// app The global flags and the outputs.
+type app struct {
+	stdout io.Writer
+	stderr io.Writer
+
+	apiKey     string
+	secret     string
+	endpoint   string
+	profile    string
+	configFile string
+	noGuard    bool
+	output     string
+}
+
+// exitError An error with a specific exit code.
+// An exitError without error only sets the exit code: nothing is printed.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	if e.err == nil {
+		return fmt.Sprintf("exit status %d", e.code)
+	}
+
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+func usageErrorf(format string, a ...any) error {
+	return &exitError{code: exitUsage, err: fmt.Errorf(format, a...)}
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+
+	code := run(ctx, os.Args[1:], os.Stdout, os.Stderr)
+
+	stop()
+
+	os.Exit(code)
+}
+
+// run executes the command line and returns the exit code.
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	a := &app{stdout: stdout, stderr: stderr}
+
+	fs := flag.NewFlagSet("auroradns", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() { a.usage() }
+
+	a.globalFlags(fs)
+
+	err := fs.Parse(args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+
+		return exitUsage
+	}
+
+	args = fs.Args()
+
+	if len(args) == 0 {
+		a.usage()
+		return exitUsage
+	}
+
+	for _, cmd := range commands {
+		words := strings.Fields(cmd.name)
+		if len(args) < len(words) || !slices.Equal(args[:len(words)], words) {
+			continue
+		}
+
+		err = cmd.run(ctx, a, args[len(words):])
+
+		var exitErr *exitError
+		if errors.As(err, &exitErr) && exitErr.err == nil {
+			return exitErr.code
+		}
+
+		if err != nil {
+			_, _ = fmt.Fprintf(stderr, "auroradns %s: %v\n", cmd.name, err)
+
+			return exitCode(err)
+		}
+
+		return exitOK
+	}
+
+	_, _ = fmt.Fprintf(stderr, "auroradns: unknown command %q\n", strings.Join(args[:min(len(args), 2)], " "))
+	a.usage()
+
+	return exitUsage
+}
+
+func (a *app) usage() {
+	var sb strings.Builder
+
+	sb.WriteString("Usage: auroradns [global flags] <command> [<subcommand>] [flags] [arguments]\n\nCommands:\n")
+
+	for _, cmd := range commands {
+		_, _ = fmt.Fprintf(&sb, "  %s %s\n    \t%s\n", cmd.name, cmd.args, cmd.summary)
+	}
+
+	sb.WriteString("\nThe deletion of a zone containing records (without -force) and the deletion of the apex NS and SOA records\n" +
+		"are refused, unless the guard is disabled with -no-guard.\n")
+
+	sb.WriteString("\nGlobal flags:\n")
+
+	_, _ = io.WriteString(a.stderr, sb.String())
+
+	// a throwaway flag set: the defaults are printed without changing the parsed values.
+	fs := flag.NewFlagSet("auroradns", flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	(&app{}).globalFlags(fs)
+	fs.PrintDefaults()
+}
+
+func (a *app) globalFlags(fs *flag.FlagSet) {
+	fs.StringVar(&a.apiKey, "api-key", "", "API key (default: $"+auroradns.EnvAPIKey+")")
+	fs.StringVar(&a.secret, "secret", "", "secret (default: $"+auroradns.EnvSecret+")")
+	fs.StringVar(&a.endpoint, "endpoint", "", "API endpoint (default: $"+auroradns.EnvEndpoint+")")
+	fs.StringVar(&a.profile, "profile", "", "profile of the configuration file (default: $"+auroradns.EnvProfile+")")
+	fs.StringVar(&a.configFile, "config", "", "configuration file (default: $"+auroradns.EnvConfigFile+")")
+	fs.BoolVar(&a.noGuard, "no-guard", false, "disable the guard refusing the destructive calls")
+	a.outputFlags(fs)
+}
+
+// outputFlags registers the output flags, accepted before and after the subcommand.
+func (a *app) outputFlags(fs *flag.FlagSet) {
+	fs.StringVar(&a.output, "output", a.outputOrDefault(), "output format: table, json or zone")
+	fs.StringVar(&a.output, "o", a.outputOrDefault(), "shorthand for -output")
+}
+
+func (a *app) outputOrDefault() string {
+	if a.output == "" {
+		return formatTable
+	}
+
+	return a.output
+}
+
+// flagSet creates the flag set of a subcommand.
+func (a *app) flagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet("auroradns "+name, flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	a.outputFlags(fs)
+
+	return fs
+}
+
+// parseFlags parses the flags of a subcommand, placed before or after the arguments, and returns the arguments.
+func parseFlags(fs *flag.FlagSet, args []string, want int) ([]string, error) {
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(positional) != want {
+		return nil, usageErrorf("expected %d arguments, got %d", want, len(positional))
+	}
+
+	return positional, nil
+}
+
+// parseArgs parses the flags of a subcommand, placed before or after the arguments, and returns the arguments.
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+
+	for {
+		err := fs.Parse(args)
+		if errors.Is(err, flag.ErrHelp) {
+			// the usage has been printed.
+			return nil, &exitError{code: exitOK}
+		}
+
+		if err != nil {
+			return nil, &exitError{code: exitUsage, err: err}
+		}
+
+		args = fs.Args()
+		if len(args) == 0 {
+			break
+		}
+
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+
+	return positional, nil
+}
+
+// newClient creates a client from the flags, the environment and the configuration file.
+// The client is guarded, unless -no-guard is set.
+func (a *app) newClient() (*auroradns.Client, error) {
+	config, err := a.config()
+	if err != nil {
+		return nil, err
+	}
+
+	if a.noGuard {
+		return config.NewClient()
+	}
+
+	return config.NewClient(auroradns.WithGuard(auroradns.GuardPolicy{}))
+}
+
+// config reads the configuration from the flags, the environment and the configuration file.
+func (a *app) config() (*auroradns.Config, error) {
+	var (
+		config *auroradns.Config
+		err    error
+	)
+
+	if a.profile != "" || a.configFile != "" {
+		// the file of the flag, then the file of the environment, then the default file.
+		filename := a.configFile
+		if filename == "" {
+			filename = os.Getenv(auroradns.EnvConfigFile)
+		}
+
+		if filename == "" {
+			filename, err = auroradns.DefaultConfigFile()
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		config, err = auroradns.LoadConfig(filename, a.profile)
+	} else {
+		config, err = auroradns.ConfigFromEnv()
+	}
+
+	if err != nil {
+		return nil, &exitError{code: exitAuth, err: err}
+	}
+
+	if a.apiKey != "" {
+		config.APIKey = a.apiKey
+	}
+
+	if a.secret != "" {
+		config.Secret = a.secret
+	}
+
+	if a.endpoint != "" {
+		config.Endpoint = a.endpoint
+	}
+
+	if config.UserAgent == "" {
+		config.UserAgent = defaultUserAgent
+	}
+
+	err = config.Validate()
+	if err != nil {
+		return nil, &exitError{code: exitAuth, err: err}
+	}
+
+	return config, nil
+}
+
+// exitCode returns the exit code matching an error.
+func exitCode(err error) int {
+	var exitErr *exitError
+	if errors.As(err, &exitErr) {
+		return exitErr.code
+	}
+
+	if errors.Is(err, auroradns.ErrProtected) {
+		return exitConflict
+	}
+
+	var apiErr *auroradns.ResponseError
+	if !errors.As(err, &apiErr) {
+		return exitFailure
+	}
+
+	switch code := apiErr.StatusCode; {
+	case code == http.StatusUnauthorized || code == http.StatusForbidden:
+		return exitAuth
+	case code == http.StatusNotFound:
+		return exitNotFound
+	case code == http.StatusConflict:
+		return exitConflict
+	case code == http.StatusBadRequest || code == http.StatusUnprocessableEntity:
+		return exitInvalid
+	case code == http.StatusTooManyRequests || code >= http.StatusInternalServerError:
+		return exitServer
+	default:
+		return exitFailure
+	}
+}
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nrdcg/auroradns"
+	"github.com/nrdcg/auroradns/internal/fakeapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupCLI starts a fake API and points the environment at it.
+func setupCLI(t *testing.T) *fakeapi.Server {
+	t.Helper()
+
+	// no configuration file.
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	for _, env := range []string{auroradns.EnvProfile, auroradns.EnvConfigFile, auroradns.EnvMaxRetries, auroradns.EnvRateLimit} {
+		t.Setenv(env, "")
+	}
+
+	server := fakeapi.NewServer("key", "secret")
+	t.Cleanup(server.Close)
+
+	t.Setenv(auroradns.EnvAPIKey, "key")
+	t.Setenv(auroradns.EnvSecret, "secret")
+	t.Setenv(auroradns.EnvEndpoint, server.URL)
+
+	return server
+}
+
+func runCLI(t *testing.T, args ...string) (string, string, int) {
+	t.Helper()
+
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+
+	code := run(t.Context(), args, stdout, stderr)
+
+	return stdout.String(), stderr.String(), code
+}
+
+func TestZones(t *testing.T) {
+	server := setupCLI(t)
+
+	server.AddZone("example.org")
+
+	stdout, stderr, code := runCLI(t, "zones", "create", "example.com")
+	require.Equal(t, exitOK, code, stderr)
+
+	assert.Equal(t, "ID      NAME\nzone-4  example.com\n", stdout)
+
+	stdout, stderr, code = runCLI(t, "-o", "json", "zones", "list")
+	require.Equal(t, exitOK, code, stderr)
+
+	assert.JSONEq(t, `[{"id":"zone-1","name":"example.org"},{"id":"zone-4","name":"example.com"}]`, stdout)
+
+	_, stderr, code = runCLI(t, "zones", "create", "example.com")
+	assert.Equal(t, exitConflict, code)
+	assert.Equal(t, "auroradns zones create: DuplicateZone - the zone example.com already exists\n", stderr)
+
+	_, stderr, code = runCLI(t, "zones", "delete", "example.com.")
+	require.Equal(t, exitOK, code, stderr)
+
+	assert.Len(t, server.Zones(), 1)
+
+	_, _, code = runCLI(t, "zones", "delete", "example.net")
+	assert.Equal(t, exitNotFound, code)
+}
+
+func TestZones_deleteNotEmpty(t *testing.T) {
+	server := setupCLI(t)
+
+	zoneID := server.AddZone("example.com")
+	server.AddRecord(zoneID, fakeapi.Record{Type: "A", Name: "www", Content: "192.0.2.1"})
+
+	_, stderr, code := runCLI(t, "zones", "delete", zoneID)
+	assert.Equal(t, exitConflict, code)
+	assert.Equal(t, "auroradns zones delete: protected resource: the zone zone-1 contains 1 records (use -force to delete it)\n", stderr)
+
+	_, stderr, code = runCLI(t, "zones", "delete", zoneID, "-force")
+	require.Equal(t, exitOK, code, stderr)
+
+	assert.Empty(t, server.Zones())
+}
+
+func TestZones_deleteNoGuard(t *testing.T) {
+	server := setupCLI(t)
+
+	zoneID := server.AddZone("example.com")
+	server.AddRecord(zoneID, fakeapi.Record{Type: "A", Name: "www", Content: "192.0.2.1"})
+
+	_, stderr, code := runCLI(t, "-no-guard", "zones", "delete", zoneID)
+	require.Equal(t, exitOK, code, stderr)
+
+	assert.Empty(t, server.Zones())
+}
+
+func TestRecords(t *testing.T) {
+	server := setupCLI(t)
+
+	zoneID := server.AddZone("example.com")
+
+	stdout, stderr, code := runCLI(t, "records", "create", "example.com", "-type", "a", "-name", "www", "-content", "192.0.2.1", "-ttl", "300")
+	require.Equal(t, exitOK, code, stderr)
+
+	assert.Equal(t, "ID        TYPE  NAME  CONTENT    TTL  HEALTH CHECK\nrecord-4  A     www   192.0.2.1  300  \n", stdout)
+
+	_, stderr, code = runCLI(t, "records", "create", "-type", "TXT", "-content", `v=spf1 "quoted" -all`, "example.com")
+	require.Equal(t, exitOK, code, stderr)
+
+	stdout, stderr, code = runCLI(t, "records", "list", "example.com", "-o", "zone")
+	require.Equal(t, exitOK, code, stderr)
+
+	expected := `$ORIGIN example.com.
+@   4800 IN SOA ns1.auroradns.eu. hostmaster.example.com. 1 86400 7200 604800 300
+@   4800 IN NS  ns1.auroradns.eu.
+www 300  IN A   192.0.2.1
+@        IN TXT "v=spf1 \"quoted\" -all"
+`
+	assert.Equal(t, expected, stdout)
+
+	stdout, stderr, code = runCLI(t, "records", "list", "-type", "A", "-output", "json", "example.com")
+	require.Equal(t, exitOK, code, stderr)
+
+	assert.JSONEq(t, `[{"id":"record-4","type":"A","name":"www","content":"192.0.2.1","ttl":300}]`, stdout)
+
+	_, stderr, code = runCLI(t, "records", "update", "example.com", "record-4", "-content", "192.0.2.2")
+	require.Equal(t, exitOK, code, stderr)
+
+	assert.Equal(t, fakeapi.Record{ID: "record-4", Type: "A", Name: "www", Content: "192.0.2.2", TTL: 300}, server.Records(zoneID)[2])
+
+	_, stderr, code = runCLI(t, "records", "delete", "example.com", "record-4")
+	require.Equal(t, exitOK, code, stderr)
+
+	assert.Len(t, server.Records(zoneID), 3)
+
+	_, stderr, code = runCLI(t, "records", "delete", "example.com", "record-4")
+	assert.Equal(t, exitNotFound, code)
+	assert.Equal(t, "auroradns records delete: record record-4 not found in the zone example.com\n", stderr)
+}
+
+func TestRun_auth(t *testing.T) {
+	setupCLI(t)
+
+	_, stderr, code := runCLI(t, "-secret", "wrong", "zones", "list")
+	assert.Equal(t, exitAuth, code)
+	assert.Equal(t, "auroradns zones list: Unauthorized - invalid signature\n", stderr)
+
+	t.Setenv(auroradns.EnvAPIKey, "")
+
+	_, stderr, code = runCLI(t, "zones", "list")
+	assert.Equal(t, exitAuth, code)
+	assert.Contains(t, stderr, "missing API key")
+}
+
+func TestRun_profile(t *testing.T) {
+	server := setupCLI(t)
+	server.AddZone("example.com")
+
+	filename := filepath.Join(t.TempDir(), "config")
+
+	err := os.WriteFile(filename, []byte("[profile staging]\napi_key = key\nsecret = secret\nendpoint = "+server.URL+"\n"), 0o600)
+	require.NoError(t, err)
+
+	for _, env := range []string{auroradns.EnvAPIKey, auroradns.EnvSecret, auroradns.EnvEndpoint} {
+		t.Setenv(env, "")
+	}
+
+	t.Setenv(auroradns.EnvConfigFile, filename)
+
+	stdout, stderr, code := runCLI(t, "-profile", "staging", "-o", "json", "zones", "list")
+	require.Equal(t, exitOK, code, stderr)
+
+	assert.JSONEq(t, `[{"id":"zone-1","name":"example.com"}]`, stdout)
+
+	// the flag takes precedence over the environment.
+	_, stderr, code = runCLI(t, "-profile", "staging", "-config", filepath.Join(t.TempDir(), "missing"), "zones", "list")
+	assert.Equal(t, exitAuth, code)
+	assert.Contains(t, stderr, "missing")
+}
+
+func TestRun_usage(t *testing.T) {
+	setupCLI(t)
+
+	testCases := []struct {
+		desc string
+		args []string
+	}{
+		{desc: "no command", args: nil},
+		{desc: "unknown command", args: []string{"zones", "rename"}},
+		{desc: "missing argument", args: []string{"zones", "create"}},
+		{desc: "unknown flag", args: []string{"zones", "list", "-foo"}},
+		{desc: "missing record flags", args: []string{"records", "create", "example.com", "-type", "A"}},
+		{desc: "zone output for zones", args: []string{"zones", "list", "-o", "zone"}},
+		{desc: "unknown output", args: []string{"zones", "list", "-o", "yaml"}},
+		{desc: "invalid external-dns TTL", args: []string{"external-dns", "-ttl", "0"}},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			_, _, code := runCLI(t, test.args...)
+			assert.Equal(t, exitUsage, code)
+		})
+	}
+}
+
+func TestRun_help(t *testing.T) {
+	setupCLI(t)
+
+	_, stderr, code := runCLI(t, "-h")
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stderr, "Usage: auroradns")
+
+	_, stderr, code = runCLI(t, "records", "create", "-h")
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stderr, "Usage of auroradns records create")
+	assert.NotContains(t, stderr, "help requested")
+}
+
+func TestExitCode(t *testing.T) {
+	testCases := []struct {
+		statusCode int
+		expected   int
+	}{
+		{statusCode: http.StatusUnauthorized, expected: exitAuth},
+		{statusCode: http.StatusForbidden, expected: exitAuth},
+		{statusCode: http.StatusNotFound, expected: exitNotFound},
+		{statusCode: http.StatusConflict, expected: exitConflict},
+		{statusCode: http.StatusBadRequest, expected: exitInvalid},
+		{statusCode: http.StatusUnprocessableEntity, expected: exitInvalid},
+		{statusCode: http.StatusTooManyRequests, expected: exitServer},
+		{statusCode: http.StatusBadGateway, expected: exitServer},
+		{statusCode: http.StatusTeapot, expected: exitFailure},
+	}
+
+	for _, test := range testCases {
+		t.Run(http.StatusText(test.statusCode), func(t *testing.T) {
+			err := fmt.Errorf("wrapped: %w", &auroradns.ResponseError{StatusCode: test.statusCode})
+
+			assert.Equal(t, test.expected, exitCode(err))
+		})
+	}
+
+	assert.Equal(t, exitFailure, exitCode(errors.New("boom")))
+}
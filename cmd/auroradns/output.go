@@ -0,0 +1,175 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"unicode/utf8"
+
+	"github.com/nrdcg/auroradns"
+)
+
+// Output formats.
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatZone  = "zone"
+)
+
+func (a *app) writeZones(zones []auroradns.Zone) error {
+	switch a.output {
+	case formatTable:
+		tw := tabwriter.NewWriter(a.stdout, 0, 0, 2, ' ', 0)
+
+		_, _ = fmt.Fprintln(tw, "ID\tNAME")
+
+		for _, zone := range zones {
+			_, _ = fmt.Fprintf(tw, "%s\t%s\n", zone.ID, zone.Name)
+		}
+
+		return tw.Flush()
+
+	case formatJSON:
+		return writeJSON(a.stdout, zones)
+
+	case formatZone:
+		return usageErrorf("the %s output format is only available for records", formatZone)
+
+	default:
+		return usageErrorf("unknown output format %q", a.output)
+	}
+}
+
+func (a *app) writeRecords(zone auroradns.Zone, records []auroradns.Record) error {
+	switch a.output {
+	case formatTable:
+		tw := tabwriter.NewWriter(a.stdout, 0, 0, 2, ' ', 0)
+
+		_, _ = fmt.Fprintln(tw, "ID\tTYPE\tNAME\tCONTENT\tTTL\tHEALTH CHECK")
+
+		for _, record := range records {
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%s\n",
+				record.ID, record.RecordType, displayName(record.Name), record.Content, record.TTL, record.HealthCheckID)
+		}
+
+		return tw.Flush()
+
+	case formatJSON:
+		return writeJSON(a.stdout, records)
+
+	case formatZone:
+		return writeZoneFile(a.stdout, zone, records)
+
+	default:
+		return usageErrorf("unknown output format %q", a.output)
+	}
+}
+
+func writeJSON(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(v)
+}
+
+// writeZoneFile writes the records in the zone file format (RFC 1035).
+func writeZoneFile(w io.Writer, zone auroradns.Zone, records []auroradns.Record) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 1, ' ', 0)
+
+	_, _ = fmt.Fprintf(tw, "$ORIGIN %s.\n", auroradns.NormalizeZoneName(zone.Name))
+
+	for _, record := range records {
+		ttl := ""
+		if record.TTL > 0 {
+			ttl = strconv.Itoa(record.TTL)
+		}
+
+		_, _ = fmt.Fprintf(tw, "%s\t%s\tIN\t%s\t%s\n",
+			displayName(auroradns.NormalizeName(record.Name, zone.Name)), ttl, record.RecordType, zoneFileContent(record))
+	}
+
+	return tw.Flush()
+}
+
+// zoneFileContent returns the content of a record in the zone file format:
+// the TXT content is quoted, and the host names (targets, SOA name servers and mailboxes) are fully qualified.
+func zoneFileContent(record auroradns.Record) string {
+	content := strings.TrimSpace(record.Content)
+
+	switch strings.ToUpper(record.RecordType) {
+	case auroradns.RecordTypeTXT:
+		if !strings.HasPrefix(content, `"`) {
+			return quoteTXT(content)
+		}
+
+		return content
+
+	case auroradns.RecordTypeCNAME, auroradns.RecordTypeNS, auroradns.RecordTypePTR:
+		return fqdn(content)
+
+	case auroradns.RecordTypeMX, auroradns.RecordTypeSRV:
+		fields := strings.Fields(content)
+		if len(fields) > 0 {
+			fields[len(fields)-1] = fqdn(fields[len(fields)-1])
+		}
+
+		return strings.Join(fields, " ")
+
+	case auroradns.RecordTypeSOA:
+		fields := strings.Fields(content)
+		for i := range min(len(fields), 2) {
+			fields[i] = fqdn(fields[i])
+		}
+
+		return strings.Join(fields, " ")
+
+	default:
+		return content
+	}
+}
+
+// fqdn adds the trailing dot to a host name: the API stores the absolute names without it.
+func fqdn(name string) string {
+	if name == "" || strings.HasSuffix(name, ".") {
+		return name
+	}
+
+	return name + "."
+}
+
+// quoteTXT quotes a TXT content, split in strings of 255 bytes at most, without splitting a UTF-8 character.
+func quoteTXT(content string) string {
+	var parts []string
+
+	for {
+		size := min(len(content), 255)
+		for size > 0 && size < len(content) && !utf8.RuneStart(content[size]) {
+			size--
+		}
+
+		if size == 0 {
+			// not UTF-8.
+			size = min(len(content), 255)
+		}
+
+		part := content[:size]
+		content = content[size:]
+
+		parts = append(parts, `"`+strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(part)+`"`)
+
+		if content == "" {
+			return strings.Join(parts, " ")
+		}
+	}
+}
+
+func displayName(name string) string {
+	if name == "" {
+		return "@"
+	}
+
+	return name
+}
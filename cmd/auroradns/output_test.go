@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/nrdcg/auroradns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteZoneFile_roundTrip(t *testing.T) {
+	zone := auroradns.Zone{ID: "zone-1", Name: "example.com"}
+
+	records := []auroradns.Record{
+		{RecordType: "SOA", Content: "ns1.auroradns.eu hostmaster.example.com 1 86400 7200 604800 300", TTL: 4800},
+		{RecordType: "NS", Content: "ns1.auroradns.eu.", TTL: 4800},
+		{RecordType: "A", Name: "www", Content: "192.0.2.1", TTL: 300},
+		{RecordType: "CNAME", Name: "alias", Content: "foo.example.net", TTL: 300},
+		{RecordType: "CNAME", Name: "local", Content: "www.example.com.", TTL: 300},
+		{RecordType: "MX", Content: "10 mx.example.net", TTL: 300},
+		{RecordType: "SRV", Name: "_sip._tcp", Content: "10 5 5060 sip.example.net", TTL: 300},
+		{RecordType: "NS", Name: "sub", Content: "ns.example.net", TTL: 300},
+		{RecordType: "PTR", Name: "1", Content: "host.example.net", TTL: 300},
+		{RecordType: "TXT", Content: `v=spf1 "quoted" -all`},
+	}
+
+	var buf bytes.Buffer
+
+	err := writeZoneFile(&buf, zone, records)
+	require.NoError(t, err)
+
+	parser := dns.NewZoneParser(strings.NewReader(buf.String()), "", "")
+
+	var parsed []string
+
+	for rr, ok := parser.Next(); ok; rr, ok = parser.Next() {
+		parsed = append(parsed, rr.String())
+	}
+
+	require.NoError(t, parser.Err(), buf.String())
+
+	expected := []string{
+		"example.com.\t4800\tIN\tSOA\tns1.auroradns.eu. hostmaster.example.com. 1 86400 7200 604800 300",
+		"example.com.\t4800\tIN\tNS\tns1.auroradns.eu.",
+		"www.example.com.\t300\tIN\tA\t192.0.2.1",
+		"alias.example.com.\t300\tIN\tCNAME\tfoo.example.net.",
+		"local.example.com.\t300\tIN\tCNAME\twww.example.com.",
+		"example.com.\t300\tIN\tMX\t10 mx.example.net.",
+		"_sip._tcp.example.com.\t300\tIN\tSRV\t10 5 5060 sip.example.net.",
+		"sub.example.com.\t300\tIN\tNS\tns.example.net.",
+		"1.example.com.\t300\tIN\tPTR\thost.example.net.",
+		"example.com.\t300\tIN\tTXT\t\"v=spf1 \\\"quoted\\\" -all\"",
+	}
+	assert.Equal(t, expected, parsed)
+}
+
+func Test_quoteTXT(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		content  string
+		expected string
+	}{
+		{
+			desc:     "short",
+			content:  `v=spf1 "quoted" \ -all`,
+			expected: `"v=spf1 \"quoted\" \\ -all"`,
+		},
+		{
+			desc:     "long",
+			content:  strings.Repeat("a", 300),
+			expected: `"` + strings.Repeat("a", 255) + `" "` + strings.Repeat("a", 45) + `"`,
+		},
+		{
+			desc:     "multi-byte character at the limit",
+			content:  strings.Repeat("a", 254) + "é" + "b",
+			expected: `"` + strings.Repeat("a", 254) + `" "éb"`,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			assert.Equal(t, test.expected, quoteTXT(test.content))
+		})
+	}
+}
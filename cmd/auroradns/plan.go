@@ -0,0 +1,517 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/nrdcg/auroradns"
+	"gopkg.in/yaml.v3"
+)
+
+// planFileVersion the version of the saved plan format.
+const planFileVersion = 1
+
+// ANSI colors of the diff lines.
+const (
+	colorRed    = "\x1b[31m"
+	colorGreen  = "\x1b[32m"
+	colorYellow = "\x1b[33m"
+	colorReset  = "\x1b[0m"
+)
+
+// desiredZone the content of a desired-state file.
+//
+//	zone: example.com
+//	records:
+//	  - name: www
+//	    type: A
+//	    content: 192.0.2.1
+//	    ttl: 300
+type desiredZone struct {
+	Zone    string          `yaml:"zone"`
+	Records []desiredRecord `yaml:"records"`
+
+	file string
+}
+
+type desiredRecord struct {
+	Name          string `yaml:"name"`
+	Type          string `yaml:"type"`
+	Content       string `yaml:"content"`
+	TTL           int    `yaml:"ttl"`
+	HealthCheckID string `yaml:"health_check_id"`
+}
+
+// planFile a saved plan.
+type planFile struct {
+	Version int        `json:"version"`
+	Zones   []zonePlan `json:"zones"`
+}
+
+// zonePlan the plan of a zone, with the fingerprint of the live state it has been computed from.
+type zonePlan struct {
+	Plan *auroradns.Plan `json:"plan"`
+
+	// CreateZone the zone does not exist yet.
+	CreateZone bool `json:"create_zone,omitempty"`
+
+	// Fingerprint the SHA-256 hash of the live records.
+	Fingerprint string `json:"fingerprint,omitempty"`
+}
+
+// stringList a repeatable flag.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+func planCommand(ctx context.Context, a *app, args []string) error {
+	fs := a.flagSet("plan")
+
+	var targets stringList
+
+	fs.Var(&targets, "target", "only this zone (repeatable)")
+	out := fs.String("out", "", "write the plan to this file, for apply -plan")
+	noColor := fs.Bool("no-color", false, "disable the colors")
+
+	files, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(files) == 0 {
+		return usageErrorf("no desired-state file")
+	}
+
+	client, err := a.newClient()
+	if err != nil {
+		return err
+	}
+
+	plans, err := planFiles(ctx, client, files, targets)
+	if err != nil {
+		return err
+	}
+
+	a.writePlans(plans, useColor(a.stdout, *noColor))
+
+	if *out != "" {
+		err = writePlanFile(*out, plans)
+		if err != nil {
+			return err
+		}
+	}
+
+	if hasChanges(plans) {
+		return &exitError{code: exitChanges}
+	}
+
+	return nil
+}
+
+func applyCommand(ctx context.Context, a *app, args []string) error {
+	fs := a.flagSet("apply")
+
+	var targets stringList
+
+	fs.Var(&targets, "target", "only this zone (repeatable)")
+	planPath := fs.String("plan", "", "apply this saved plan (see plan -out)")
+	destroy := fs.Bool("destroy", false, "allow the deletion of records")
+	noColor := fs.Bool("no-color", false, "disable the colors")
+
+	files, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if (*planPath == "") == (len(files) == 0) {
+		return usageErrorf("either -plan or desired-state files are required")
+	}
+
+	client, err := a.newClient()
+	if err != nil {
+		return err
+	}
+
+	var plans []zonePlan
+
+	if *planPath != "" {
+		plans, err = readPlanFile(*planPath, targets)
+		if err != nil {
+			return err
+		}
+
+		err = verifyPlans(ctx, client, plans)
+	} else {
+		plans, err = planFiles(ctx, client, files, targets)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	a.writePlans(plans, useColor(a.stdout, *noColor))
+
+	if removed := countChanges(plans).removed; removed > 0 && !*destroy {
+		return &exitError{code: exitConflict, err: fmt.Errorf("the plan deletes %d records: use -destroy to apply it", removed)}
+	}
+
+	for _, plan := range plans {
+		err = applyZonePlan(ctx, client, plan)
+		if err != nil {
+			return fmt.Errorf("zone %s: %w", plan.Plan.ZoneName, err)
+		}
+	}
+
+	changes := countChanges(plans)
+
+	_, _ = fmt.Fprintf(a.stdout, "Applied: %d added, %d changed, %d destroyed.\n", changes.added, changes.changed, changes.removed)
+
+	return nil
+}
+
+func applyZonePlan(ctx context.Context, client *auroradns.Client, plan zonePlan) error {
+	if plan.CreateZone {
+		zone, _, err := client.CreateZoneWithContext(ctx, plan.Plan.ZoneName)
+		if err != nil {
+			return err
+		}
+
+		plan.Plan.ZoneID = zone.ID
+	}
+
+	return client.ApplyPlan(ctx, plan.Plan)
+}
+
+// planFiles computes the plans of the desired-state files.
+func planFiles(ctx context.Context, client *auroradns.Client, patterns, targets []string) ([]zonePlan, error) {
+	desired, err := loadDesiredZones(patterns, targets)
+	if err != nil {
+		return nil, err
+	}
+
+	liveZones, _, err := client.ListZonesWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var plans []zonePlan
+
+	for _, zone := range desired {
+		records := make([]auroradns.Record, 0, len(zone.Records))
+		for _, r := range zone.Records {
+			records = append(records, auroradns.Record{RecordType: r.Type, Name: r.Name, Content: r.Content, TTL: r.TTL, HealthCheckID: r.HealthCheckID})
+		}
+
+		live, ok := findZone(liveZones, zone.Zone)
+		if !ok {
+			plans = append(plans, zonePlan{
+				Plan:       auroradns.NewPlan(auroradns.Zone{Name: zone.Zone}, nil, records),
+				CreateZone: true,
+			})
+
+			continue
+		}
+
+		liveRecords, _, err := client.ListRecordsWithContext(ctx, live.ID)
+		if err != nil {
+			return nil, fmt.Errorf("zone %s: %w", live.Name, err)
+		}
+
+		plans = append(plans, zonePlan{
+			Plan:        auroradns.NewPlan(live, liveRecords, records),
+			Fingerprint: fingerprint(live, liveRecords),
+		})
+	}
+
+	return plans, nil
+}
+
+// verifyPlans checks that the live state has not changed since the plans were computed.
+func verifyPlans(ctx context.Context, client *auroradns.Client, plans []zonePlan) error {
+	liveZones, _, err := client.ListZonesWithContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, plan := range plans {
+		live, ok := findZone(liveZones, plan.Plan.ZoneName)
+
+		switch {
+		case plan.CreateZone && ok, !plan.CreateZone && (!ok || live.ID != plan.Plan.ZoneID):
+			return &exitError{code: exitConflict, err: fmt.Errorf("the zone %s has changed since the plan: run plan again", plan.Plan.ZoneName)}
+
+		case plan.CreateZone:
+			continue
+		}
+
+		liveRecords, _, err := client.ListRecordsWithContext(ctx, live.ID)
+		if err != nil {
+			return fmt.Errorf("zone %s: %w", live.Name, err)
+		}
+
+		if fingerprint(live, liveRecords) != plan.Fingerprint {
+			return &exitError{code: exitConflict, err: fmt.Errorf("the records of the zone %s have changed since the plan: run plan again", plan.Plan.ZoneName)}
+		}
+	}
+
+	return nil
+}
+
+// loadDesiredZones reads the desired-state files (glob patterns are expanded), sorted by zone name.
+func loadDesiredZones(patterns, targets []string) ([]desiredZone, error) {
+	var zones []desiredZone
+
+	for _, pattern := range patterns {
+		files, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, usageErrorf("invalid pattern %q: %w", pattern, err)
+		}
+
+		if len(files) == 0 {
+			return nil, usageErrorf("no file matches %s", pattern)
+		}
+
+		for _, file := range files {
+			zone, err := readDesiredZone(file)
+			if err != nil {
+				return nil, err
+			}
+
+			if i := slices.IndexFunc(zones, func(z desiredZone) bool { return sameZone(z.Zone, zone.Zone) }); i >= 0 {
+				return nil, &exitError{code: exitInvalid, err: fmt.Errorf("the zone %s is defined in %s and %s", zone.Zone, zones[i].file, file)}
+			}
+
+			zones = append(zones, zone)
+		}
+	}
+
+	for _, target := range targets {
+		if !slices.ContainsFunc(zones, func(z desiredZone) bool { return sameZone(z.Zone, target) }) {
+			return nil, usageErrorf("the target %s is not in the desired state", target)
+		}
+	}
+
+	if len(targets) > 0 {
+		zones = slices.DeleteFunc(zones, func(z desiredZone) bool {
+			return !slices.ContainsFunc(targets, func(target string) bool { return sameZone(z.Zone, target) })
+		})
+	}
+
+	slices.SortFunc(zones, func(a, b desiredZone) int {
+		return cmp.Compare(auroradns.NormalizeZoneName(a.Zone), auroradns.NormalizeZoneName(b.Zone))
+	})
+
+	return zones, nil
+}
+
+func readDesiredZone(file string) (desiredZone, error) {
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		return desiredZone{}, err
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(raw))
+	decoder.KnownFields(true)
+
+	zone := desiredZone{file: file}
+
+	err = decoder.Decode(&zone)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return desiredZone{}, &exitError{code: exitInvalid, err: fmt.Errorf("%s: %w", file, err)}
+	}
+
+	if zone.Zone == "" {
+		return desiredZone{}, &exitError{code: exitInvalid, err: fmt.Errorf("%s: missing zone", file)}
+	}
+
+	for i, record := range zone.Records {
+		if record.Type == "" {
+			return desiredZone{}, &exitError{code: exitInvalid, err: fmt.Errorf("%s: record %d: missing type", file, i+1)}
+		}
+	}
+
+	return zone, nil
+}
+
+func readPlanFile(filename string, targets []string) ([]zonePlan, error) {
+	raw, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var saved planFile
+
+	err = json.Unmarshal(raw, &saved)
+	if err != nil {
+		return nil, &exitError{code: exitInvalid, err: fmt.Errorf("invalid plan file %s: %w", filename, err)}
+	}
+
+	if saved.Version != planFileVersion {
+		return nil, &exitError{code: exitInvalid, err: fmt.Errorf("unsupported plan file version: %d", saved.Version)}
+	}
+
+	plans := saved.Zones
+
+	if len(targets) > 0 {
+		plans = slices.DeleteFunc(plans, func(p zonePlan) bool {
+			return !slices.ContainsFunc(targets, func(target string) bool { return sameZone(p.Plan.ZoneName, target) })
+		})
+	}
+
+	return plans, nil
+}
+
+func writePlanFile(filename string, plans []zonePlan) error {
+	raw, err := json.MarshalIndent(planFile{Version: planFileVersion, Zones: plans}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filename, append(raw, '\n'), 0o600)
+}
+
+// writePlans writes the changes of the plans, and a summary.
+func (a *app) writePlans(plans []zonePlan, color bool) {
+	for _, plan := range plans {
+		header := "zone " + plan.Plan.ZoneName
+		if plan.CreateZone {
+			header += " (new)"
+		}
+
+		if plan.Plan.Diff.IsEmpty() && !plan.CreateZone {
+			_, _ = fmt.Fprintf(a.stdout, "%s: no changes\n", header)
+			continue
+		}
+
+		_, _ = fmt.Fprintf(a.stdout, "%s:\n", header)
+
+		for line := range strings.Lines(plan.Plan.Diff.String()) {
+			_, _ = fmt.Fprint(a.stdout, "  "+colorize(line, color))
+		}
+	}
+
+	changes := countChanges(plans)
+
+	_, _ = fmt.Fprintf(a.stdout, "\nPlan: %d to add, %d to change, %d to destroy.\n", changes.added, changes.changed, changes.removed)
+}
+
+type changeCount struct {
+	added, changed, removed int
+}
+
+func countChanges(plans []zonePlan) changeCount {
+	var count changeCount
+
+	for _, plan := range plans {
+		count.added += len(plan.Plan.Diff.Added)
+		count.changed += len(plan.Plan.Diff.Changed)
+		count.removed += len(plan.Plan.Diff.Removed)
+	}
+
+	return count
+}
+
+func hasChanges(plans []zonePlan) bool {
+	return slices.ContainsFunc(plans, func(p zonePlan) bool {
+		return p.CreateZone || !p.Plan.Diff.IsEmpty()
+	})
+}
+
+// colorize colors a diff line according to its prefix.
+func colorize(line string, color bool) string {
+	if !color || line == "" {
+		return line
+	}
+
+	var c string
+
+	switch line[0] {
+	case '+':
+		c = colorGreen
+	case '-':
+		c = colorRed
+	case '~':
+		c = colorYellow
+	default:
+		return line
+	}
+
+	text, newline := strings.CutSuffix(line, "\n")
+
+	line = c + text + colorReset
+	if newline {
+		line += "\n"
+	}
+
+	return line
+}
+
+// useColor returns true if the output is a terminal, and the colors are not disabled (-no-color or NO_COLOR).
+func useColor(w io.Writer, noColor bool) bool {
+	if noColor || os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// fingerprint returns the SHA-256 hash of the live records taken into account by the plans.
+func fingerprint(zone auroradns.Zone, records []auroradns.Record) string {
+	records = slices.DeleteFunc(slices.Clone(records), func(r auroradns.Record) bool {
+		normalized := auroradns.NormalizeRecord(r, zone.Name)
+
+		return normalized.Name == "" && (normalized.RecordType == auroradns.RecordTypeNS || normalized.RecordType == auroradns.RecordTypeSOA)
+	})
+
+	slices.SortFunc(records, func(a, b auroradns.Record) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	raw, _ := json.Marshal(records)
+
+	sum := sha256.Sum256(raw)
+
+	return hex.EncodeToString(sum[:])
+}
+
+func findZone(zones []auroradns.Zone, name string) (auroradns.Zone, bool) {
+	for _, zone := range zones {
+		if sameZone(zone.Name, name) {
+			return zone, true
+		}
+	}
+
+	return auroradns.Zone{}, false
+}
+
+func sameZone(a, b string) bool {
+	return auroradns.NormalizeZoneName(a) == auroradns.NormalizeZoneName(b)
+}
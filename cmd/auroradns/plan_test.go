@@ -0,0 +1,163 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nrdcg/auroradns/internal/fakeapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeDesired(t *testing.T, dir, name, content string) {
+	t.Helper()
+
+	err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600)
+	require.NoError(t, err)
+}
+
+func setupPlanTest(t *testing.T) (*fakeapi.Server, string) {
+	t.Helper()
+
+	server := setupCLI(t)
+
+	zoneID := server.AddZone("example.com")
+	server.AddRecord(zoneID, fakeapi.Record{Type: "A", Name: "www", Content: "192.0.2.1", TTL: 300})
+	server.AddRecord(zoneID, fakeapi.Record{Type: "A", Name: "api", Content: "192.0.2.1", TTL: 300})
+	server.AddRecord(zoneID, fakeapi.Record{Type: "TXT", Name: "old", Content: "foo", TTL: 300})
+
+	dir := t.TempDir()
+
+	writeDesired(t, dir, "example.com.yaml", `
+zone: example.com
+records:
+  - {name: www, type: A, content: 192.0.2.1, ttl: 300}
+  - {name: api, type: A, content: 192.0.2.2}
+  - {name: old, type: TXT, content: foo, ttl: 300}
+  - {name: "@", type: MX, content: 10 mail.example.com., ttl: 300}
+`)
+
+	writeDesired(t, dir, "example.org.yaml", `
+zone: example.org
+records:
+  - {name: www, type: CNAME, content: example.com., ttl: 300}
+`)
+
+	return server, dir
+}
+
+func TestPlan(t *testing.T) {
+	server, dir := setupPlanTest(t)
+
+	out := filepath.Join(t.TempDir(), "plan.json")
+
+	stdout, stderr, code := runCLI(t, "plan", "-out", out, filepath.Join(dir, "*.yaml"))
+	require.Equal(t, exitChanges, code, stderr)
+
+	expected := `zone example.com:
+  ~ A api content: 192.0.2.1 -> 192.0.2.2
+  + MX @ 10 mail.example.com. ttl=300
+zone example.org (new):
+  + CNAME www example.com. ttl=300
+
+Plan: 2 to add, 1 to change, 0 to destroy.
+`
+	assert.Equal(t, expected, stdout)
+	assert.FileExists(t, out)
+
+	assert.Empty(t, server.Mutations())
+
+	stdout, stderr, code = runCLI(t, "apply", "-plan", out)
+	require.Equal(t, exitOK, code, stderr)
+
+	assert.Contains(t, stdout, "Applied: 2 added, 1 changed, 0 destroyed.\n")
+
+	_, stderr, code = runCLI(t, "plan", filepath.Join(dir, "*.yaml"))
+	require.Equal(t, exitOK, code, stderr)
+
+	// the saved plan is stale.
+	_, stderr, code = runCLI(t, "apply", "-plan", out)
+	assert.Equal(t, exitConflict, code)
+	assert.Equal(t, "auroradns apply: the records of the zone example.com have changed since the plan: run plan again\n", stderr)
+}
+
+func TestPlan_target(t *testing.T) {
+	_, dir := setupPlanTest(t)
+
+	stdout, stderr, code := runCLI(t, "plan", "-target", "example.org.", filepath.Join(dir, "*.yaml"))
+	require.Equal(t, exitChanges, code, stderr)
+
+	assert.Equal(t, "zone example.org (new):\n  + CNAME www example.com. ttl=300\n\nPlan: 1 to add, 0 to change, 0 to destroy.\n", stdout)
+
+	_, _, code = runCLI(t, "plan", "-target", "example.net", filepath.Join(dir, "*.yaml"))
+	assert.Equal(t, exitUsage, code)
+}
+
+func TestApply_stalePlan(t *testing.T) {
+	server, dir := setupPlanTest(t)
+
+	out := filepath.Join(t.TempDir(), "plan.json")
+
+	_, stderr, code := runCLI(t, "plan", "-out", out, "-target", "example.com", filepath.Join(dir, "example.com.yaml"))
+	require.Equal(t, exitChanges, code, stderr)
+
+	server.AddRecord("zone-1", fakeapi.Record{Type: "A", Name: "new", Content: "192.0.2.3"})
+
+	_, stderr, code = runCLI(t, "apply", "-plan", out)
+	assert.Equal(t, exitConflict, code)
+	assert.Equal(t, "auroradns apply: the records of the zone example.com have changed since the plan: run plan again\n", stderr)
+
+	assert.Empty(t, server.Mutations())
+}
+
+func TestApply_destroy(t *testing.T) {
+	server, dir := setupPlanTest(t)
+
+	writeDesired(t, dir, "example.com.yaml", `
+zone: example.com
+records:
+  - {name: www, type: A, content: 192.0.2.1, ttl: 300}
+`)
+
+	file := filepath.Join(dir, "example.com.yaml")
+
+	_, stderr, code := runCLI(t, "apply", file)
+	assert.Equal(t, exitConflict, code)
+	assert.Equal(t, "auroradns apply: the plan deletes 2 records: use -destroy to apply it\n", stderr)
+
+	assert.Empty(t, server.Mutations())
+
+	stdout, stderr, code := runCLI(t, "apply", "-destroy", file)
+	require.Equal(t, exitOK, code, stderr)
+
+	assert.Contains(t, stdout, "Applied: 0 added, 0 changed, 2 destroyed.\n")
+	assert.Len(t, server.Records("zone-1"), 3)
+}
+
+func TestPlan_invalid(t *testing.T) {
+	setupCLI(t)
+
+	dir := t.TempDir()
+
+	writeDesired(t, dir, "a.yaml", "zone: example.com\nrecords:\n  - {name: www, content: 192.0.2.1}\n")
+	writeDesired(t, dir, "b.yaml", "zone: example.com\nrecord: []\n")
+
+	_, stderr, code := runCLI(t, "plan", filepath.Join(dir, "a.yaml"))
+	assert.Equal(t, exitInvalid, code)
+	assert.Contains(t, stderr, "record 1: missing type")
+
+	_, stderr, code = runCLI(t, "plan", filepath.Join(dir, "b.yaml"))
+	assert.Equal(t, exitInvalid, code)
+	assert.Contains(t, stderr, "field record not found")
+
+	_, _, code = runCLI(t, "plan", filepath.Join(dir, "missing.yaml"))
+	assert.Equal(t, exitUsage, code)
+}
+
+func TestColorize(t *testing.T) {
+	assert.Equal(t, "\x1b[32m+ A www 192.0.2.1\x1b[0m\n", colorize("+ A www 192.0.2.1\n", true))
+	assert.Equal(t, "\x1b[31m- A www 192.0.2.1\x1b[0m", colorize("- A www 192.0.2.1", true))
+	assert.Equal(t, "\x1b[33m~ A www ttl: 300 -> 600\x1b[0m\n", colorize("~ A www ttl: 300 -> 600\n", true))
+	assert.Equal(t, "+ A www 192.0.2.1\n", colorize("+ A www 192.0.2.1\n", false))
+}
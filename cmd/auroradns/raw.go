@@ -0,0 +1,245 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/nrdcg/auroradns"
+)
+
+const dateFormat = "20060102T150405Z"
+
+func signCommand(ctx context.Context, a *app, args []string) error {
+	fs := a.flagSet("sign")
+	curl := fs.Bool("curl", false, "print a ready-to-paste curl command")
+	data := fs.String("data", "", "request body (@FILE reads the body from a file)")
+	timestamp := fs.String("time", "", "signing time, as 20060102T150405Z (default: now)")
+	extended := fs.Bool("sign-query-and-body", false, "include the query string and the body hash in the signature (see TokenTransport.SignQueryAndBody)")
+
+	positional, err := parseFlags(fs, args, 2)
+	if err != nil {
+		return err
+	}
+
+	signedAt := time.Now()
+
+	if *timestamp != "" {
+		signedAt, err = time.Parse(dateFormat, *timestamp)
+		if err != nil {
+			return usageErrorf("invalid -time: %w", err)
+		}
+	}
+
+	config, err := a.config()
+	if err != nil {
+		return err
+	}
+
+	req, err := newSignedRequest(ctx, config, positional[0], positional[1], *data, *extended, signedAt)
+	if err != nil {
+		return err
+	}
+
+	if !*curl {
+		for _, key := range []string{"X-AuroraDNS-Date", "Authorization"} {
+			_, _ = fmt.Fprintf(a.stdout, "%s: %s\n", key, req.Header.Get(key))
+		}
+
+		return nil
+	}
+
+	_, _ = fmt.Fprintln(a.stdout, curlCommand(req, *data))
+
+	return nil
+}
+
+func rawCommand(ctx context.Context, a *app, args []string) error {
+	fs := a.flagSet("raw")
+	data := fs.String("data", "", "request body (@FILE reads the body from a file)")
+	include := fs.Bool("include", false, "print the status and the headers of the response")
+	extended := fs.Bool("sign-query-and-body", false, "include the query string and the body hash in the signature (see TokenTransport.SignQueryAndBody)")
+
+	positional, err := parseFlags(fs, args, 2)
+	if err != nil {
+		return err
+	}
+
+	config, err := a.config()
+	if err != nil {
+		return err
+	}
+
+	req, err := newSignedRequest(ctx, config, positional[0], positional[1], *data, *extended, time.Now())
+	if err != nil {
+		return err
+	}
+
+	httpClient := &http.Client{Timeout: 30 * time.Second}
+	if config.Timeout > 0 {
+		httpClient.Timeout = config.Timeout
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read body: %w", err)
+	}
+
+	if *include {
+		_, _ = fmt.Fprintf(a.stdout, "%s %s\n", resp.Proto, resp.Status)
+
+		for _, key := range slices.Sorted(maps.Keys(resp.Header)) {
+			for _, value := range resp.Header[key] {
+				_, _ = fmt.Fprintf(a.stdout, "%s: %s\n", key, value)
+			}
+		}
+
+		_, _ = fmt.Fprintln(a.stdout)
+	}
+
+	a.writeBody(body)
+
+	if resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices {
+		return nil
+	}
+
+	apiErr := &auroradns.ResponseError{}
+
+	if json.Unmarshal(body, apiErr) != nil || apiErr.ErrorCode == "" {
+		apiErr.ErrorCode = resp.Status
+	}
+
+	apiErr.StatusCode = resp.StatusCode
+
+	return apiErr
+}
+
+// writeBody writes the body of a response, indented if it is JSON.
+func (a *app) writeBody(body []byte) {
+	if len(body) == 0 {
+		return
+	}
+
+	buf := new(bytes.Buffer)
+
+	if json.Indent(buf, body, "", "  ") != nil {
+		buf.Reset()
+		buf.Write(body)
+	}
+
+	if !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+		buf.WriteByte('\n')
+	}
+
+	_, _ = a.stdout.Write(buf.Bytes())
+}
+
+// newSignedRequest creates a request to the endpoint, signed with the credentials of the configuration.
+func newSignedRequest(ctx context.Context, config *auroradns.Config, method, path, data string, extended bool, signedAt time.Time) (*http.Request, error) {
+	endpoint := config.Endpoint
+	if endpoint == "" {
+		endpoint = auroradns.DefaultBaseURL
+	}
+
+	baseURL, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid endpoint: %w", err)
+	}
+
+	ref, err := url.Parse(path)
+	if err != nil {
+		return nil, usageErrorf("invalid path: %w", err)
+	}
+
+	if ref.IsAbs() {
+		return nil, usageErrorf("the path must be relative to the endpoint (e.g. /zones)")
+	}
+
+	target := baseURL.JoinPath(ref.Path)
+	target.RawQuery = ref.RawQuery
+
+	body, err := readData(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var reader io.Reader = http.NoBody
+	if len(body) > 0 {
+		reader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, strings.ToUpper(method), target.String(), reader)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	if config.UserAgent != "" {
+		req.Header.Set("User-Agent", config.UserAgent)
+	}
+
+	tr, err := auroradns.NewTokenTransport(config.APIKey, config.Secret)
+	if err != nil {
+		return nil, &exitError{code: exitAuth, err: err}
+	}
+
+	tr.SignQueryAndBody = extended
+
+	err = tr.Sign(req, signedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// readData returns the body given with -data: the value itself, or the content of a file (@FILE).
+func readData(data string) ([]byte, error) {
+	filename, ok := strings.CutPrefix(data, "@")
+	if !ok {
+		return []byte(data), nil
+	}
+
+	return os.ReadFile(filename)
+}
+
+// curlCommand returns a curl command sending the signed request.
+func curlCommand(req *http.Request, data string) string {
+	parts := []string{"curl", "-X", req.Method}
+
+	for _, key := range []string{"Content-Type", "User-Agent", "X-AuroraDNS-Date", "Authorization"} {
+		if value := req.Header.Get(key); value != "" {
+			parts = append(parts, "-H", shellQuote(key+": "+value))
+		}
+	}
+
+	if filename, ok := strings.CutPrefix(data, "@"); ok {
+		parts = append(parts, "--data-binary", shellQuote("@"+filename))
+	} else if data != "" {
+		parts = append(parts, "--data-raw", shellQuote(data))
+	}
+
+	return strings.Join(append(parts, shellQuote(req.URL.String())), " ")
+}
+
+// shellQuote quotes a string for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
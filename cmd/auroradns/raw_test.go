@@ -0,0 +1,87 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSign(t *testing.T) {
+	setupCLI(t)
+
+	stdout, stderr, code := runCLI(t, "sign", "-time", "20240102T030405Z", "GET", "/zones")
+	require.Equal(t, exitOK, code, stderr)
+
+	expected := "X-AuroraDNS-Date: 20240102T030405Z\n" +
+		"Authorization: AuroraDNSv1 a2V5OlZFRGpBdFRzVW1yUDlBdUFFUnZqVXZ6bk1hTi9ReGhjVjVycG9ZWEQxUUk9\n"
+
+	assert.Equal(t, expected, stdout)
+}
+
+func TestSign_curl(t *testing.T) {
+	setupCLI(t)
+
+	t.Setenv("AURORA_ENDPOINT", "https://api.example.com")
+
+	stdout, stderr, code := runCLI(t, "sign", "-curl", "-time", "20240102T030405Z", "-data", `{"name":"it's"}`, "post", "/zones")
+	require.Equal(t, exitOK, code, stderr)
+
+	assert.Regexp(t, `^curl -X POST -H 'Content-Type: application/json' -H 'User-Agent: auroradns-cli' `+
+		`-H 'X-AuroraDNS-Date: 20240102T030405Z' -H 'Authorization: AuroraDNSv1 [A-Za-z0-9+/=]+' `+
+		`--data-raw '\{"name":"it'\\''s"\}' 'https://api.example.com/zones'\n$`, stdout)
+}
+
+func TestSign_usage(t *testing.T) {
+	setupCLI(t)
+
+	_, _, code := runCLI(t, "sign", "GET")
+	assert.Equal(t, exitUsage, code)
+
+	_, _, code = runCLI(t, "sign", "-time", "yesterday", "GET", "/zones")
+	assert.Equal(t, exitUsage, code)
+
+	_, _, code = runCLI(t, "sign", "GET", "https://example.com/zones")
+	assert.Equal(t, exitUsage, code)
+}
+
+func TestRaw(t *testing.T) {
+	server := setupCLI(t)
+
+	server.AddZone("example.com")
+
+	stdout, stderr, code := runCLI(t, "raw", "GET", "/zones")
+	require.Equal(t, exitOK, code, stderr)
+
+	expected := `[
+  {
+    "id": "zone-1",
+    "name": "example.com"
+  }
+]
+`
+	assert.Equal(t, expected, stdout)
+
+	stdout, stderr, code = runCLI(t, "raw", "-include", "POST", "/zones", "-data", `{"name":"example.org"}`)
+	require.Equal(t, exitOK, code, stderr)
+
+	assert.Contains(t, stdout, "HTTP/1.1 201 Created\nContent-Length: ")
+	assert.Contains(t, stdout, "Content-Type: application/json\n")
+	assert.Contains(t, stdout, "\n\n{\n  \"id\": \"zone-4\",\n  \"name\": \"example.org\"\n}\n")
+
+	assert.Len(t, server.Zones(), 2)
+}
+
+func TestRaw_error(t *testing.T) {
+	setupCLI(t)
+
+	stdout, stderr, code := runCLI(t, "raw", "DELETE", "/zones/zone-9")
+	assert.Equal(t, exitNotFound, code)
+
+	assert.JSONEq(t, `{"error":"ZoneNotFound","errormsg":"the zone zone-9 does not exist"}`, stdout)
+	assert.Equal(t, "auroradns raw: ZoneNotFound - the zone zone-9 does not exist\n", stderr)
+
+	_, stderr, code = runCLI(t, "-secret", "wrong", "raw", "GET", "/zones")
+	assert.Equal(t, exitAuth, code)
+	assert.Equal(t, "auroradns raw: Unauthorized - invalid signature\n", stderr)
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/nrdcg/auroradns"
+)
+
+// recordFlags the flags describing a record.
+type recordFlags struct {
+	recordType string
+	name       string
+	content    string
+	ttl        int
+}
+
+func newRecordFlags(fs *flag.FlagSet) *recordFlags {
+	f := &recordFlags{}
+
+	fs.StringVar(&f.recordType, "type", "", "record type (e.g. A, TXT)")
+	fs.StringVar(&f.name, "name", "", "record name, relative to the zone (default: the apex)")
+	fs.StringVar(&f.content, "content", "", "record content")
+	fs.IntVar(&f.ttl, "ttl", 0, "record TTL in seconds")
+
+	return f
+}
+
+// apply sets the fields of the record that have been set on the command line.
+func (f *recordFlags) apply(fs *flag.FlagSet, record *auroradns.Record) {
+	fs.Visit(func(fl *flag.Flag) {
+		switch fl.Name {
+		case "type":
+			record.RecordType = strings.ToUpper(f.recordType)
+		case "name":
+			record.Name = f.name
+		case "content":
+			record.Content = f.content
+		case "ttl":
+			record.TTL = f.ttl
+		}
+	})
+}
+
+func recordsList(ctx context.Context, a *app, args []string) error {
+	fs := a.flagSet("records list")
+	recordType := fs.String("type", "", "only the records of this type")
+	name := fs.String("name", "", "only the records with this name")
+
+	positional, err := parseFlags(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	client, err := a.newClient()
+	if err != nil {
+		return err
+	}
+
+	zone, err := resolveZone(ctx, client, positional[0])
+	if err != nil {
+		return err
+	}
+
+	query := auroradns.RecordQuery{Name: *name}
+	if *recordType != "" {
+		query.Types = []string{*recordType}
+	}
+
+	records, err := client.ListRecordsMatching(ctx, zone.ID, query)
+	if err != nil {
+		return err
+	}
+
+	return a.writeRecords(zone, records)
+}
+
+func recordsCreate(ctx context.Context, a *app, args []string) error {
+	fs := a.flagSet("records create")
+	flags := newRecordFlags(fs)
+
+	positional, err := parseFlags(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	if flags.recordType == "" || flags.content == "" {
+		return usageErrorf("-type and -content are required")
+	}
+
+	client, err := a.newClient()
+	if err != nil {
+		return err
+	}
+
+	zone, err := resolveZone(ctx, client, positional[0])
+	if err != nil {
+		return err
+	}
+
+	var record auroradns.Record
+
+	flags.apply(fs, &record)
+
+	newRecord, _, err := client.CreateRecordWithContext(ctx, zone.ID, record)
+	if err != nil {
+		return err
+	}
+
+	return a.writeRecords(zone, []auroradns.Record{*newRecord})
+}
+
+func recordsUpdate(ctx context.Context, a *app, args []string) error {
+	fs := a.flagSet("records update")
+	flags := newRecordFlags(fs)
+
+	positional, err := parseFlags(fs, args, 2)
+	if err != nil {
+		return err
+	}
+
+	client, err := a.newClient()
+	if err != nil {
+		return err
+	}
+
+	zone, err := resolveZone(ctx, client, positional[0])
+	if err != nil {
+		return err
+	}
+
+	record, err := findRecord(ctx, client, zone, positional[1])
+	if err != nil {
+		return err
+	}
+
+	flags.apply(fs, &record)
+
+	updatedRecord, _, err := client.UpdateRecordWithContext(ctx, zone.ID, record.ID, record)
+	if err != nil {
+		return err
+	}
+
+	return a.writeRecords(zone, []auroradns.Record{*updatedRecord})
+}
+
+func recordsDelete(ctx context.Context, a *app, args []string) error {
+	positional, err := parseFlags(a.flagSet("records delete"), args, 2)
+	if err != nil {
+		return err
+	}
+
+	client, err := a.newClient()
+	if err != nil {
+		return err
+	}
+
+	zone, err := resolveZone(ctx, client, positional[0])
+	if err != nil {
+		return err
+	}
+
+	record, err := findRecord(ctx, client, zone, positional[1])
+	if err != nil {
+		return err
+	}
+
+	_, _, err = client.DeleteRecordWithContext(ctx, zone.ID, record.ID)
+	if err != nil {
+		return err
+	}
+
+	return a.writeRecords(zone, []auroradns.Record{record})
+}
+
+func findRecord(ctx context.Context, client *auroradns.Client, zone auroradns.Zone, recordID string) (auroradns.Record, error) {
+	records, _, err := client.ListRecordsWithContext(ctx, zone.ID)
+	if err != nil {
+		return auroradns.Record{}, err
+	}
+
+	for _, record := range records {
+		if record.ID == recordID {
+			return record, nil
+		}
+	}
+
+	return auroradns.Record{}, &exitError{code: exitNotFound, err: fmt.Errorf("record %s not found in the zone %s", recordID, zone.Name)}
+}
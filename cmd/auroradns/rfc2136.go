@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/nrdcg/auroradns/rfc2136"
+	"gopkg.in/yaml.v3"
+)
+
+// keysFile The TSIG keys of the rfc2136 command.
+type keysFile struct {
+	Keys []rfc2136.Key `yaml:"keys"`
+}
+
+func rfc2136Command(ctx context.Context, a *app, args []string) error {
+	fs := a.flagSet("rfc2136")
+	listen := fs.String("listen", ":53", "address to listen on, over UDP and TCP")
+	file := fs.String("keys", "", "YAML file with the TSIG keys and the zones they can update (required)")
+
+	_, err := parseFlags(fs, args, 0)
+	if err != nil {
+		return err
+	}
+
+	if *file == "" {
+		return usageErrorf("-keys is required")
+	}
+
+	keys, err := readKeysFile(*file)
+	if err != nil {
+		return err
+	}
+
+	client, err := a.newClient()
+	if err != nil {
+		return err
+	}
+
+	logger := slog.New(slog.NewTextHandler(a.stderr, nil))
+
+	gateway, err := rfc2136.NewGateway(client, rfc2136.Config{Keys: keys, Logger: logger})
+	if err != nil {
+		return &exitError{code: exitInvalid, err: fmt.Errorf("%s: %w", *file, err)}
+	}
+
+	logger.Info("rfc2136: listening", slog.String("addr", *listen))
+
+	return gateway.ListenAndServe(ctx, *listen)
+}
+
+func readKeysFile(file string) ([]rfc2136.Key, error) {
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(raw))
+	decoder.KnownFields(true)
+
+	var keys keysFile
+
+	err = decoder.Decode(&keys)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, &exitError{code: exitInvalid, err: fmt.Errorf("%s: %w", file, err)}
+	}
+
+	return keys.Keys, nil
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRFC2136_invalid(t *testing.T) {
+	setupCLI(t)
+
+	dir := t.TempDir()
+
+	unknownField := filepath.Join(dir, "unknown.yaml")
+	require.NoError(t, os.WriteFile(unknownField, []byte("keys:\n  - name: dhcp\n    secrets: abc\n"), 0o600))
+
+	noKey := filepath.Join(dir, "empty.yaml")
+	require.NoError(t, os.WriteFile(noKey, []byte("keys: []\n"), 0o600))
+
+	_, _, code := runCLI(t, "rfc2136")
+	assert.Equal(t, exitUsage, code)
+
+	_, stderr, code := runCLI(t, "rfc2136", "-keys", unknownField)
+	assert.Equal(t, exitInvalid, code)
+	assert.Contains(t, stderr, "field secrets not found")
+
+	_, stderr, code = runCLI(t, "rfc2136", "-keys", noKey)
+	assert.Equal(t, exitInvalid, code)
+	assert.Contains(t, stderr, "at least one key is required")
+}
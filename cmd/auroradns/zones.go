@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/nrdcg/auroradns"
+)
+
+func zonesList(ctx context.Context, a *app, args []string) error {
+	_, err := parseFlags(a.flagSet("zones list"), args, 0)
+	if err != nil {
+		return err
+	}
+
+	client, err := a.newClient()
+	if err != nil {
+		return err
+	}
+
+	zones, _, err := client.ListZonesWithContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	return a.writeZones(zones)
+}
+
+func zonesCreate(ctx context.Context, a *app, args []string) error {
+	positional, err := parseFlags(a.flagSet("zones create"), args, 1)
+	if err != nil {
+		return err
+	}
+
+	client, err := a.newClient()
+	if err != nil {
+		return err
+	}
+
+	zone, _, err := client.CreateZoneWithContext(ctx, positional[0])
+	if err != nil {
+		return err
+	}
+
+	return a.writeZones([]auroradns.Zone{*zone})
+}
+
+func zonesDelete(ctx context.Context, a *app, args []string) error {
+	fs := a.flagSet("zones delete")
+	force := fs.Bool("force", false, "delete the zone even if it contains records")
+
+	positional, err := parseFlags(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	client, err := a.newClient()
+	if err != nil {
+		return err
+	}
+
+	zone, err := resolveZone(ctx, client, positional[0])
+	if err != nil {
+		return err
+	}
+
+	if *force {
+		ctx = auroradns.ForceZoneDeletion(ctx)
+	}
+
+	_, _, err = client.DeleteZoneWithContext(ctx, zone.ID)
+	if err != nil {
+		if errors.Is(err, auroradns.ErrProtected) && !*force {
+			return fmt.Errorf("%w (use -force to delete it)", err)
+		}
+
+		return err
+	}
+
+	return a.writeZones([]auroradns.Zone{zone})
+}
+
+// resolveZone finds a zone by ID or by name.
+func resolveZone(ctx context.Context, client *auroradns.Client, ref string) (auroradns.Zone, error) {
+	zones, _, err := client.ListZonesWithContext(ctx)
+	if err != nil {
+		return auroradns.Zone{}, err
+	}
+
+	for _, zone := range zones {
+		if zone.ID == ref || auroradns.NormalizeZoneName(zone.Name) == auroradns.NormalizeZoneName(ref) {
+			return zone, nil
+		}
+	}
+
+	return auroradns.Zone{}, &exitError{code: exitNotFound, err: fmt.Errorf("zone %s not found", ref)}
+}
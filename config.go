@@ -0,0 +1,362 @@
+package auroradns
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Environment variables read by NewClientFromEnv.
+const (
+	EnvAPIKey         = "AURORA_API_KEY"
+	EnvSecret         = "AURORA_SECRET"
+	EnvEndpoint       = "AURORA_ENDPOINT"
+	EnvUserAgent      = "AURORA_USER_AGENT"
+	EnvTimeout        = "AURORA_TIMEOUT"
+	EnvMaxRetries     = "AURORA_MAX_RETRIES"
+	EnvRetryWait      = "AURORA_RETRY_WAIT"
+	EnvRateLimit      = "AURORA_RATE_LIMIT"
+	EnvRateLimitBurst = "AURORA_RATE_LIMIT_BURST"
+	EnvProfile        = "AURORA_PROFILE"
+	EnvConfigFile     = "AURORA_CONFIG_FILE"
+)
+
+// DefaultProfile the profile used when no profile is specified.
+const DefaultProfile = "default"
+
+var errProfileNotFound = errors.New("profile not found")
+
+// Config The settings used to build an authenticated client.
+type Config struct {
+	APIKey    string
+	Secret    string
+	Endpoint  string
+	UserAgent string
+
+	// Timeout of the HTTP client (30 seconds if zero).
+	Timeout time.Duration
+
+	// MaxRetries and RetryWait are used with WithRetry.
+	MaxRetries int
+	RetryWait  time.Duration
+
+	// RateLimit (requests per second) and RateLimitBurst are used with WithRateLimit.
+	// No rate limit is applied if RateLimit is zero.
+	RateLimit      float64
+	RateLimitBurst int
+
+	// where the settings come from, used in error messages.
+	useEnv   bool
+	profile  string
+	filename string
+}
+
+// DefaultConfigFile Returns the path of the default configuration file (<user config dir>/auroradns/config).
+func DefaultConfigFile() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "auroradns", "config"), nil
+}
+
+// LoadConfig Reads a profile from a configuration file.
+//
+// The file contains one section per profile, with "key = value" settings:
+//
+//	[default]
+//	api_key = xxx
+//	secret = yyy
+//
+//	[profile staging]
+//	api_key = zzz
+//	secret = www
+//	endpoint = https://api.staging.example.com
+//	timeout = 10s
+//	user_agent = my-tool
+//	max_retries = 3
+//	retry_wait = 1s
+//	rate_limit = 5
+//	rate_limit_burst = 10
+//
+// The section of a profile is either "[name]" or "[profile name]".
+func LoadConfig(filename, profile string) (*Config, error) {
+	if profile == "" {
+		profile = DefaultProfile
+	}
+
+	profiles, err := readProfiles(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	settings, ok := profiles[profile]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q in %s", errProfileNotFound, profile, filename)
+	}
+
+	config := &Config{profile: profile, filename: filename}
+
+	for key, value := range settings {
+		err = config.set(key, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s in profile %q of %s: %w", key, profile, filename, err)
+		}
+	}
+
+	return config, nil
+}
+
+// ConfigFromEnv Reads the configuration from the environment.
+//
+// The profile named by AURORA_PROFILE is read first from the file named by AURORA_CONFIG_FILE
+// (DefaultConfigFile if not set), then each AURORA_* variable overrides the matching profile setting.
+// A missing configuration file is only an error if AURORA_PROFILE or AURORA_CONFIG_FILE is set,
+// and a missing profile only if AURORA_PROFILE is set.
+func ConfigFromEnv() (*Config, error) {
+	profile := os.Getenv(EnvProfile)
+
+	filename := os.Getenv(EnvConfigFile)
+	if filename == "" {
+		filename, _ = DefaultConfigFile()
+	}
+
+	config := &Config{}
+
+	if filename != "" {
+		var err error
+
+		config, err = LoadConfig(filename, profile)
+
+		switch {
+		case err == nil:
+		case profile == "" && (errors.Is(err, errProfileNotFound) || errors.Is(err, fs.ErrNotExist) && os.Getenv(EnvConfigFile) == ""):
+			config = &Config{}
+		default:
+			return nil, err
+		}
+	}
+
+	config.useEnv = true
+
+	envs := []struct{ env, key string }{
+		{env: EnvAPIKey, key: "api_key"},
+		{env: EnvSecret, key: "secret"},
+		{env: EnvEndpoint, key: "endpoint"},
+		{env: EnvUserAgent, key: "user_agent"},
+		{env: EnvTimeout, key: "timeout"},
+		{env: EnvMaxRetries, key: "max_retries"},
+		{env: EnvRetryWait, key: "retry_wait"},
+		{env: EnvRateLimit, key: "rate_limit"},
+		{env: EnvRateLimitBurst, key: "rate_limit_burst"},
+	}
+
+	for _, e := range envs {
+		value, ok := os.LookupEnv(e.env)
+		if !ok || value == "" {
+			continue
+		}
+
+		err := config.set(e.key, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", e.env, err)
+		}
+	}
+
+	return config, nil
+}
+
+// Validate Checks that the credentials are set.
+func (c *Config) Validate() error {
+	if c.APIKey == "" {
+		return c.missing("API key", EnvAPIKey, "api_key")
+	}
+
+	if c.Secret == "" {
+		return c.missing("secret", EnvSecret, "secret")
+	}
+
+	return nil
+}
+
+// NewClient Creates a new authenticated client from the configuration.
+// The options are applied after the options derived from the configuration.
+func (c *Config) NewClient(opts ...Option) (*Client, error) {
+	err := c.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	tr, err := NewTokenTransport(c.APIKey, c.Secret)
+	if err != nil {
+		return nil, err
+	}
+
+	httpClient := tr.Client()
+	if c.Timeout > 0 {
+		httpClient.Timeout = c.Timeout
+	}
+
+	options := []Option{
+		WithBaseURL(c.Endpoint),
+		func(client *Client) error {
+			client.UserAgent = c.UserAgent
+			return nil
+		},
+	}
+
+	if c.MaxRetries > 0 {
+		options = append(options, WithRetry(c.MaxRetries, c.RetryWait))
+	}
+
+	if c.RateLimit > 0 {
+		options = append(options, WithRateLimit(c.RateLimit, c.RateLimitBurst))
+	}
+
+	return NewClient(httpClient, append(options, opts...)...)
+}
+
+// NewClientFromEnv Creates a new authenticated client from the environment (see ConfigFromEnv).
+func NewClientFromEnv(opts ...Option) (*Client, error) {
+	config, err := ConfigFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	return config.NewClient(opts...)
+}
+
+// NewClientFromProfile Creates a new authenticated client from a profile of a configuration file (see LoadConfig).
+func NewClientFromProfile(filename, profile string, opts ...Option) (*Client, error) {
+	config, err := LoadConfig(filename, profile)
+	if err != nil {
+		return nil, err
+	}
+
+	return config.NewClient(opts...)
+}
+
+func (c *Config) set(key, value string) error {
+	var err error
+
+	switch key {
+	case "api_key":
+		c.APIKey = value
+	case "secret":
+		c.Secret = value
+	case "endpoint":
+		c.Endpoint = value
+	case "user_agent":
+		c.UserAgent = value
+	case "timeout":
+		c.Timeout, err = parseDuration(value)
+	case "max_retries":
+		c.MaxRetries, err = strconv.Atoi(value)
+		if err == nil && c.MaxRetries < 0 {
+			err = errors.New("must be positive")
+		}
+	case "retry_wait":
+		c.RetryWait, err = parseDuration(value)
+	case "rate_limit":
+		c.RateLimit, err = strconv.ParseFloat(value, 64)
+		if err == nil && c.RateLimit < 0 {
+			err = errors.New("must be positive")
+		}
+	case "rate_limit_burst":
+		c.RateLimitBurst, err = strconv.Atoi(value)
+	default:
+		return errors.New("unknown setting")
+	}
+
+	return err
+}
+
+func (c *Config) missing(name, env, key string) error {
+	var sources []string
+
+	if c.useEnv {
+		sources = append(sources, env)
+	}
+
+	if c.filename != "" {
+		sources = append(sources, fmt.Sprintf("%s in profile %q of %s", key, c.profile, c.filename))
+	}
+
+	if len(sources) == 0 {
+		return fmt.Errorf("missing %s", name)
+	}
+
+	return fmt.Errorf("missing %s: set %s", name, strings.Join(sources, " or "))
+}
+
+// readProfiles parses a configuration file.
+func readProfiles(filename string) (map[string]map[string]string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = file.Close() }()
+
+	profiles := make(map[string]map[string]string)
+
+	var current map[string]string
+
+	scanner := bufio.NewScanner(file)
+
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case line == "", strings.HasPrefix(line, "#"), strings.HasPrefix(line, ";"):
+			continue
+
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			name := strings.TrimSpace(line[1 : len(line)-1])
+			name = strings.TrimSpace(strings.TrimPrefix(name, "profile "))
+
+			if name == "" {
+				return nil, fmt.Errorf("%s:%d: empty profile name", filename, lineNumber)
+			}
+
+			current = make(map[string]string)
+			profiles[name] = current
+
+		default:
+			key, value, ok := strings.Cut(line, "=")
+			if !ok {
+				return nil, fmt.Errorf("%s:%d: expected key = value", filename, lineNumber)
+			}
+
+			if current == nil {
+				return nil, fmt.Errorf("%s:%d: setting outside of a profile", filename, lineNumber)
+			}
+
+			current[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+
+	err = scanner.Err()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", filename, err)
+	}
+
+	return profiles, nil
+}
+
+// parseDuration parses a duration, a number without unit is a number of seconds.
+func parseDuration(value string) (time.Duration, error) {
+	seconds, err := strconv.Atoi(value)
+	if err == nil {
+		return time.Duration(seconds) * time.Second, nil
+	}
+
+	return time.ParseDuration(value)
+}
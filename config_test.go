@@ -0,0 +1,195 @@
+package auroradns
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testConfigFile = `
+# Aurora DNS accounts
+[default]
+api_key = key
+secret = secret
+
+[profile staging]
+api_key = staging-key
+secret = staging-secret
+endpoint = https://api.staging.example.com
+timeout = 10s
+user_agent = my-tool
+max_retries = 3
+retry_wait = 2
+rate_limit = 5
+rate_limit_burst = 10
+
+[incomplete]
+api_key = key
+`
+
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+
+	filename := filepath.Join(t.TempDir(), "config")
+
+	err := os.WriteFile(filename, []byte(content), 0o600)
+	require.NoError(t, err)
+
+	return filename
+}
+
+func clearEnv(t *testing.T) {
+	t.Helper()
+
+	for _, env := range []string{
+		EnvAPIKey, EnvSecret, EnvEndpoint, EnvUserAgent, EnvTimeout, EnvMaxRetries,
+		EnvRetryWait, EnvRateLimit, EnvRateLimitBurst, EnvProfile, EnvConfigFile,
+	} {
+		t.Setenv(env, "")
+	}
+
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+}
+
+func TestLoadConfig(t *testing.T) {
+	filename := writeConfigFile(t, testConfigFile)
+
+	config, err := LoadConfig(filename, "staging")
+	require.NoError(t, err)
+
+	assert.Equal(t, "staging-key", config.APIKey)
+	assert.Equal(t, "staging-secret", config.Secret)
+	assert.Equal(t, "https://api.staging.example.com", config.Endpoint)
+	assert.Equal(t, "my-tool", config.UserAgent)
+	assert.Equal(t, 10*time.Second, config.Timeout)
+	assert.Equal(t, 3, config.MaxRetries)
+	assert.Equal(t, 2*time.Second, config.RetryWait)
+	assert.InDelta(t, 5, config.RateLimit, 0)
+	assert.Equal(t, 10, config.RateLimitBurst)
+
+	config, err = LoadConfig(filename, "")
+	require.NoError(t, err)
+
+	assert.Equal(t, "key", config.APIKey)
+}
+
+func TestLoadConfig_errors(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		content  string
+		profile  string
+		expected string
+	}{
+		{
+			desc:     "unknown profile",
+			content:  testConfigFile,
+			profile:  "prod",
+			expected: `profile not found: "prod" in %s`,
+		},
+		{
+			desc:     "missing secret",
+			content:  testConfigFile,
+			profile:  "incomplete",
+			expected: `missing secret: set secret in profile "incomplete" of %s`,
+		},
+		{
+			desc:     "invalid value",
+			content:  "[default]\ntimeout = soon\n",
+			expected: `invalid timeout in profile "default" of %s: time: invalid duration "soon"`,
+		},
+		{
+			desc:     "unknown setting",
+			content:  "[default]\nfoo = bar\n",
+			expected: `invalid foo in profile "default" of %s: unknown setting`,
+		},
+		{
+			desc:     "setting outside of a profile",
+			content:  "api_key = key\n",
+			expected: `%s:1: setting outside of a profile`,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			filename := writeConfigFile(t, test.content)
+
+			_, err := NewClientFromProfile(filename, test.profile)
+			require.EqualError(t, err, fmt.Sprintf(test.expected, filename))
+		})
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	clearEnv(t)
+
+	t.Setenv(EnvAPIKey, "key")
+	t.Setenv(EnvSecret, "secret")
+	t.Setenv(EnvEndpoint, "https://api.example.com")
+	t.Setenv(EnvTimeout, "5s")
+
+	config, err := ConfigFromEnv()
+	require.NoError(t, err)
+
+	assert.Equal(t, "key", config.APIKey)
+	assert.Equal(t, "secret", config.Secret)
+	assert.Equal(t, "https://api.example.com", config.Endpoint)
+	assert.Equal(t, 5*time.Second, config.Timeout)
+
+	client, err := config.NewClient()
+	require.NoError(t, err)
+
+	assert.Equal(t, "https://api.example.com", client.baseURL.String())
+	assert.Equal(t, 5*time.Second, client.httpClient.Timeout)
+	assert.IsType(t, &TokenTransport{}, client.httpClient.Transport)
+}
+
+func TestConfigFromEnv_profile(t *testing.T) {
+	clearEnv(t)
+
+	t.Setenv(EnvConfigFile, writeConfigFile(t, testConfigFile))
+	t.Setenv(EnvProfile, "staging")
+	t.Setenv(EnvSecret, "override")
+
+	client, err := NewClientFromEnv()
+	require.NoError(t, err)
+
+	assert.Equal(t, "https://api.staging.example.com", client.baseURL.String())
+	assert.Equal(t, "my-tool", client.UserAgent)
+	assert.Equal(t, 3, client.maxRetries)
+	assert.NotNil(t, client.limiter)
+}
+
+func TestNewClientFromEnv_missing(t *testing.T) {
+	clearEnv(t)
+
+	_, err := NewClientFromEnv()
+	require.EqualError(t, err, "missing API key: set AURORA_API_KEY")
+
+	t.Setenv(EnvAPIKey, "key")
+
+	_, err = NewClientFromEnv()
+	require.EqualError(t, err, "missing secret: set AURORA_SECRET")
+
+	filename := writeConfigFile(t, testConfigFile)
+	t.Setenv(EnvConfigFile, filename)
+	t.Setenv(EnvProfile, "incomplete")
+	t.Setenv(EnvAPIKey, "")
+
+	_, err = NewClientFromEnv()
+	require.EqualError(t, err, `missing secret: set AURORA_SECRET or secret in profile "incomplete" of `+filename)
+}
+
+func TestNewClientFromEnv_invalid(t *testing.T) {
+	clearEnv(t)
+
+	t.Setenv(EnvMaxRetries, "-1")
+
+	_, err := NewClientFromEnv()
+	require.EqualError(t, err, "invalid AURORA_MAX_RETRIES: must be positive")
+}
@@ -0,0 +1,454 @@
+// Package ddns Keeps the A and AAAA records of a host up to date with its current addresses (dynamic DNS).
+//
+// The addresses are detected with sources (HTTP echo endpoints, network interfaces),
+// and the records are updated with auroradns.Client.EnsureRecord when the addresses change.
+// A state file keeps the last published addresses, so an unchanged address does not call the API:
+//
+//	updater, err := ddns.NewUpdater(client, ddns.Config{
+//		Zone:      "example.com",
+//		Name:      "site1",
+//		IPv4:      true,
+//		IPv6:      true,
+//		Sources:   ddns.DefaultSources(),
+//		StateFile: "/var/lib/auroradns/ddns.json",
+//	})
+//
+//	results, err := updater.Update(ctx) // one-shot, e.g. from cron
+//	err = updater.Run(ctx)              // daemon
+package ddns
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/nrdcg/auroradns"
+)
+
+// Default values of the configuration.
+const (
+	DefaultTTL      = 300
+	DefaultInterval = 5 * time.Minute
+)
+
+// Actions of a Result, in addition to the auroradns.EnsureAction values.
+const (
+	// ActionPending the address changed, but it has not been stable for Config.Debounce yet.
+	ActionPending = "pending"
+
+	// ActionFailed the address could not be detected or published.
+	ActionFailed = "failed"
+)
+
+// Config the configuration of an Updater.
+type Config struct {
+	// Zone the name of the zone.
+	Zone string
+
+	// Name the name of the records, relative to the zone ("" or "@" for the apex).
+	Name string
+
+	// TTL of the records (DefaultTTL by default).
+	TTL int
+
+	// IPv4 and IPv6 select the records to keep up to date: A, AAAA, or both.
+	IPv4 bool
+	IPv6 bool
+
+	// Sources the sources of the addresses, tried in order for each family until one returns an address of this family.
+	Sources []Source
+
+	// StateFile the file where the published addresses are saved (no file by default: the state is kept in memory).
+	StateFile string
+
+	// Debounce the duration during which a new address must stay the same before being published (no delay by default).
+	// With a state file, the pending address is saved, so the debounce also works in one-shot mode.
+	Debounce time.Duration
+
+	// Interval between two updates in Run (DefaultInterval by default).
+	Interval time.Duration
+
+	// Logger logs the results in Run (slog.Default by default).
+	Logger *slog.Logger
+}
+
+// Result The result of the update of a record.
+type Result struct {
+	RecordType string     `json:"type"`
+	Name       string     `json:"name"`
+	Address    netip.Addr `json:"address,omitzero"`
+	Action     string     `json:"action"`
+	Err        error      `json:"-"`
+}
+
+// String Returns the text rendering of the result (e.g. "A site1.example.com 192.0.2.1 updated").
+func (r Result) String() string {
+	s := fmt.Sprintf("%s %s", r.RecordType, r.Name)
+
+	if r.Address.IsValid() {
+		s += " " + r.Address.String()
+	}
+
+	s += " " + r.Action
+
+	if r.Err != nil {
+		s += ": " + r.Err.Error()
+	}
+
+	return s
+}
+
+// State The content of the state file.
+type State struct {
+	Zone    string                  `json:"zone"`
+	Name    string                  `json:"name"`
+	Records map[string]*RecordState `json:"records"`
+}
+
+// RecordState The state of a record (A or AAAA).
+type RecordState struct {
+	// Address the last published address.
+	Address netip.Addr `json:"address,omitzero"`
+	Updated time.Time  `json:"updated,omitzero"`
+
+	// Pending the detected address waiting for the debounce, and the time it was first detected.
+	Pending      netip.Addr `json:"pending,omitzero"`
+	PendingSince time.Time  `json:"pending_since,omitzero"`
+}
+
+// Updater Keeps the records up to date.
+type Updater struct {
+	client *auroradns.Client
+	config Config
+	now    func() time.Time
+
+	mu     sync.Mutex
+	zoneID string
+	state  *State
+}
+
+// NewUpdater Creates a new Updater, and loads the state file.
+// A state file of another zone or name is ignored.
+func NewUpdater(client *auroradns.Client, config Config) (*Updater, error) {
+	if client == nil {
+		return nil, errors.New("the client is required")
+	}
+
+	if config.Zone == "" {
+		return nil, errors.New("the zone is required")
+	}
+
+	if !config.IPv4 && !config.IPv6 {
+		return nil, errors.New("at least one of IPv4 and IPv6 is required")
+	}
+
+	if len(config.Sources) == 0 {
+		return nil, errors.New("at least one source is required")
+	}
+
+	if config.TTL <= 0 {
+		config.TTL = DefaultTTL
+	}
+
+	if config.Interval <= 0 {
+		config.Interval = DefaultInterval
+	}
+
+	if config.Logger == nil {
+		config.Logger = slog.Default()
+	}
+
+	config.Zone = auroradns.NormalizeZoneName(config.Zone)
+	config.Name = auroradns.NormalizeName(config.Name, config.Zone)
+
+	u := &Updater{client: client, config: config, now: time.Now}
+
+	state, err := u.loadState()
+	if err != nil {
+		return nil, err
+	}
+
+	u.state = state
+
+	return u, nil
+}
+
+// Update Detects the addresses and updates the records that changed (one-shot).
+// The errors of the records are in the results, and joined in the returned error.
+func (u *Updater) Update(ctx context.Context) ([]Result, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	var (
+		results []Result
+		errs    []error
+	)
+
+	for _, recordType := range u.recordTypes() {
+		result := u.update(ctx, recordType)
+		if result.Err != nil {
+			errs = append(errs, fmt.Errorf("%s %s: %w", result.RecordType, result.Name, result.Err))
+		}
+
+		results = append(results, result)
+	}
+
+	err := u.saveState()
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	return results, errors.Join(errs...)
+}
+
+// Run Updates the records every Config.Interval until the context ends.
+// The errors are logged, and do not stop the updater.
+func (u *Updater) Run(ctx context.Context) error {
+	ticker := time.NewTicker(u.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		results, err := u.Update(ctx)
+
+		for _, result := range results {
+			u.log(ctx, result)
+		}
+
+		if err != nil && ctx.Err() == nil {
+			u.config.Logger.ErrorContext(ctx, "ddns update failed", slog.Any("error", err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (u *Updater) log(ctx context.Context, result Result) {
+	attrs := []any{
+		slog.String("type", result.RecordType),
+		slog.String("name", result.Name),
+		slog.String("action", result.Action),
+	}
+
+	if result.Address.IsValid() {
+		attrs = append(attrs, slog.String("address", result.Address.String()))
+	}
+
+	switch result.Action {
+	case string(auroradns.EnsureUnchanged):
+		u.config.Logger.DebugContext(ctx, "ddns record", attrs...)
+	case ActionFailed:
+		u.config.Logger.WarnContext(ctx, "ddns record", append(attrs, slog.Any("error", result.Err))...)
+	default:
+		u.config.Logger.InfoContext(ctx, "ddns record", attrs...)
+	}
+}
+
+func (u *Updater) update(ctx context.Context, recordType string) Result {
+	result := Result{RecordType: recordType, Name: u.fqdn()}
+
+	addr, err := u.detect(ctx, recordType)
+	if err != nil {
+		result.Action = ActionFailed
+		result.Err = err
+
+		return result
+	}
+
+	result.Address = addr
+
+	state := u.state.Records[recordType]
+	if state == nil {
+		state = &RecordState{}
+		u.state.Records[recordType] = state
+	}
+
+	if state.Address == addr {
+		state.Pending = netip.Addr{}
+		state.PendingSince = time.Time{}
+
+		result.Action = string(auroradns.EnsureUnchanged)
+
+		return result
+	}
+
+	now := u.now()
+
+	if u.config.Debounce > 0 {
+		if state.Pending != addr {
+			state.Pending = addr
+			state.PendingSince = now
+		}
+
+		if now.Sub(state.PendingSince) < u.config.Debounce {
+			result.Action = ActionPending
+
+			return result
+		}
+	}
+
+	zoneID, err := u.findZone(ctx)
+	if err != nil {
+		result.Action = ActionFailed
+		result.Err = err
+
+		return result
+	}
+
+	record := auroradns.Record{RecordType: recordType, Name: u.config.Name, Content: addr.String(), TTL: u.config.TTL}
+
+	_, action, err := u.client.EnsureRecord(ctx, zoneID, record)
+	if err != nil {
+		result.Action = ActionFailed
+		result.Err = err
+
+		return result
+	}
+
+	*state = RecordState{Address: addr, Updated: now}
+
+	result.Action = string(action)
+
+	return result
+}
+
+// detect Returns the first address of the family of the record type found by the sources.
+func (u *Updater) detect(ctx context.Context, recordType string) (netip.Addr, error) {
+	var errs []error
+
+	for _, source := range u.config.Sources {
+		addresses, err := source.Addresses(ctx)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		for _, addr := range addresses {
+			if (recordType == auroradns.RecordTypeA) == addr.Is4() {
+				return addr, nil
+			}
+		}
+	}
+
+	return netip.Addr{}, errors.Join(append([]error{fmt.Errorf("no address found for %s", recordType)}, errs...)...)
+}
+
+func (u *Updater) findZone(ctx context.Context) (string, error) {
+	if u.zoneID != "" {
+		return u.zoneID, nil
+	}
+
+	zones, _, err := u.client.ListZonesWithContext(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to list the zones: %w", err)
+	}
+
+	for _, zone := range zones {
+		if auroradns.NormalizeZoneName(zone.Name) == u.config.Zone {
+			u.zoneID = zone.ID
+
+			return zone.ID, nil
+		}
+	}
+
+	return "", fmt.Errorf("zone %s not found", u.config.Zone)
+}
+
+func (u *Updater) recordTypes() []string {
+	var types []string
+
+	if u.config.IPv4 {
+		types = append(types, auroradns.RecordTypeA)
+	}
+
+	if u.config.IPv6 {
+		types = append(types, auroradns.RecordTypeAAAA)
+	}
+
+	return types
+}
+
+func (u *Updater) fqdn() string {
+	if u.config.Name == "" {
+		return u.config.Zone
+	}
+
+	return u.config.Name + "." + u.config.Zone
+}
+
+func (u *Updater) loadState() (*State, error) {
+	state := &State{Zone: u.config.Zone, Name: u.config.Name, Records: make(map[string]*RecordState)}
+
+	if u.config.StateFile == "" {
+		return state, nil
+	}
+
+	data, err := os.ReadFile(u.config.StateFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return state, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the state file: %w", err)
+	}
+
+	saved := &State{}
+
+	err = json.Unmarshal(data, saved)
+	if err != nil {
+		return nil, fmt.Errorf("invalid state file %s: %w", u.config.StateFile, err)
+	}
+
+	if saved.Zone != state.Zone || saved.Name != state.Name || saved.Records == nil {
+		return state, nil
+	}
+
+	return saved, nil
+}
+
+// saveState Writes the state file atomically.
+func (u *Updater) saveState() error {
+	if u.config.StateFile == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(u.state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(u.config.StateFile), filepath.Base(u.config.StateFile)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write the state file: %w", err)
+	}
+
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	_, err = tmp.Write(append(data, '\n'))
+	if err == nil {
+		err = tmp.Close()
+	} else {
+		_ = tmp.Close()
+	}
+
+	if err == nil {
+		err = os.Rename(tmp.Name(), u.config.StateFile)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to write the state file: %w", err)
+	}
+
+	return nil
+}
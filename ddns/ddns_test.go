@@ -0,0 +1,222 @@
+package ddns
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nrdcg/auroradns"
+	"github.com/nrdcg/auroradns/internal/fakeapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type staticSource struct {
+	mu        sync.Mutex
+	addresses []netip.Addr
+	err       error
+}
+
+func (s *staticSource) set(addresses ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.addresses = nil
+	for _, addr := range addresses {
+		s.addresses = append(s.addresses, netip.MustParseAddr(addr))
+	}
+}
+
+func (s *staticSource) Addresses(_ context.Context) ([]netip.Addr, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.addresses, s.err
+}
+
+func setupTest(t *testing.T) (*auroradns.Client, *fakeapi.Server, string) {
+	t.Helper()
+
+	api := fakeapi.NewServer("", "")
+	t.Cleanup(api.Close)
+
+	client, err := auroradns.NewClient(nil, auroradns.WithBaseURL(api.URL))
+	require.NoError(t, err)
+
+	zoneID := api.AddZone("example.com")
+
+	return client, api, zoneID
+}
+
+func TestUpdater_Update(t *testing.T) {
+	client, api, zoneID := setupTest(t)
+
+	source := &staticSource{}
+	source.set("192.0.2.1", "2001:db8::1")
+
+	config := Config{
+		Zone:      "example.com.",
+		Name:      "site1.example.com.",
+		IPv4:      true,
+		IPv6:      true,
+		Sources:   []Source{source},
+		StateFile: filepath.Join(t.TempDir(), "state.json"),
+	}
+
+	updater, err := NewUpdater(client, config)
+	require.NoError(t, err)
+
+	results, err := updater.Update(t.Context())
+	require.NoError(t, err)
+
+	expected := []Result{
+		{RecordType: "A", Name: "site1.example.com", Address: netip.MustParseAddr("192.0.2.1"), Action: "created"},
+		{RecordType: "AAAA", Name: "site1.example.com", Address: netip.MustParseAddr("2001:db8::1"), Action: "created"},
+	}
+	assert.Equal(t, expected, results)
+
+	assert.Equal(t, []fakeapi.Record{
+		{ID: "record-4", Type: "A", Name: "site1", Content: "192.0.2.1", TTL: DefaultTTL},
+		{ID: "record-5", Type: "AAAA", Name: "site1", Content: "2001:db8::1", TTL: DefaultTTL},
+	}, api.Records(zoneID)[2:])
+
+	requests := len(api.Requests())
+
+	// a new updater reads the state file: no API calls.
+	updater, err = NewUpdater(client, config)
+	require.NoError(t, err)
+
+	results, err = updater.Update(t.Context())
+	require.NoError(t, err)
+
+	assert.Equal(t, "unchanged", results[0].Action)
+	assert.Equal(t, "unchanged", results[1].Action)
+	assert.Len(t, api.Requests(), requests)
+
+	source.set("192.0.2.2", "2001:db8::1")
+
+	results, err = updater.Update(t.Context())
+	require.NoError(t, err)
+
+	assert.Equal(t, "A site1.example.com 192.0.2.2 updated", results[0].String())
+	assert.Equal(t, "AAAA site1.example.com 2001:db8::1 unchanged", results[1].String())
+
+	assert.Equal(t, "192.0.2.2", api.Records(zoneID)[2].Content)
+}
+
+func TestUpdater_Update_debounce(t *testing.T) {
+	client, api, _ := setupTest(t)
+
+	source := &staticSource{}
+	source.set("192.0.2.1")
+
+	config := Config{
+		Zone:      "example.com",
+		Name:      "@",
+		IPv4:      true,
+		Sources:   []Source{source},
+		StateFile: filepath.Join(t.TempDir(), "state.json"),
+		Debounce:  time.Minute,
+	}
+
+	now := time.Date(2024, time.January, 2, 3, 4, 5, 0, time.UTC)
+
+	newUpdater := func() *Updater {
+		updater, err := NewUpdater(client, config)
+		require.NoError(t, err)
+
+		updater.now = func() time.Time { return now }
+
+		return updater
+	}
+
+	results, err := newUpdater().Update(t.Context())
+	require.NoError(t, err)
+
+	assert.Equal(t, "A example.com 192.0.2.1 pending", results[0].String())
+
+	// the address changes before the end of the debounce.
+	now = now.Add(30 * time.Second)
+	source.set("192.0.2.2")
+
+	results, err = newUpdater().Update(t.Context())
+	require.NoError(t, err)
+
+	assert.Equal(t, ActionPending, results[0].Action)
+
+	now = now.Add(59 * time.Second)
+
+	results, err = newUpdater().Update(t.Context())
+	require.NoError(t, err)
+
+	assert.Equal(t, ActionPending, results[0].Action)
+	assert.Empty(t, api.Mutations())
+
+	now = now.Add(time.Second)
+
+	results, err = newUpdater().Update(t.Context())
+	require.NoError(t, err)
+
+	assert.Equal(t, "A example.com 192.0.2.2 created", results[0].String())
+	assert.Len(t, api.Mutations(), 1)
+}
+
+func TestUpdater_Update_errors(t *testing.T) {
+	client, api, _ := setupTest(t)
+
+	failing := &staticSource{err: errors.New("boom")}
+
+	source := &staticSource{}
+	source.set("2001:db8::1", "192.0.2.1")
+
+	updater, err := NewUpdater(client, Config{Zone: "example.com", Name: "site1", IPv4: true, IPv6: true, Sources: []Source{failing, source}})
+	require.NoError(t, err)
+
+	// the first source with an address of the family is used.
+	results, err := updater.Update(t.Context())
+	require.NoError(t, err)
+
+	assert.Equal(t, netip.MustParseAddr("192.0.2.1"), results[0].Address)
+	assert.Equal(t, netip.MustParseAddr("2001:db8::1"), results[1].Address)
+
+	source.set("192.0.2.2")
+
+	results, err = updater.Update(t.Context())
+	require.EqualError(t, err, "AAAA site1.example.com: no address found for AAAA\nboom")
+
+	assert.Equal(t, "updated", results[0].Action)
+	assert.Equal(t, "AAAA site1.example.com failed: no address found for AAAA\nboom", results[1].String())
+
+	updater, err = NewUpdater(client, Config{Zone: "example.org", IPv4: true, Sources: []Source{source}})
+	require.NoError(t, err)
+
+	_, err = updater.Update(t.Context())
+	require.EqualError(t, err, "A example.org: zone example.org not found")
+
+	assert.Len(t, api.Mutations(), 3)
+}
+
+func TestNewUpdater_invalid(t *testing.T) {
+	client, _, _ := setupTest(t)
+
+	testCases := []struct {
+		desc     string
+		config   Config
+		expected string
+	}{
+		{desc: "no zone", config: Config{IPv4: true, Sources: DefaultSources()}, expected: "the zone is required"},
+		{desc: "no family", config: Config{Zone: "example.com", Sources: DefaultSources()}, expected: "at least one of IPv4 and IPv6 is required"},
+		{desc: "no source", config: Config{Zone: "example.com", IPv4: true}, expected: "at least one source is required"},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			_, err := NewUpdater(client, test.config)
+			require.EqualError(t, err, test.expected)
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package ddns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Default echo endpoints, reachable only over IPv4 and only over IPv6.
+const (
+	DefaultIPv4URL = "https://api4.ipify.org"
+	DefaultIPv6URL = "https://api6.ipify.org"
+)
+
+// Source Detects the current addresses of the host.
+type Source interface {
+	// Addresses Returns the detected addresses.
+	// The addresses of the other family are ignored by the updater.
+	Addresses(ctx context.Context) ([]netip.Addr, error)
+}
+
+// HTTPSource An HTTP echo endpoint, returning the address of the client in the body (e.g. https://api4.ipify.org).
+type HTTPSource struct {
+	// URL of the endpoint.
+	URL string
+
+	// Network forces the family of the connection: "tcp4", "tcp6" or "tcp" (by default).
+	Network string
+
+	// Client the HTTP client (a client with a 10 seconds timeout by default).
+	// Network is ignored with a custom client.
+	Client *http.Client
+
+	defaultClientOnce sync.Once
+	defaultClient     *http.Client
+}
+
+// DefaultSources Returns the HTTP sources of DefaultIPv4URL and DefaultIPv6URL.
+func DefaultSources() []Source {
+	return []Source{
+		&HTTPSource{URL: DefaultIPv4URL, Network: "tcp4"},
+		&HTTPSource{URL: DefaultIPv6URL, Network: "tcp6"},
+	}
+}
+
+// Addresses Returns the address in the body of the response.
+func (s *HTTPSource) Addresses(ctx context.Context) ([]netip.Addr, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: status code: %d", s.URL, resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read body: %w", err)
+	}
+
+	addr, err := netip.ParseAddr(strings.TrimSpace(string(body)))
+	if err != nil {
+		return nil, fmt.Errorf("%s: invalid address: %w", s.URL, err)
+	}
+
+	return []netip.Addr{addr.Unmap()}, nil
+}
+
+func (s *HTTPSource) client() *http.Client {
+	if s.Client != nil {
+		return s.Client
+	}
+
+	// the default client is built once, to reuse its connections.
+	s.defaultClientOnce.Do(func() {
+		s.defaultClient = newDefaultClient(s.Network)
+	})
+
+	return s.defaultClient
+}
+
+func newDefaultClient(network string) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if network != "" {
+		dialer := &net.Dialer{Timeout: 10 * time.Second}
+
+		transport.DialContext = func(ctx context.Context, _, addr string) (net.Conn, error) {
+			return dialer.DialContext(ctx, network, addr)
+		}
+	}
+
+	return &http.Client{Timeout: 10 * time.Second, Transport: transport}
+}
+
+// InterfaceSource The global unicast addresses of the network interfaces.
+type InterfaceSource struct {
+	// Name of the interface (all the interfaces by default).
+	Name string
+
+	// IncludePrivate includes the private addresses (RFC 1918, RFC 4193).
+	IncludePrivate bool
+}
+
+// Addresses Returns the global unicast addresses of the interfaces.
+func (s *InterfaceSource) Addresses(_ context.Context) ([]netip.Addr, error) {
+	var interfaces []net.Interface
+
+	if s.Name != "" {
+		iface, err := net.InterfaceByName(s.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		interfaces = append(interfaces, *iface)
+	} else {
+		var err error
+
+		interfaces, err = net.Interfaces()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var addresses []netip.Addr
+
+	for _, iface := range interfaces {
+		addrs, err := iface.Addrs()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, addr := range addrs {
+			ipNet, ok := addr.(*net.IPNet)
+			if !ok {
+				continue
+			}
+
+			ip, ok := netip.AddrFromSlice(ipNet.IP)
+			if !ok {
+				continue
+			}
+
+			ip = ip.Unmap()
+
+			if ip.IsGlobalUnicast() && (s.IncludePrivate || !ip.IsPrivate()) {
+				addresses = append(addresses, ip)
+			}
+		}
+	}
+
+	if len(addresses) == 0 {
+		return nil, errors.New("no global unicast address")
+	}
+
+	return addresses, nil
+}
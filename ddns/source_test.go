@@ -0,0 +1,65 @@
+package ddns
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/error" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		if r.URL.Path == "/invalid" {
+			_, _ = w.Write([]byte("<html>"))
+			return
+		}
+
+		_, _ = w.Write([]byte("192.0.2.1\n"))
+	}))
+	t.Cleanup(server.Close)
+
+	source := &HTTPSource{URL: server.URL, Network: "tcp4"}
+
+	addresses, err := source.Addresses(t.Context())
+	require.NoError(t, err)
+
+	assert.Equal(t, []netip.Addr{netip.MustParseAddr("192.0.2.1")}, addresses)
+
+	assert.Same(t, source.client(), source.client())
+
+	_, err = (&HTTPSource{URL: server.URL + "/error"}).Addresses(t.Context())
+	require.EqualError(t, err, server.URL+"/error: status code: 503")
+
+	_, err = (&HTTPSource{URL: server.URL + "/invalid"}).Addresses(t.Context())
+	require.ErrorContains(t, err, server.URL+"/invalid: invalid address")
+
+	// the test server only listens over IPv4.
+	_, err = (&HTTPSource{URL: server.URL, Network: "tcp6"}).Addresses(t.Context())
+	require.Error(t, err)
+}
+
+func TestInterfaceSource(t *testing.T) {
+	interfaces, err := net.Interfaces()
+	require.NoError(t, err)
+
+	for _, iface := range interfaces {
+		if iface.Flags&net.FlagLoopback == 0 {
+			continue
+		}
+
+		_, err = (&InterfaceSource{Name: iface.Name}).Addresses(t.Context())
+		require.EqualError(t, err, "no global unicast address")
+	}
+
+	_, err = (&InterfaceSource{Name: "missing0"}).Addresses(t.Context())
+	require.Error(t, err)
+}
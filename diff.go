@@ -0,0 +1,287 @@
+package auroradns
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Diff operations, used in the text rendering.
+const (
+	diffAdded   = "+"
+	diffRemoved = "-"
+	diffChanged = "~"
+)
+
+// RecordDiff The changes needed to go from the live records to the desired records.
+type RecordDiff struct {
+	Added   []Record       `json:"added,omitempty"`
+	Removed []Record       `json:"removed,omitempty"`
+	Changed []RecordChange `json:"changed,omitempty"`
+}
+
+// RecordChange A record whose fields change.
+type RecordChange struct {
+	// Old the live record (with its ID).
+	Old Record `json:"old"`
+
+	// New the desired record.
+	New Record `json:"new"`
+
+	// Fields the changed fields.
+	Fields []FieldChange `json:"fields"`
+}
+
+// FieldChange The old and new values of a field.
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// RecordsEqual Returns true if the records are semantically equal:
+// same name, type and content (compared with NormalizeRecord), same TTL and same health check.
+// The ID is ignored.
+func RecordsEqual(a, b Record) bool {
+	return len(compareRecords(NormalizeRecord(a, ""), NormalizeRecord(b, ""), true)) == 0
+}
+
+// DiffRecords Computes the changes needed to go from the live records to the desired records.
+// The names are relative to the zone (see DiffZoneRecords).
+//
+// The records are keyed by name and type, and compared on their normalized content, their TTL and their health check.
+// The server-only fields (ID) are ignored.
+// A desired record without TTL (0) or without health check accepts any live value.
+func DiffRecords(live, desired []Record) *RecordDiff {
+	return DiffZoneRecords("", live, desired)
+}
+
+// DiffZoneRecords Computes the changes needed to go from the live records to the desired records of a zone.
+// The names can be relative to the zone or absolute (see NormalizeName).
+func DiffZoneRecords(zone string, live, desired []Record) *RecordDiff {
+	liveSets := groupRecords(zone, live)
+	desiredSets := groupRecords(zone, desired)
+
+	keys := slices.Collect(maps.Keys(liveSets))
+	for key := range desiredSets {
+		if _, ok := liveSets[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+
+	slices.SortFunc(keys, func(a, b recordKey) int {
+		return cmp.Or(cmp.Compare(a.name, b.name), cmp.Compare(a.recordType, b.recordType))
+	})
+
+	diff := &RecordDiff{}
+
+	for _, key := range keys {
+		diff.diffSet(liveSets[key], desiredSets[key])
+	}
+
+	return diff
+}
+
+// IsEmpty Returns true if there are no changes.
+func (d *RecordDiff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// String Returns the text rendering of the diff (see WriteText).
+func (d *RecordDiff) String() string {
+	buf := new(bytes.Buffer)
+
+	_ = d.WriteText(buf)
+
+	return buf.String()
+}
+
+// WriteText Writes one line per change, prefixed by "-" for a removed record,
+// "~" for a changed record (e.g. `~ A api content: 192.0.2.1 -> 192.0.2.2, ttl: 300 -> 600`),
+// and "+" for an added record (e.g. `+ A www 192.0.2.1 ttl=300`).
+// The apex is written as "@".
+func (d *RecordDiff) WriteText(w io.Writer) error {
+	for _, line := range d.lines() {
+		_, err := fmt.Fprintln(w, line)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WriteJSON Writes the diff in JSON.
+func (d *RecordDiff) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(d)
+}
+
+func (d *RecordDiff) lines() []string {
+	var lines []string
+
+	for _, record := range d.Removed {
+		lines = append(lines, diffRemoved+" "+formatRecord(record))
+	}
+
+	for _, change := range d.Changed {
+		var fields []string
+
+		for _, field := range change.Fields {
+			fields = append(fields, fmt.Sprintf("%s: %s -> %s", field.Field, field.Old, field.New))
+		}
+
+		lines = append(lines, fmt.Sprintf("%s %s %s %s", diffChanged, change.New.RecordType, displayName(change.New.Name), strings.Join(fields, ", ")))
+	}
+
+	for _, record := range d.Added {
+		lines = append(lines, diffAdded+" "+formatRecord(record))
+	}
+
+	return lines
+}
+
+// diffSet compares the live and desired records with the same name and type.
+func (d *RecordDiff) diffSet(live, desired []normalizedRecord) {
+	// identical records.
+	live = slices.DeleteFunc(live, func(l normalizedRecord) bool {
+		i := slices.IndexFunc(desired, func(r normalizedRecord) bool {
+			return len(compareRecords(l.normalized, r.normalized, false)) == 0
+		})
+		if i < 0 {
+			return false
+		}
+
+		desired = slices.Delete(desired, i, i+1)
+
+		return true
+	})
+
+	// same content, other fields changed.
+	live = slices.DeleteFunc(live, func(l normalizedRecord) bool {
+		i := slices.IndexFunc(desired, func(r normalizedRecord) bool {
+			return l.normalized.Content == r.normalized.Content
+		})
+		if i < 0 {
+			return false
+		}
+
+		d.addChange(l, desired[i])
+		desired = slices.Delete(desired, i, i+1)
+
+		return true
+	})
+
+	// content changed.
+	for len(live) > 0 && len(desired) > 0 {
+		d.addChange(live[0], desired[0])
+
+		live = live[1:]
+		desired = desired[1:]
+	}
+
+	for _, l := range live {
+		d.Removed = append(d.Removed, l.original)
+	}
+
+	for _, r := range desired {
+		d.Added = append(d.Added, r.original)
+	}
+}
+
+func (d *RecordDiff) addChange(live, desired normalizedRecord) {
+	d.Changed = append(d.Changed, RecordChange{
+		Old:    live.original,
+		New:    desired.original,
+		Fields: compareRecords(live.normalized, desired.normalized, false),
+	})
+}
+
+type recordKey struct {
+	name       string
+	recordType string
+}
+
+type normalizedRecord struct {
+	original   Record
+	normalized Record
+}
+
+// groupRecords groups the records by name and type, sorted by normalized content.
+func groupRecords(zone string, records []Record) map[recordKey][]normalizedRecord {
+	sets := make(map[recordKey][]normalizedRecord)
+
+	for _, record := range records {
+		normalized := NormalizeRecord(record, zone)
+		key := recordKey{name: normalized.Name, recordType: normalized.RecordType}
+
+		sets[key] = append(sets[key], normalizedRecord{original: record, normalized: normalized})
+	}
+
+	for _, set := range sets {
+		slices.SortStableFunc(set, func(a, b normalizedRecord) int {
+			return cmp.Compare(a.normalized.Content, b.normalized.Content)
+		})
+	}
+
+	return sets
+}
+
+// compareRecords returns the fields of desired that differ from live.
+// If strict is false, a zero TTL or an empty health check in desired matches any live value.
+func compareRecords(live, desired Record, strict bool) []FieldChange {
+	var changes []FieldChange
+
+	if live.Name != desired.Name {
+		changes = append(changes, FieldChange{Field: "name", Old: live.Name, New: desired.Name})
+	}
+
+	if live.RecordType != desired.RecordType {
+		changes = append(changes, FieldChange{Field: "type", Old: live.RecordType, New: desired.RecordType})
+	}
+
+	if live.Content != desired.Content {
+		changes = append(changes, FieldChange{Field: "content", Old: live.Content, New: desired.Content})
+	}
+
+	if live.TTL != desired.TTL && (strict || desired.TTL != 0) {
+		changes = append(changes, FieldChange{Field: "ttl", Old: strconv.Itoa(live.TTL), New: strconv.Itoa(desired.TTL)})
+	}
+
+	if live.HealthCheckID != desired.HealthCheckID && (strict || desired.HealthCheckID != "") {
+		changes = append(changes, FieldChange{Field: "health_check_id", Old: live.HealthCheckID, New: desired.HealthCheckID})
+	}
+
+	return changes
+}
+
+func formatRecord(record Record) string {
+	content := record.Content
+	if record.RecordType == RecordTypeTXT {
+		content = strconv.Quote(content)
+	}
+
+	line := fmt.Sprintf("%s %s %s", record.RecordType, displayName(record.Name), content)
+
+	if record.TTL > 0 {
+		line += " ttl=" + strconv.Itoa(record.TTL)
+	}
+
+	return line
+}
+
+func displayName(name string) string {
+	if name == "" {
+		return "@"
+	}
+
+	return name
+}
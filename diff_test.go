@@ -0,0 +1,163 @@
+package auroradns
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordsEqual(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		a, b     Record
+		expected bool
+	}{
+		{
+			desc:     "same",
+			a:        Record{ID: "aaa", RecordType: RecordTypeA, Name: "www", Content: "192.0.2.1", TTL: 300},
+			b:        Record{RecordType: RecordTypeA, Name: "www", Content: "192.0.2.1", TTL: 300},
+			expected: true,
+		},
+		{
+			desc:     "normalized",
+			a:        Record{RecordType: RecordTypeAAAA, Name: "@", Content: "2001:db8::1", TTL: 300},
+			b:        Record{RecordType: "aaaa", Name: "", Content: "2001:0db8:0:0:0:0:0:1", TTL: 300},
+			expected: true,
+		},
+		{
+			desc: "other TTL",
+			a:    Record{RecordType: RecordTypeA, Name: "www", Content: "192.0.2.1", TTL: 300},
+			b:    Record{RecordType: RecordTypeA, Name: "www", Content: "192.0.2.1"},
+		},
+		{
+			desc: "other content",
+			a:    Record{RecordType: RecordTypeA, Name: "www", Content: "192.0.2.1"},
+			b:    Record{RecordType: RecordTypeA, Name: "www", Content: "192.0.2.2"},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			assert.Equal(t, test.expected, RecordsEqual(test.a, test.b))
+		})
+	}
+}
+
+func TestDiffRecords(t *testing.T) {
+	live := []Record{
+		{ID: "1", RecordType: RecordTypeA, Name: "www", Content: "192.0.2.1", TTL: 300},
+		{ID: "2", RecordType: RecordTypeA, Name: "api", Content: "192.0.2.1", TTL: 300},
+		{ID: "3", RecordType: RecordTypeTXT, Name: "", Content: "v=spf1 -all", TTL: 300},
+		{ID: "4", RecordType: RecordTypeMX, Name: "", Content: "mail.example.com", TTL: 300},
+		{ID: "5", RecordType: RecordTypeAAAA, Name: "www", Content: "2001:db8::1", TTL: 300},
+		{ID: "6", RecordType: RecordTypeTXT, Name: "old", Content: "foo", TTL: 300},
+	}
+
+	desired := []Record{
+		{RecordType: RecordTypeA, Name: "WWW", Content: "192.0.2.1", TTL: 300},
+		{RecordType: RecordTypeA, Name: "api", Content: "192.0.2.2", TTL: 600},
+		{RecordType: RecordTypeTXT, Name: "@", Content: `"v=spf1 -all"`, TTL: 3600},
+		{RecordType: RecordTypeMX, Name: "", Content: "mail.example.com."},
+		{RecordType: RecordTypeAAAA, Name: "www", Content: "2001:0db8::0001", TTL: 300},
+		{RecordType: RecordTypeCNAME, Name: "blog", Content: "example.github.io", TTL: 300},
+	}
+
+	diff := DiffRecords(live, desired)
+
+	expected := &RecordDiff{
+		Added: []Record{
+			{RecordType: RecordTypeCNAME, Name: "blog", Content: "example.github.io", TTL: 300},
+		},
+		Removed: []Record{
+			{ID: "6", RecordType: RecordTypeTXT, Name: "old", Content: "foo", TTL: 300},
+		},
+		Changed: []RecordChange{
+			{
+				Old: Record{ID: "3", RecordType: RecordTypeTXT, Name: "", Content: "v=spf1 -all", TTL: 300},
+				New: Record{RecordType: RecordTypeTXT, Name: "@", Content: `"v=spf1 -all"`, TTL: 3600},
+				Fields: []FieldChange{
+					{Field: "ttl", Old: "300", New: "3600"},
+				},
+			},
+			{
+				Old: Record{ID: "2", RecordType: RecordTypeA, Name: "api", Content: "192.0.2.1", TTL: 300},
+				New: Record{RecordType: RecordTypeA, Name: "api", Content: "192.0.2.2", TTL: 600},
+				Fields: []FieldChange{
+					{Field: "content", Old: "192.0.2.1", New: "192.0.2.2"},
+					{Field: "ttl", Old: "300", New: "600"},
+				},
+			},
+		},
+	}
+	assert.Equal(t, expected, diff)
+	assert.False(t, diff.IsEmpty())
+
+	expectedText := `- TXT old "foo" ttl=300
+~ TXT @ ttl: 300 -> 3600
+~ A api content: 192.0.2.1 -> 192.0.2.2, ttl: 300 -> 600
++ CNAME blog example.github.io ttl=300
+`
+	assert.Equal(t, expectedText, diff.String())
+
+	buf := new(bytes.Buffer)
+
+	err := diff.WriteJSON(buf)
+	require.NoError(t, err)
+
+	expectedJSON := `{
+  "added": [{"type": "CNAME", "name": "blog", "content": "example.github.io", "ttl": 300}],
+  "removed": [{"id": "6", "type": "TXT", "name": "old", "content": "foo", "ttl": 300}],
+  "changed": [
+    {
+      "old": {"id": "3", "type": "TXT", "name": "", "content": "v=spf1 -all", "ttl": 300},
+      "new": {"type": "TXT", "name": "@", "content": "\"v=spf1 -all\"", "ttl": 3600},
+      "fields": [{"field": "ttl", "old": "300", "new": "3600"}]
+    },
+    {
+      "old": {"id": "2", "type": "A", "name": "api", "content": "192.0.2.1", "ttl": 300},
+      "new": {"type": "A", "name": "api", "content": "192.0.2.2", "ttl": 600},
+      "fields": [{"field": "content", "old": "192.0.2.1", "new": "192.0.2.2"}, {"field": "ttl", "old": "300", "new": "600"}]
+    }
+  ]
+}`
+	assert.JSONEq(t, expectedJSON, buf.String())
+}
+
+func TestDiffRecords_rrset(t *testing.T) {
+	live := []Record{
+		{ID: "1", RecordType: RecordTypeA, Name: "www", Content: "192.0.2.1", TTL: 300},
+		{ID: "2", RecordType: RecordTypeA, Name: "www", Content: "192.0.2.2", TTL: 300},
+		{ID: "3", RecordType: RecordTypeA, Name: "www", Content: "192.0.2.3", TTL: 300},
+	}
+
+	desired := []Record{
+		{RecordType: RecordTypeA, Name: "www", Content: "192.0.2.3", TTL: 300},
+		{RecordType: RecordTypeA, Name: "www", Content: "192.0.2.1", TTL: 300},
+	}
+
+	diff := DiffRecords(live, desired)
+
+	expected := &RecordDiff{
+		Removed: []Record{{ID: "2", RecordType: RecordTypeA, Name: "www", Content: "192.0.2.2", TTL: 300}},
+	}
+	assert.Equal(t, expected, diff)
+}
+
+func TestDiffZoneRecords(t *testing.T) {
+	live := []Record{
+		{ID: "1", RecordType: RecordTypeA, Name: "www", Content: "192.0.2.1", TTL: 300},
+		{ID: "2", RecordType: RecordTypeA, Name: "", Content: "192.0.2.1", TTL: 300},
+	}
+
+	desired := []Record{
+		{RecordType: RecordTypeA, Name: "www.example.com.", Content: "192.0.2.1", TTL: 300},
+		{RecordType: RecordTypeA, Name: "example.com.", Content: "192.0.2.1", TTL: 300},
+	}
+
+	diff := DiffZoneRecords("example.com", live, desired)
+
+	assert.True(t, diff.IsEmpty())
+	assert.Empty(t, diff.String())
+}
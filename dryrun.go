@@ -0,0 +1,201 @@
+package auroradns
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+)
+
+// DryRunHeader the header set on the synthetic responses of the dry-run mode.
+const DryRunHeader = "X-AuroraDNS-Dry-Run"
+
+// PlannedCall A mutation not sent in dry-run mode.
+type PlannedCall struct {
+	Operation string          `json:"operation"`
+	Method    string          `json:"method"`
+	Path      string          `json:"path"`
+	ZoneID    string          `json:"zone_id,omitempty"`
+	RecordID  string          `json:"record_id,omitempty"`
+	Body      json.RawMessage `json:"body,omitempty"`
+}
+
+// DryRun Records the mutations not sent in dry-run mode.
+type DryRun struct {
+	// Logger logs the intended calls (slog.Default if nil).
+	Logger *slog.Logger
+
+	mu    sync.Mutex
+	calls []PlannedCall
+}
+
+// Calls Returns the recorded mutations, in order.
+func (d *DryRun) Calls() []PlannedCall {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return slices.Clone(d.calls)
+}
+
+// Reset Removes the recorded mutations.
+func (d *DryRun) Reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.calls = nil
+}
+
+func (d *DryRun) record(ctx context.Context, call PlannedCall) {
+	d.mu.Lock()
+	d.calls = append(d.calls, call)
+	d.mu.Unlock()
+
+	logger := d.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	attrs := []slog.Attr{
+		slog.String("operation", call.Operation),
+		slog.String("method", call.Method),
+		slog.String("path", call.Path),
+	}
+
+	if len(call.Body) > 0 {
+		attrs = append(attrs, slog.String("body", redactBody(call.Body)))
+	}
+
+	logger.LogAttrs(ctx, slog.LevelInfo, "auroradns dry-run: call not sent", attrs...)
+}
+
+// WithDryRun Prevents the mutations (all the calls except GET requests) from being sent.
+//
+// The mutations are logged and recorded in dryRun (a nil dryRun only logs them),
+// and the client returns plausible synthetic results:
+// a created resource is the requested resource with a generated ID, a deletion always succeeds.
+// The synthetic responses have the header X-AuroraDNS-Dry-Run.
+// The reads are sent to the API.
+//
+// The dry-run mode is a middleware (see WithMiddleware):
+// the middlewares registered after it do not receive the mutations.
+func WithDryRun(dryRun *DryRun) Option {
+	if dryRun == nil {
+		dryRun = &DryRun{}
+	}
+
+	return WithMiddleware(func(next Handler) Handler {
+		return func(call *Call) (*http.Response, error) {
+			if call.Request.Method == http.MethodGet || call.Request.Method == http.MethodHead {
+				return next(call)
+			}
+
+			body, err := readRequestBody(call.Request)
+			if err != nil {
+				return nil, err
+			}
+
+			dryRun.record(call.Request.Context(), PlannedCall{
+				Operation: call.Operation,
+				Method:    call.Request.Method,
+				Path:      call.Request.URL.Path,
+				ZoneID:    call.ZoneID,
+				RecordID:  call.RecordID,
+				Body:      body,
+			})
+
+			return syntheticResponse(call, body)
+		}
+	})
+}
+
+// syntheticResponse builds the response of a mutation that has not been sent.
+func syntheticResponse(call *Call, body []byte) (*http.Response, error) {
+	statusCode := http.StatusOK
+
+	switch call.Request.Method {
+	case http.MethodPost:
+		statusCode = http.StatusCreated
+
+		id, err := newID()
+		if err != nil {
+			return nil, err
+		}
+
+		body, err = withID(body, id)
+		if err != nil {
+			return nil, err
+		}
+
+	case http.MethodPut, http.MethodPatch:
+		var err error
+
+		body, err = withID(body, call.RecordID)
+		if err != nil {
+			return nil, err
+		}
+
+	case http.MethodDelete:
+		statusCode = http.StatusNoContent
+		body = nil
+	}
+
+	header := make(http.Header)
+	header.Set(DryRunHeader, "true")
+
+	if len(body) > 0 {
+		header.Set(contentTypeHeader, contentTypeJSON)
+	}
+
+	return &http.Response{
+		Status:        strconv.Itoa(statusCode) + " " + http.StatusText(statusCode),
+		StatusCode:    statusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       call.Request,
+	}, nil
+}
+
+// withID sets the "id" field of a JSON object.
+func withID(body []byte, id string) ([]byte, error) {
+	if len(body) == 0 || id == "" {
+		return body, nil
+	}
+
+	var object map[string]any
+
+	err := json.Unmarshal(body, &object)
+	if err != nil {
+		// not an object: returned as is.
+		return body, nil //nolint:nilerr // the body is not modified.
+	}
+
+	object["id"] = id
+
+	return json.Marshal(object)
+}
+
+// newID generates a random UUID (version 4).
+func newID() (string, error) {
+	var b [16]byte
+
+	_, err := rand.Read(b[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to generate ID: %w", err)
+	}
+
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
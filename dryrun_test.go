@@ -0,0 +1,80 @@
+package auroradns
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithDryRun(t *testing.T) {
+	logs := new(bytes.Buffer)
+
+	dryRun := &DryRun{Logger: slog.New(slog.NewTextHandler(logs, nil))}
+
+	client, mux := setupTest(t, WithDryRun(dryRun))
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "mutation sent in dry-run mode", http.StatusInternalServerError)
+			return
+		}
+
+		_, _ = w.Write([]byte(`[{"id":"identifier-zone-1","name":"example.com"}]`))
+	})
+
+	zones, _, err := client.ListZonesWithContext(t.Context())
+	require.NoError(t, err)
+
+	assert.Equal(t, []Zone{{ID: "identifier-zone-1", Name: "example.com"}}, zones)
+
+	zone, resp, err := client.CreateZoneWithContext(t.Context(), "example.org")
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, "true", resp.Header.Get(DryRunHeader))
+	assert.Equal(t, "example.org", zone.Name)
+	assert.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, zone.ID)
+
+	record := Record{RecordType: RecordTypeTXT, Name: "foo", Content: "bar", TTL: 300}
+
+	newRecord, _, err := client.CreateRecordWithContext(t.Context(), zone.ID, record)
+	require.NoError(t, err)
+
+	assert.NotEmpty(t, newRecord.ID)
+	assert.NotEqual(t, zone.ID, newRecord.ID)
+
+	record.ID = newRecord.ID
+	assert.Equal(t, &record, newRecord)
+
+	deleted, resp, err := client.DeleteRecordWithContext(t.Context(), zone.ID, newRecord.ID)
+	require.NoError(t, err)
+
+	assert.True(t, deleted)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	deleted, _, err = client.DeleteZoneWithContext(t.Context(), "identifier-zone-1")
+	require.NoError(t, err)
+
+	assert.True(t, deleted)
+
+	calls := dryRun.Calls()
+	require.Len(t, calls, 4)
+
+	expected := []PlannedCall{
+		{Operation: OperationCreateZone, Method: http.MethodPost, Path: "/zones", Body: json.RawMessage(`{"name":"example.org"}`)},
+		{Operation: OperationCreateRecord, Method: http.MethodPost, Path: "/zones/" + zone.ID + "/records", ZoneID: zone.ID, Body: json.RawMessage(`{"type":"TXT","name":"foo","content":"bar","ttl":300}`)},
+		{Operation: OperationDeleteRecord, Method: http.MethodDelete, Path: "/zones/" + zone.ID + "/records/" + newRecord.ID, ZoneID: zone.ID, RecordID: newRecord.ID},
+		{Operation: OperationDeleteZone, Method: http.MethodDelete, Path: "/zones/identifier-zone-1", ZoneID: "identifier-zone-1"},
+	}
+	assert.Equal(t, expected, calls)
+
+	assert.Contains(t, logs.String(), `msg="auroradns dry-run: call not sent" operation=CreateZone method=POST path=/zones body="{\"name\":\"example.org\"}"`)
+
+	dryRun.Reset()
+	assert.Empty(t, dryRun.Calls())
+}
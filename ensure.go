@@ -0,0 +1,83 @@
+package auroradns
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// EnsureAction The action taken by EnsureRecord.
+type EnsureAction string
+
+// Actions taken by EnsureRecord.
+const (
+	EnsureUnchanged EnsureAction = "unchanged"
+	EnsureCreated   EnsureAction = "created"
+	EnsureUpdated   EnsureAction = "updated"
+)
+
+// EnsureRecord Makes the record the only record of its name and type in the zone (upsert).
+// The name of the record is relative to the zone.
+//
+// The record is created if the zone has no record with this name and type.
+// Otherwise, the first one is updated if its content, its TTL or its health check differs (compared like DiffRecords),
+// and the others are deleted.
+// A record without TTL or without health check keeps the live values.
+func (c *Client) EnsureRecord(ctx context.Context, zoneID string, record Record) (*Record, EnsureAction, error) {
+	var existing []Record
+
+	for live, err := range c.AllRecords(ctx, zoneID) {
+		if err != nil {
+			return nil, "", err
+		}
+
+		if NormalizeName(live.Name, "") == NormalizeName(record.Name, "") && strings.EqualFold(live.RecordType, record.RecordType) {
+			existing = append(existing, live)
+		}
+	}
+
+	if len(existing) == 0 {
+		record.ID = ""
+
+		created, _, err := c.CreateRecordWithContext(ctx, zoneID, record)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to create the record %s %q: %w", record.RecordType, record.Name, err)
+		}
+
+		return created, EnsureCreated, nil
+	}
+
+	for _, extra := range existing[1:] {
+		_, _, err := c.DeleteRecordWithContext(ctx, zoneID, extra.ID)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to delete the record %s %q: %w", extra.RecordType, extra.Name, err)
+		}
+	}
+
+	live := existing[0]
+
+	if len(compareRecords(NormalizeRecord(live, ""), NormalizeRecord(record, ""), false)) == 0 {
+		action := EnsureUnchanged
+		if len(existing) > 1 {
+			action = EnsureUpdated
+		}
+
+		return &live, action, nil
+	}
+
+	// the unspecified fields keep their live values.
+	if record.TTL == 0 {
+		record.TTL = live.TTL
+	}
+
+	if record.HealthCheckID == "" {
+		record.HealthCheckID = live.HealthCheckID
+	}
+
+	updated, _, err := c.UpdateRecordWithContext(ctx, zoneID, live.ID, record)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to update the record %s %q: %w", record.RecordType, record.Name, err)
+	}
+
+	return updated, EnsureUpdated, nil
+}
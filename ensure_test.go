@@ -0,0 +1,56 @@
+package auroradns
+
+import (
+	"testing"
+
+	"github.com/nrdcg/auroradns/internal/fakeapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_EnsureRecord(t *testing.T) {
+	client, server := setupFakeAPI(t)
+
+	zoneID := server.AddZone("example.com")
+
+	record, action, err := client.EnsureRecord(t.Context(), zoneID, Record{RecordType: RecordTypeA, Name: "www", Content: "192.0.2.1", TTL: 300})
+	require.NoError(t, err)
+
+	assert.Equal(t, EnsureCreated, action)
+	assert.Equal(t, "record-4", record.ID)
+
+	_, action, err = client.EnsureRecord(t.Context(), zoneID, Record{RecordType: "a", Name: "WWW", Content: "192.0.2.1"})
+	require.NoError(t, err)
+
+	assert.Equal(t, EnsureUnchanged, action)
+
+	_, action, err = client.EnsureRecord(t.Context(), zoneID, Record{RecordType: RecordTypeA, Name: "www", Content: "192.0.2.2"})
+	require.NoError(t, err)
+
+	assert.Equal(t, EnsureUpdated, action)
+
+	expected := []string{
+		"POST /zones/zone-1/records",
+		"PUT /zones/zone-1/records/record-4",
+	}
+	assert.Equal(t, expected, server.Mutations())
+
+	// the TTL is kept.
+	assert.Equal(t, fakeapi.Record{ID: "record-4", Type: "A", Name: "www", Content: "192.0.2.2", TTL: 300}, server.Records(zoneID)[2])
+}
+
+func TestClient_EnsureRecord_duplicates(t *testing.T) {
+	client, server := setupFakeAPI(t)
+
+	zoneID := server.AddZone("example.com")
+	server.AddRecord(zoneID, fakeapi.Record{Type: "A", Name: "www", Content: "192.0.2.1"})
+	server.AddRecord(zoneID, fakeapi.Record{Type: "A", Name: "www", Content: "192.0.2.2"})
+	server.AddRecord(zoneID, fakeapi.Record{Type: "AAAA", Name: "www", Content: "2001:db8::1"})
+
+	_, action, err := client.EnsureRecord(t.Context(), zoneID, Record{RecordType: RecordTypeA, Name: "www", Content: "192.0.2.1"})
+	require.NoError(t, err)
+
+	assert.Equal(t, EnsureUpdated, action)
+	assert.Equal(t, []string{"DELETE /zones/zone-1/records/record-5"}, server.Mutations())
+	assert.Len(t, server.Records(zoneID), 4)
+}
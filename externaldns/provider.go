@@ -0,0 +1,449 @@
+// Package externaldns An external-dns webhook provider for Aurora DNS.
+//
+// The provider implements the webhook protocol of external-dns (negotiation, records, changes, endpoint adjustments),
+// and runs as a sidecar of external-dns (--provider=webhook):
+//
+//	provider, err := externaldns.NewProvider(client, externaldns.Config{
+//		DomainFilter: externaldns.DomainFilter{Include: []string{"example.com"}},
+//		OwnerID:      "cluster1",
+//	})
+//
+//	err = provider.ListenAndServe(ctx, "127.0.0.1:8888")
+//
+// The listing is ownership-aware: the records are labeled with the owner of their external-dns TXT registry record
+// (default formats: "www" and "a-www", without prefix or suffix).
+// With an OwnerID, the changes of records owned by another owner are refused (ErrNotOwned).
+//
+// The changes are applied in batch: one plan per zone (see auroradns.NewPlan),
+// which only touches the records that actually change.
+package externaldns
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/nrdcg/auroradns"
+)
+
+// DefaultTTL the TTL of the records created from the endpoints without TTL.
+const DefaultTTL = 300
+
+// OwnerLabel the label of the endpoints holding the owner of the record.
+const OwnerLabel = "owner"
+
+// ErrNotOwned The error returned when a change targets a record owned by another owner.
+var ErrNotOwned = errors.New("record owned by another owner")
+
+// supportedTypes the record types managed by external-dns.
+var supportedTypes = []string{
+	auroradns.RecordTypeA,
+	auroradns.RecordTypeAAAA,
+	auroradns.RecordTypeCNAME,
+	auroradns.RecordTypeMX,
+	auroradns.RecordTypeNS,
+	auroradns.RecordTypePTR,
+	auroradns.RecordTypeSRV,
+	auroradns.RecordTypeTXT,
+}
+
+// Config the configuration of a Provider.
+type Config struct {
+	// DomainFilter the domains managed by the provider (all the zones by default).
+	DomainFilter DomainFilter
+
+	// OwnerID the owner of the records (the --txt-owner-id of external-dns).
+	// If set, the changes of records owned by another owner are refused.
+	OwnerID string
+
+	// DefaultTTL the TTL of the endpoints without TTL (DefaultTTL by default).
+	DefaultTTL int
+
+	// Logger logs the changes (slog.Default by default).
+	Logger *slog.Logger
+}
+
+// Provider An external-dns webhook provider.
+type Provider struct {
+	client *auroradns.Client
+	config Config
+	logger *slog.Logger
+	mux    *http.ServeMux
+}
+
+// NewProvider Creates a new Provider.
+func NewProvider(client *auroradns.Client, config Config) (*Provider, error) {
+	if client == nil {
+		return nil, errors.New("the client is required")
+	}
+
+	if config.DefaultTTL == 0 {
+		config.DefaultTTL = DefaultTTL
+	}
+
+	if config.DefaultTTL < 0 {
+		return nil, fmt.Errorf("invalid default TTL %d", config.DefaultTTL)
+	}
+
+	config.DomainFilter = config.DomainFilter.normalize()
+
+	logger := config.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	p := &Provider{client: client, config: config, logger: logger}
+	p.mux = p.routes()
+
+	return p, nil
+}
+
+// Records Returns the endpoints of the managed zones: one endpoint per name and type.
+// The apex SOA and NS records, and the record types not supported by external-dns, are not returned.
+func (p *Provider) Records(ctx context.Context) ([]*Endpoint, error) {
+	zones, err := p.zones(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var endpoints []*Endpoint
+
+	for _, zone := range zones {
+		records, _, err := p.client.ListRecordsWithContext(ctx, zone.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the records of the zone %s: %w", zone.Name, err)
+		}
+
+		endpoints = append(endpoints, p.endpoints(zone, records)...)
+	}
+
+	slices.SortFunc(endpoints, func(a, b *Endpoint) int {
+		return cmp.Or(cmp.Compare(a.DNSName, b.DNSName), cmp.Compare(a.RecordType, b.RecordType))
+	})
+
+	return endpoints, nil
+}
+
+// ApplyChanges Applies the changes, zone by zone.
+// The records of each zone are listed once, and only the records that change are created, updated or deleted.
+// The endpoints outside the managed zones are ignored.
+// The ownership of the changes is checked in all the zones before applying any change.
+// It stops at the first error: the changes applied before are not reverted.
+func (p *Provider) ApplyChanges(ctx context.Context, changes *Changes) error {
+	if changes == nil {
+		return errors.New("the changes are required")
+	}
+
+	zones, err := p.zones(ctx)
+	if err != nil {
+		return err
+	}
+
+	batches := make(map[string]*batch)
+
+	add := func(endpoints []*Endpoint, apply func(b *batch, key rrsetKey, endpoint *Endpoint)) {
+		for _, endpoint := range endpoints {
+			zone, name, ok := p.findZone(zones, endpoint.DNSName)
+			if !ok {
+				p.logger.Warn("externaldns: endpoint outside the managed zones ignored",
+					slog.String("name", endpoint.DNSName), slog.String("type", endpoint.RecordType))
+
+				continue
+			}
+
+			b, ok := batches[zone.ID]
+			if !ok {
+				b = &batch{zone: zone}
+				batches[zone.ID] = b
+			}
+
+			apply(b, rrsetKey{name: name, recordType: strings.ToUpper(endpoint.RecordType)}, endpoint)
+		}
+	}
+
+	removal := func(b *batch, key rrsetKey, endpoint *Endpoint) {
+		b.removed = append(b.removed, endpointChange{key: key, endpoint: endpoint})
+	}
+
+	addition := func(b *batch, key rrsetKey, endpoint *Endpoint) {
+		b.added = append(b.added, endpointChange{key: key, endpoint: endpoint})
+	}
+
+	add(changes.Delete, removal)
+	add(changes.UpdateOld, removal)
+	add(changes.UpdateNew, addition)
+	add(changes.Create, addition)
+
+	ids := make([]string, 0, len(batches))
+	for id := range batches {
+		ids = append(ids, id)
+	}
+
+	slices.SortFunc(ids, func(a, b string) int {
+		return cmp.Compare(batches[a].zone.Name, batches[b].zone.Name)
+	})
+
+	for _, id := range ids {
+		err = p.checkBatch(ctx, batches[id])
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, id := range ids {
+		err = p.applyBatch(ctx, batches[id])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// AdjustEndpoints Returns the desired endpoints in the form returned by Records,
+// so that external-dns does not see differences where there are none:
+// the names and the targets are normalized (see auroradns.NormalizeContent), the TTLs are set,
+// and the record types not supported are removed.
+func (p *Provider) AdjustEndpoints(endpoints []*Endpoint) []*Endpoint {
+	adjusted := make([]*Endpoint, 0, len(endpoints))
+
+	for _, endpoint := range endpoints {
+		recordType := strings.ToUpper(endpoint.RecordType)
+
+		if !slices.Contains(supportedTypes, recordType) {
+			p.logger.Warn("externaldns: record type not supported", slog.String("name", endpoint.DNSName), slog.String("type", endpoint.RecordType))
+			continue
+		}
+
+		e := *endpoint
+		e.DNSName = auroradns.NormalizeZoneName(endpoint.DNSName)
+		e.RecordType = recordType
+		e.Targets = normalizeTargets(recordType, endpoint.Targets)
+
+		if e.RecordTTL <= 0 {
+			e.RecordTTL = int64(p.config.DefaultTTL)
+		}
+
+		adjusted = append(adjusted, &e)
+	}
+
+	return adjusted
+}
+
+// rrsetKey identifies an RRset: a name relative to the zone and a type.
+type rrsetKey struct {
+	name       string
+	recordType string
+}
+
+type endpointChange struct {
+	key      rrsetKey
+	endpoint *Endpoint
+}
+
+// batch The changes of a zone.
+type batch struct {
+	zone    auroradns.Zone
+	removed []endpointChange
+	added   []endpointChange
+
+	// live the records of the zone, listed by checkBatch.
+	live []auroradns.Record
+}
+
+// checkBatch Lists the records of the zone, and checks the ownership of the changes.
+func (p *Provider) checkBatch(ctx context.Context, b *batch) error {
+	live, _, err := p.client.ListRecordsWithContext(ctx, b.zone.ID)
+	if err != nil {
+		return fmt.Errorf("failed to list the records of the zone %s: %w", b.zone.Name, err)
+	}
+
+	b.live = live
+
+	if p.config.OwnerID == "" {
+		return nil
+	}
+
+	owners := newOwners(b.zone.Name, live)
+
+	for _, change := range slices.Concat(b.removed, b.added) {
+		owner := owners.of(change.key)
+
+		if owner != "" && owner != p.config.OwnerID {
+			return fmt.Errorf("%s %s: %w %s", change.key.recordType, change.endpoint.DNSName, ErrNotOwned, owner)
+		}
+	}
+
+	return nil
+}
+
+func (p *Provider) applyBatch(ctx context.Context, b *batch) error {
+	live := b.live
+
+	desired := slices.Clone(live)
+
+	for _, change := range b.removed {
+		desired = slices.DeleteFunc(desired, func(record auroradns.Record) bool {
+			return change.matches(b.zone.Name, record)
+		})
+	}
+
+	for _, change := range b.added {
+		// the record replaces the record with the same content.
+		desired = slices.DeleteFunc(desired, func(record auroradns.Record) bool {
+			return change.matches(b.zone.Name, record)
+		})
+
+		for _, target := range change.endpoint.Targets {
+			desired = append(desired, auroradns.Record{
+				RecordType: change.key.recordType,
+				Name:       change.key.name,
+				Content:    target,
+				TTL:        p.ttl(change.endpoint),
+			})
+		}
+	}
+
+	plan := auroradns.NewPlan(b.zone, live, desired)
+	if plan.Diff.IsEmpty() {
+		return nil
+	}
+
+	err := p.client.ApplyPlan(ctx, plan)
+	if err != nil {
+		return fmt.Errorf("zone %s: %w", b.zone.Name, err)
+	}
+
+	p.logger.Info("externaldns: changes applied", slog.String("zone", b.zone.Name),
+		slog.Int("created", len(plan.Diff.Added)), slog.Int("updated", len(plan.Diff.Changed)), slog.Int("deleted", len(plan.Diff.Removed)))
+
+	return nil
+}
+
+// matches Returns true if the record is one of the targets of the endpoint.
+func (c endpointChange) matches(zone string, record auroradns.Record) bool {
+	record = auroradns.NormalizeRecord(record, zone)
+
+	if record.Name != c.key.name || record.RecordType != c.key.recordType {
+		return false
+	}
+
+	return slices.ContainsFunc(c.endpoint.Targets, func(target string) bool {
+		return auroradns.NormalizeContent(c.key.recordType, target) == record.Content
+	})
+}
+
+// endpoints Groups the records of a zone by name and type.
+func (p *Provider) endpoints(zone auroradns.Zone, records []auroradns.Record) []*Endpoint {
+	zoneName := auroradns.NormalizeZoneName(zone.Name)
+	owners := newOwners(zoneName, records)
+
+	byKey := make(map[rrsetKey]*Endpoint)
+
+	var endpoints []*Endpoint
+
+	for _, record := range records {
+		normalized := auroradns.NormalizeRecord(record, zoneName)
+
+		if !slices.Contains(supportedTypes, normalized.RecordType) || (normalized.RecordType == auroradns.RecordTypeNS && normalized.Name == "") {
+			continue
+		}
+
+		fqdn := zoneName
+		if normalized.Name != "" {
+			fqdn = normalized.Name + "." + zoneName
+		}
+
+		if !p.config.DomainFilter.Match(fqdn) {
+			continue
+		}
+
+		key := rrsetKey{name: normalized.Name, recordType: normalized.RecordType}
+
+		endpoint, ok := byKey[key]
+		if !ok {
+			endpoint = &Endpoint{DNSName: fqdn, RecordType: key.recordType, RecordTTL: int64(record.TTL)}
+
+			if owner := owners.of(key); owner != "" {
+				endpoint.Labels = map[string]string{OwnerLabel: owner}
+			}
+
+			byKey[key] = endpoint
+			endpoints = append(endpoints, endpoint)
+		}
+
+		endpoint.Targets = append(endpoint.Targets, record.Content)
+	}
+
+	for _, endpoint := range endpoints {
+		endpoint.Targets = normalizeTargets(endpoint.RecordType, endpoint.Targets)
+	}
+
+	return endpoints
+}
+
+// normalizeTargets Returns the targets normalized like the content of the records, sorted and without duplicates,
+// for the comparisons of external-dns.
+func normalizeTargets(recordType string, targets []string) []string {
+	normalized := make([]string, 0, len(targets))
+
+	for _, target := range targets {
+		normalized = append(normalized, auroradns.NormalizeContent(recordType, target))
+	}
+
+	slices.Sort(normalized)
+
+	return slices.Compact(normalized)
+}
+
+// zones Returns the zones matching the domain filter.
+func (p *Provider) zones(ctx context.Context) ([]auroradns.Zone, error) {
+	zones, _, err := p.client.ListZonesWithContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the zones: %w", err)
+	}
+
+	return slices.DeleteFunc(zones, func(zone auroradns.Zone) bool {
+		return !p.config.DomainFilter.matchZone(auroradns.NormalizeZoneName(zone.Name))
+	}), nil
+}
+
+// findZone Returns the zone of a name (the longest matching zone), and the name relative to the zone.
+func (p *Provider) findZone(zones []auroradns.Zone, dnsName string) (auroradns.Zone, string, bool) {
+	fqdn := auroradns.NormalizeZoneName(dnsName)
+
+	if !p.config.DomainFilter.Match(fqdn) {
+		return auroradns.Zone{}, "", false
+	}
+
+	var found auroradns.Zone
+
+	for _, zone := range zones {
+		name := auroradns.NormalizeZoneName(zone.Name)
+
+		if (fqdn != name && !strings.HasSuffix(fqdn, "."+name)) || len(name) <= len(auroradns.NormalizeZoneName(found.Name)) {
+			continue
+		}
+
+		found = zone
+	}
+
+	if found.ID == "" {
+		return auroradns.Zone{}, "", false
+	}
+
+	return found, auroradns.NormalizeName(fqdn, found.Name), true
+}
+
+func (p *Provider) ttl(endpoint *Endpoint) int {
+	if endpoint.RecordTTL > 0 {
+		return int(endpoint.RecordTTL)
+	}
+
+	return p.config.DefaultTTL
+}
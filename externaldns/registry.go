@@ -0,0 +1,79 @@
+package externaldns
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/nrdcg/auroradns"
+)
+
+// heritage the heritage of the external-dns TXT registry records.
+const heritage = "heritage=external-dns"
+
+// owners The owners of the records of a zone, from the external-dns TXT registry records.
+type owners struct {
+	// byKey the owners of the RRsets (new format: the registry record "a-www" owns the A records of "www").
+	byKey map[rrsetKey]string
+
+	// byName the owners of all the records of a name (old format: the registry record "www" owns the records of "www").
+	byName map[string]string
+}
+
+func newOwners(zone string, records []auroradns.Record) *owners {
+	o := &owners{byKey: make(map[rrsetKey]string), byName: make(map[string]string)}
+
+	for _, record := range records {
+		record = auroradns.NormalizeRecord(record, zone)
+
+		if record.RecordType != auroradns.RecordTypeTXT {
+			continue
+		}
+
+		owner, ok := parseRegistry(record.Content)
+		if !ok {
+			continue
+		}
+
+		o.byName[record.Name] = owner
+
+		label, rest, _ := strings.Cut(record.Name, ".")
+
+		prefix, name, found := strings.Cut(label, "-")
+		if !found || !slices.Contains(supportedTypes, strings.ToUpper(prefix)) {
+			continue
+		}
+
+		if rest != "" {
+			name += "." + rest
+		}
+
+		o.byKey[rrsetKey{name: name, recordType: strings.ToUpper(prefix)}] = owner
+	}
+
+	return o
+}
+
+// of Returns the owner of an RRset, or "" if it has no owner.
+func (o *owners) of(key rrsetKey) string {
+	if owner, ok := o.byKey[key]; ok {
+		return owner
+	}
+
+	return o.byName[key.name]
+}
+
+// parseRegistry Returns the owner of an external-dns TXT registry record
+// (e.g. "heritage=external-dns,external-dns/owner=cluster1,external-dns/resource=ingress/default/web").
+func parseRegistry(content string) (string, bool) {
+	if !strings.HasPrefix(content, heritage+",") && content != heritage {
+		return "", false
+	}
+
+	for label := range strings.SplitSeq(content, ",") {
+		if owner, ok := strings.CutPrefix(label, "external-dns/"+OwnerLabel+"="); ok {
+			return owner, true
+		}
+	}
+
+	return "", true
+}
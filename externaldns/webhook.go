@@ -0,0 +1,291 @@
+package externaldns
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/nrdcg/auroradns"
+)
+
+// MediaType the media type of the webhook protocol.
+const MediaType = "application/external.dns.webhook+json;version=1"
+
+// Endpoint An RRset, in the format of external-dns.
+type Endpoint struct {
+	DNSName          string                     `json:"dnsName,omitempty"`
+	Targets          []string                   `json:"targets,omitempty"`
+	RecordType       string                     `json:"recordType,omitempty"`
+	SetIdentifier    string                     `json:"setIdentifier,omitempty"`
+	RecordTTL        int64                      `json:"recordTTL,omitempty"`
+	Labels           map[string]string          `json:"labels,omitempty"`
+	ProviderSpecific []ProviderSpecificProperty `json:"providerSpecific,omitempty"`
+}
+
+// ProviderSpecificProperty A provider-specific property of an endpoint.
+type ProviderSpecificProperty struct {
+	Name  string `json:"name,omitempty"`
+	Value string `json:"value,omitempty"`
+}
+
+// Changes The changes computed by external-dns.
+// An update is described by the old endpoint (UpdateOld) and the new endpoint (UpdateNew).
+type Changes struct {
+	Create    []*Endpoint `json:"Create,omitempty"`
+	UpdateOld []*Endpoint `json:"UpdateOld,omitempty"`
+	UpdateNew []*Endpoint `json:"UpdateNew,omitempty"`
+	Delete    []*Endpoint `json:"Delete,omitempty"`
+}
+
+// DomainFilter The domains managed by the provider, sent to external-dns during the negotiation.
+type DomainFilter struct {
+	// Include the managed domains (and their subdomains). All the domains if empty.
+	Include []string `json:"include,omitempty"`
+
+	// Exclude the domains (and their subdomains) not managed.
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// Match Returns true if the name is managed.
+func (f DomainFilter) Match(name string) bool {
+	name = auroradns.NormalizeZoneName(name)
+
+	for _, domain := range f.Exclude {
+		if isSubdomain(name, auroradns.NormalizeZoneName(domain)) {
+			return false
+		}
+	}
+
+	if len(f.Include) == 0 {
+		return true
+	}
+
+	for _, domain := range f.Include {
+		if isSubdomain(name, auroradns.NormalizeZoneName(domain)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchZone Returns true if the zone contains managed names.
+func (f DomainFilter) matchZone(zone string) bool {
+	if f.Match(zone) {
+		return true
+	}
+
+	for _, domain := range f.Include {
+		if isSubdomain(domain, zone) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (f DomainFilter) normalize() DomainFilter {
+	normalized := DomainFilter{}
+
+	for _, domain := range f.Include {
+		normalized.Include = append(normalized.Include, auroradns.NormalizeZoneName(domain))
+	}
+
+	for _, domain := range f.Exclude {
+		normalized.Exclude = append(normalized.Exclude, auroradns.NormalizeZoneName(domain))
+	}
+
+	return normalized
+}
+
+func isSubdomain(name, domain string) bool {
+	return name == domain || strings.HasSuffix(name, "."+domain)
+}
+
+// ServeHTTP Serves the webhook protocol:
+//   - GET /: the negotiation, returns the domain filter.
+//   - GET /records: returns the endpoints (see Records).
+//   - POST /records: applies the changes (see ApplyChanges).
+//   - POST /adjustendpoints: returns the adjusted endpoints (see AdjustEndpoints).
+//   - GET /healthz: the health check.
+func (p *Provider) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.mux.ServeHTTP(w, r)
+}
+
+// ListenAndServe Serves the webhook protocol on the address until the context ends.
+func (p *Provider) ListenAndServe(ctx context.Context, addr string) error {
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           p,
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+
+	errs := make(chan error, 1)
+
+	go func() { errs <- server.ListenAndServe() }()
+
+	select {
+	case err := <-errs:
+		return err
+
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+		defer cancel()
+
+		return server.Shutdown(shutdownCtx)
+	}
+}
+
+func (p *Provider) routes() *http.ServeMux {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /{$}", p.negotiate)
+	mux.HandleFunc("GET /records", p.records)
+	mux.HandleFunc("POST /records", p.applyChanges)
+	mux.HandleFunc("POST /adjustendpoints", p.adjustEndpoints)
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	})
+
+	return mux
+}
+
+func (p *Provider) negotiate(w http.ResponseWriter, r *http.Request) {
+	if !accepts(w, r) {
+		return
+	}
+
+	p.writeJSON(w, http.StatusOK, p.config.DomainFilter)
+}
+
+func (p *Provider) records(w http.ResponseWriter, r *http.Request) {
+	if !accepts(w, r) {
+		return
+	}
+
+	endpoints, err := p.Records(r.Context())
+	if err != nil {
+		p.writeError(w, err)
+		return
+	}
+
+	if endpoints == nil {
+		endpoints = []*Endpoint{}
+	}
+
+	p.writeJSON(w, http.StatusOK, endpoints)
+}
+
+func (p *Provider) applyChanges(w http.ResponseWriter, r *http.Request) {
+	changes := new(Changes)
+
+	if !readJSON(w, r, changes) {
+		return
+	}
+
+	err := p.ApplyChanges(r.Context(), changes)
+	if err != nil {
+		p.writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (p *Provider) adjustEndpoints(w http.ResponseWriter, r *http.Request) {
+	var endpoints []*Endpoint
+
+	if !accepts(w, r) || !readJSON(w, r, &endpoints) {
+		return
+	}
+
+	p.writeJSON(w, http.StatusOK, p.AdjustEndpoints(endpoints))
+}
+
+// accepts Checks that the client accepts the media type of the protocol, or writes a 406 response.
+func accepts(w http.ResponseWriter, r *http.Request) bool {
+	for accept := range strings.SplitSeq(r.Header.Get("Accept"), ",") {
+		if isMediaType(accept) {
+			return true
+		}
+	}
+
+	http.Error(w, fmt.Sprintf("the client must accept %s", MediaType), http.StatusNotAcceptable)
+
+	return false
+}
+
+// readJSON Decodes the request body, or writes a 415 or 400 response.
+func readJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	if !isMediaType(r.Header.Get("Content-Type")) {
+		http.Error(w, fmt.Sprintf("the content type must be %s", MediaType), http.StatusUnsupportedMediaType)
+		return false
+	}
+
+	err := json.NewDecoder(r.Body).Decode(v)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid request body: %v", err), http.StatusBadRequest)
+		return false
+	}
+
+	return true
+}
+
+func isMediaType(value string) bool {
+	mediaType, params, err := mime.ParseMediaType(value)
+	if err != nil {
+		return false
+	}
+
+	expectedType, expectedParams, _ := mime.ParseMediaType(MediaType)
+
+	return mediaType == expectedType && params["version"] == expectedParams["version"]
+}
+
+func (p *Provider) writeJSON(w http.ResponseWriter, statusCode int, v any) {
+	w.Header().Set("Content-Type", MediaType)
+	w.WriteHeader(statusCode)
+
+	err := json.NewEncoder(w).Encode(v)
+	if err != nil {
+		p.logger.Error("externaldns: failed to write the response", slog.Any("error", err))
+	}
+}
+
+// writeError Writes the error with the status code of errorStatusCode.
+func (p *Provider) writeError(w http.ResponseWriter, err error) {
+	p.logger.Error("externaldns: request failed", slog.Any("error", err))
+
+	http.Error(w, err.Error(), errorStatusCode(err))
+}
+
+// errorStatusCode Returns the status code of an error:
+// 409 for ErrNotOwned, 403 for auroradns.ErrProtected,
+// the status code of the API for its client errors (e.g. an invalid record), except 429,
+// and 500 otherwise (external-dns retries the 5xx errors).
+func errorStatusCode(err error) int {
+	var respErr *auroradns.ResponseError
+
+	switch {
+	case errors.Is(err, ErrNotOwned):
+		return http.StatusConflict
+
+	case errors.Is(err, auroradns.ErrProtected):
+		return http.StatusForbidden
+
+	case errors.As(err, &respErr) && respErr.StatusCode >= http.StatusBadRequest && respErr.StatusCode < http.StatusInternalServerError &&
+		respErr.StatusCode != http.StatusTooManyRequests:
+		return respErr.StatusCode
+
+	default:
+		return http.StatusInternalServerError
+	}
+}
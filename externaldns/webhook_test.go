@@ -0,0 +1,323 @@
+package externaldns
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/nrdcg/auroradns"
+	"github.com/nrdcg/auroradns/internal/fakeapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const registryCluster2 = `"heritage=external-dns,external-dns/owner=cluster2,external-dns/resource=service/default/api"`
+
+func setupTest(t *testing.T) (*fakeapi.Server, string, *httptest.Server) {
+	t.Helper()
+
+	api := fakeapi.NewServer("", "")
+	t.Cleanup(api.Close)
+
+	client, err := auroradns.NewClient(nil, auroradns.WithBaseURL(api.URL))
+	require.NoError(t, err)
+
+	zoneID := api.AddZone("example.com")
+	api.AddRecord(zoneID, fakeapi.Record{Type: "A", Name: "api", Content: "192.0.2.20", TTL: 300})
+	api.AddRecord(zoneID, fakeapi.Record{Type: "TXT", Name: "a-api", Content: registryCluster2, TTL: 300})
+
+	api.AddZone("example.org")
+
+	provider, err := NewProvider(client, Config{
+		DomainFilter: DomainFilter{Include: []string{"Example.com."}},
+		OwnerID:      "cluster1",
+		Logger:       slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+	require.NoError(t, err)
+
+	server := httptest.NewServer(provider)
+	t.Cleanup(server.Close)
+
+	return api, zoneID, server
+}
+
+// send Sends a request as external-dns does, with a recorded body (testdata/<file>).
+func send(t *testing.T, server *httptest.Server, method, path, file string) (int, string) {
+	t.Helper()
+
+	var body io.Reader = http.NoBody
+
+	if file != "" {
+		raw, err := os.ReadFile(filepath.Join("testdata", file))
+		require.NoError(t, err)
+
+		body = bytes.NewReader(raw)
+	}
+
+	req, err := http.NewRequestWithContext(t.Context(), method, server.URL+path, body)
+	require.NoError(t, err)
+
+	req.Header.Set("Accept", MediaType)
+
+	if file != "" {
+		req.Header.Set("Content-Type", MediaType)
+	}
+
+	resp, err := server.Client().Do(req)
+	require.NoError(t, err)
+
+	defer func() { _ = resp.Body.Close() }()
+
+	raw, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	if resp.StatusCode == http.StatusOK {
+		assert.Equal(t, MediaType, resp.Header.Get("Content-Type"))
+	}
+
+	return resp.StatusCode, string(raw)
+}
+
+func TestProvider_negotiate(t *testing.T) {
+	_, _, server := setupTest(t)
+
+	code, body := send(t, server, http.MethodGet, "/", "")
+	require.Equal(t, http.StatusOK, code, body)
+
+	assert.JSONEq(t, `{"include":["example.com"]}`, body)
+
+	resp, err := server.Client().Get(server.URL)
+	require.NoError(t, err)
+
+	_ = resp.Body.Close()
+
+	assert.Equal(t, http.StatusNotAcceptable, resp.StatusCode)
+
+	resp, err = server.Client().Get(server.URL + "/healthz")
+	require.NoError(t, err)
+
+	_ = resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestProvider_adjustEndpoints(t *testing.T) {
+	_, _, server := setupTest(t)
+
+	code, body := send(t, server, http.MethodPost, "/adjustendpoints", "adjustendpoints.json")
+	require.Equal(t, http.StatusOK, code, body)
+
+	expected := `[
+		{"dnsName":"web.example.com","targets":["192.0.2.10"],"recordType":"A","recordTTL":300,"labels":{"resource":"ingress/default/web"}},
+		{"dnsName":"web.example.com","targets":["2001:db8::10"],"recordType":"AAAA","recordTTL":60,"labels":{"resource":"ingress/default/web"}}
+	]`
+	assert.JSONEq(t, expected, body)
+}
+
+func TestProvider_cname(t *testing.T) {
+	api, zoneID, server := setupTest(t)
+
+	api.AddRecord(zoneID, fakeapi.Record{Type: "CNAME", Name: "www", Content: "web.example.com.", TTL: 300})
+
+	// the desired target and the target of the record are in the same form: external-dns sees no change.
+	code, body := send(t, server, http.MethodPost, "/adjustendpoints", "adjustendpoints_cname.json")
+	require.Equal(t, http.StatusOK, code, body)
+
+	assert.JSONEq(t, `[
+		{"dnsName":"www.example.com","targets":["web.example.com"],"recordType":"CNAME","recordTTL":300,"labels":{"resource":"ingress/default/www"}}
+	]`, body)
+
+	code, body = send(t, server, http.MethodGet, "/records", "")
+	require.Equal(t, http.StatusOK, code, body)
+
+	assert.Contains(t, body, `{"dnsName":"www.example.com","targets":["web.example.com"],"recordType":"CNAME","recordTTL":300}`)
+
+	// the re-sync of the same target does not change the record.
+	code, body = send(t, server, http.MethodPost, "/records", "changes_cname.json")
+	require.Equal(t, http.StatusNoContent, code, body)
+
+	assert.Empty(t, api.Mutations())
+}
+
+func TestProvider_changes(t *testing.T) {
+	api, zoneID, server := setupTest(t)
+
+	code, body := send(t, server, http.MethodGet, "/records", "")
+	require.Equal(t, http.StatusOK, code, body)
+
+	// the TXT targets are returned without the zone file quoting.
+	expected := `[
+		{"dnsName":"a-api.example.com","targets":[` + strconv.Quote(auroradns.NormalizeContent(auroradns.RecordTypeTXT, registryCluster2)) + `],"recordType":"TXT","recordTTL":300,"labels":{"owner":"cluster2"}},
+		{"dnsName":"api.example.com","targets":["192.0.2.20"],"recordType":"A","recordTTL":300,"labels":{"owner":"cluster2"}}
+	]`
+	assert.JSONEq(t, expected, body)
+
+	// the endpoints of example.org are outside the domain filter.
+	code, body = send(t, server, http.MethodPost, "/records", "changes_create.json")
+	require.Equal(t, http.StatusNoContent, code, body)
+
+	// one listing, and one call per record.
+	assert.Equal(t, []string{
+		"GET /zones",
+		"GET /zones/zone-1/records",
+		"GET /zones",
+		"GET /zones/zone-1/records",
+		"POST /zones/zone-1/records",
+		"POST /zones/zone-1/records",
+		"POST /zones/zone-1/records",
+		"POST /zones/zone-1/records",
+	}, api.Requests())
+
+	code, body = send(t, server, http.MethodPost, "/records", "changes_update.json")
+	require.Equal(t, http.StatusNoContent, code, body)
+
+	code, body = send(t, server, http.MethodGet, "/records", "")
+	require.Equal(t, http.StatusOK, code, body)
+
+	registryCluster1 := strconv.Quote(`heritage=external-dns,external-dns/owner=cluster1,external-dns/resource=ingress/default/web`)
+
+	expected = `[
+		{"dnsName":"a-api.example.com","targets":[` + strconv.Quote(auroradns.NormalizeContent(auroradns.RecordTypeTXT, registryCluster2)) + `],"recordType":"TXT","recordTTL":300,"labels":{"owner":"cluster2"}},
+		{"dnsName":"a-web.example.com","targets":[` + registryCluster1 + `],"recordType":"TXT","recordTTL":300,"labels":{"owner":"cluster1"}},
+		{"dnsName":"api.example.com","targets":["192.0.2.20"],"recordType":"A","recordTTL":300,"labels":{"owner":"cluster2"}},
+		{"dnsName":"web.example.com","targets":["192.0.2.11","192.0.2.12"],"recordType":"A","recordTTL":300,"labels":{"owner":"cluster1"}},
+		{"dnsName":"web.example.com","targets":[` + registryCluster1 + `],"recordType":"TXT","recordTTL":300,"labels":{"owner":"cluster1"}}
+	]`
+	assert.JSONEq(t, expected, body)
+
+	// the records of cluster2 cannot be changed by cluster1.
+	code, body = send(t, server, http.MethodPost, "/records", "changes_foreign.json")
+	require.Equal(t, http.StatusConflict, code, body)
+
+	assert.Contains(t, body, "A api.example.com: record owned by another owner cluster2")
+
+	code, body = send(t, server, http.MethodPost, "/records", "changes_delete.json")
+	require.Equal(t, http.StatusNoContent, code, body)
+
+	records := api.Records(zoneID)
+	require.Len(t, records, 4)
+
+	assert.Equal(t, []fakeapi.Record{
+		{ID: "record-4", Type: "A", Name: "api", Content: "192.0.2.20", TTL: 300},
+		{ID: "record-5", Type: "TXT", Name: "a-api", Content: registryCluster2, TTL: 300},
+	}, records[2:])
+}
+
+func TestProvider_changes_foreignZone(t *testing.T) {
+	api, _, server := setupTest(t)
+
+	labID := api.AddZone("lab.example.com")
+	api.AddRecord(labID, fakeapi.Record{Type: "A", Name: "api", Content: "192.0.2.30", TTL: 300})
+	api.AddRecord(labID, fakeapi.Record{Type: "TXT", Name: "a-api", Content: registryCluster2, TTL: 300})
+
+	// the change of example.com is not applied: the change of lab.example.com is refused.
+	code, body := send(t, server, http.MethodPost, "/records", "changes_foreign_zones.json")
+	require.Equal(t, http.StatusConflict, code, body)
+
+	assert.Contains(t, body, "A api.lab.example.com: record owned by another owner cluster2")
+	assert.Empty(t, api.Mutations())
+}
+
+func TestProvider_changes_invalid(t *testing.T) {
+	api, zoneID, server := setupTest(t)
+
+	// the API refuses the record without type: external-dns must not retry.
+	code, body := send(t, server, http.MethodPost, "/records", "changes_invalid.json")
+	require.Equal(t, http.StatusBadRequest, code, body)
+
+	assert.Contains(t, body, "InvalidRecord - missing record type")
+	assert.Len(t, api.Records(zoneID), 4)
+}
+
+func Test_errorStatusCode(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		err      error
+		expected int
+	}{
+		{
+			desc:     "not owned",
+			err:      fmt.Errorf("A api.example.com: %w cluster2", ErrNotOwned),
+			expected: http.StatusConflict,
+		},
+		{
+			desc:     "protected",
+			err:      fmt.Errorf("zone example.com: %w", auroradns.ErrProtected),
+			expected: http.StatusForbidden,
+		},
+		{
+			desc:     "client error",
+			err:      fmt.Errorf("zone example.com: %w", &auroradns.ResponseError{ErrorCode: "InvalidRecord", StatusCode: http.StatusBadRequest}),
+			expected: http.StatusBadRequest,
+		},
+		{
+			desc:     "rate limit",
+			err:      &auroradns.ResponseError{ErrorCode: "TooManyRequests", StatusCode: http.StatusTooManyRequests},
+			expected: http.StatusInternalServerError,
+		},
+		{
+			desc:     "server error",
+			err:      &auroradns.ResponseError{ErrorCode: "InternalError", StatusCode: http.StatusBadGateway},
+			expected: http.StatusInternalServerError,
+		},
+		{
+			desc:     "other",
+			err:      errors.New("connection refused"),
+			expected: http.StatusInternalServerError,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			assert.Equal(t, test.expected, errorStatusCode(test.err))
+		})
+	}
+}
+
+func TestProvider_ApplyChanges_nil(t *testing.T) {
+	client, err := auroradns.NewClient(nil)
+	require.NoError(t, err)
+
+	provider, err := NewProvider(client, Config{})
+	require.NoError(t, err)
+
+	require.EqualError(t, provider.ApplyChanges(t.Context(), nil), "the changes are required")
+}
+
+func TestProvider_invalidRequest(t *testing.T) {
+	_, _, server := setupTest(t)
+
+	resp, err := server.Client().Post(server.URL+"/records", "application/json", bytes.NewReader([]byte(`{}`)))
+	require.NoError(t, err)
+
+	_ = resp.Body.Close()
+
+	assert.Equal(t, http.StatusUnsupportedMediaType, resp.StatusCode)
+
+	resp, err = server.Client().Post(server.URL+"/records", MediaType, bytes.NewReader([]byte(`{`)))
+	require.NoError(t, err)
+
+	_ = resp.Body.Close()
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestDomainFilter_Match(t *testing.T) {
+	filter := DomainFilter{Include: []string{"example.com"}, Exclude: []string{"internal.example.com"}}
+
+	assert.True(t, filter.Match("example.com"))
+	assert.True(t, filter.Match("www.Example.com."))
+	assert.False(t, filter.Match("db.internal.example.com"))
+	assert.False(t, filter.Match("example.org"))
+	assert.False(t, filter.Match("badexample.com"))
+
+	assert.True(t, DomainFilter{}.Match("example.org"))
+}
@@ -0,0 +1,242 @@
+package auroradns
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// ErrProtected The error returned when a guard policy refuses a call.
+var ErrProtected = errors.New("protected resource")
+
+type forceZoneDeletionKey struct{}
+
+// GuardPolicy The resources protected against the mutations.
+type GuardPolicy struct {
+	// ProtectedZones the zones (names or IDs) that cannot be deleted.
+	ProtectedZones []string
+
+	// ProtectedRecords the records that cannot be created, modified or deleted.
+	ProtectedRecords []GuardRule
+}
+
+// GuardRule A pattern of protected records.
+type GuardRule struct {
+	// Name the pattern of the record names (path.Match syntax), normalized like the record names (see NormalizeName):
+	// relative to the zone ("www", "@" for the apex), or absolute ("www.example.com.").
+	// An empty pattern matches all the names.
+	Name string
+
+	// Type the record type.
+	// An empty type matches all the types.
+	Type string
+}
+
+// match Returns true if the record of the zone matches the rule.
+func (r GuardRule) match(record Record, zone string) bool {
+	if r.Type != "" && !strings.EqualFold(r.Type, record.RecordType) {
+		return false
+	}
+
+	if r.Name == "" {
+		return true
+	}
+
+	return globMatch(NormalizeName(r.Name, zone), NormalizeName(record.Name, zone))
+}
+
+// WithGuard Refuses the destructive calls, with an error wrapping ErrProtected:
+//   - the deletion of a protected zone.
+//   - the deletion of a zone containing records (other than the apex NS and SOA records), unless forced with ForceZoneDeletion.
+//   - the deletion of the apex NS and SOA records.
+//   - the mutations of the records matching a protected record rule.
+//
+// The guard is a middleware (see WithMiddleware):
+// it must be registered before the middlewares that short-circuit the mutations (e.g. WithDryRun).
+func WithGuard(policy GuardPolicy) Option {
+	return func(client *Client) error {
+		g := &guard{client: client, policy: policy}
+
+		return WithMiddleware(g.middleware)(client)
+	}
+}
+
+// ForceZoneDeletion Returns a context that allows the deletion of zones containing records.
+// The protected zones still cannot be deleted.
+func ForceZoneDeletion(ctx context.Context) context.Context {
+	return context.WithValue(ctx, forceZoneDeletionKey{}, true)
+}
+
+type guard struct {
+	client *Client
+	policy GuardPolicy
+}
+
+func (g *guard) middleware(next Handler) Handler {
+	return func(call *Call) (*http.Response, error) {
+		if call.Request.Method == http.MethodGet || call.Request.Method == http.MethodHead {
+			return next(call)
+		}
+
+		err := g.check(call)
+		if err != nil {
+			return nil, err
+		}
+
+		return next(call)
+	}
+}
+
+func (g *guard) check(call *Call) error {
+	ctx := call.Request.Context()
+
+	switch {
+	case call.Operation == OperationDeleteZone:
+		return g.checkZoneDeletion(ctx, call.ZoneID)
+
+	case call.Operation == OperationUpdateRecord || call.Operation == OperationDeleteRecord:
+		zone, err := g.rulesZone(ctx, call.ZoneID)
+		if err != nil {
+			return err
+		}
+
+		record, found, err := g.findRecord(ctx, call.ZoneID, call.RecordID)
+		if err != nil {
+			return err
+		}
+
+		if found {
+			if call.Request.Method == http.MethodDelete && isApexNSorSOA(record) {
+				return fmt.Errorf("%w: the apex %s record cannot be deleted", ErrProtected, record.RecordType)
+			}
+
+			err = g.checkRecord(record, zone)
+			if err != nil {
+				return err
+			}
+		}
+
+		// the new state of the record: the fields of the body replace the current ones.
+		return g.checkRequestRecord(call, record, zone)
+
+	case call.Operation == OperationCreateRecord:
+		zone, err := g.rulesZone(ctx, call.ZoneID)
+		if err != nil {
+			return err
+		}
+
+		return g.checkRequestRecord(call, Record{}, zone)
+
+	default:
+		return nil
+	}
+}
+
+func (g *guard) checkZoneDeletion(ctx context.Context, zoneID string) error {
+	if len(g.policy.ProtectedZones) > 0 {
+		name, err := g.zoneName(ctx, zoneID)
+		if err != nil {
+			return err
+		}
+
+		for _, protected := range g.policy.ProtectedZones {
+			if protected == zoneID || name != "" && NormalizeZoneName(protected) == name {
+				return fmt.Errorf("%w: the zone %s is protected", ErrProtected, protected)
+			}
+		}
+	}
+
+	if force, _ := ctx.Value(forceZoneDeletionKey{}).(bool); force {
+		return nil
+	}
+
+	records, _, err := g.client.ListRecordsWithContext(ctx, zoneID)
+	if err != nil {
+		return fmt.Errorf("guard: %w", err)
+	}
+
+	count := len(slices.DeleteFunc(records, isApexNSorSOA))
+	if count > 0 {
+		return fmt.Errorf("%w: the zone %s contains %d records", ErrProtected, zoneID, count)
+	}
+
+	return nil
+}
+
+// checkRequestRecord checks the record sent in the body of the request, if any, applied to the current record.
+func (g *guard) checkRequestRecord(call *Call, record Record, zone string) error {
+	body, err := readRequestBody(call.Request)
+	if err != nil || len(body) == 0 {
+		return err
+	}
+
+	err = json.Unmarshal(body, &record)
+	if err != nil {
+		// not a record.
+		return nil //nolint:nilerr // only the records are checked.
+	}
+
+	return g.checkRecord(record, zone)
+}
+
+func (g *guard) checkRecord(record Record, zone string) error {
+	for _, rule := range g.policy.ProtectedRecords {
+		if rule.match(record, zone) {
+			return fmt.Errorf("%w: the record %s %q matches the rule %s %q", ErrProtected, record.RecordType, record.Name, rule.Type, rule.Name)
+		}
+	}
+
+	return nil
+}
+
+// rulesZone Returns the name of the zone, to match the absolute names of the rules, if any.
+func (g *guard) rulesZone(ctx context.Context, zoneID string) (string, error) {
+	named := slices.ContainsFunc(g.policy.ProtectedRecords, func(rule GuardRule) bool { return rule.Name != "" })
+	if !named {
+		return "", nil
+	}
+
+	return g.zoneName(ctx, zoneID)
+}
+
+func (g *guard) zoneName(ctx context.Context, zoneID string) (string, error) {
+	zones, _, err := g.client.ListZonesWithContext(ctx)
+	if err != nil {
+		return "", fmt.Errorf("guard: %w", err)
+	}
+
+	for _, zone := range zones {
+		if zone.ID == zoneID {
+			return NormalizeZoneName(zone.Name), nil
+		}
+	}
+
+	return "", nil
+}
+
+func (g *guard) findRecord(ctx context.Context, zoneID, recordID string) (Record, bool, error) {
+	records, _, err := g.client.ListRecordsWithContext(ctx, zoneID)
+	if err != nil {
+		return Record{}, false, fmt.Errorf("guard: %w", err)
+	}
+
+	for _, record := range records {
+		if record.ID == recordID {
+			return record, true, nil
+		}
+	}
+
+	return Record{}, false, nil
+}
+
+func isApexNSorSOA(record Record) bool {
+	if !strings.EqualFold(record.RecordType, RecordTypeNS) && !strings.EqualFold(record.RecordType, RecordTypeSOA) {
+		return false
+	}
+
+	return NormalizeName(record.Name, "") == ""
+}
@@ -0,0 +1,219 @@
+package auroradns
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupGuardTest(t *testing.T, policy GuardPolicy) (*Client, *[]string) {
+	t.Helper()
+
+	client, mux := setupTest(t, WithGuard(policy))
+
+	var sent []string
+
+	handleAPI(mux, "/zones", http.MethodGet, func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`[
+			{"id":"identifier-zone-1","name":"example.com"},
+			{"id":"identifier-zone-2","name":"example.org"},
+			{"id":"identifier-zone-3","name":"example.net"}
+		]`))
+	})
+
+	record := func(w http.ResponseWriter, r *http.Request) {
+		sent = append(sent, r.Method+" "+r.URL.Path)
+
+		_, _ = w.Write([]byte(`{"id":"identifier-record-1","type":"A","name":"www"}`))
+	}
+
+	mux.HandleFunc("/zones/identifier-zone-1/records", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			record(w, r)
+			return
+		}
+
+		_, _ = w.Write([]byte(`[
+			{"id":"soa","type":"SOA","name":"","content":"ns1.example.com admin.example.com 1 86400 7200 604800 300"},
+			{"id":"ns","type":"NS","name":"@","content":"ns1.example.com"},
+			{"id":"www","type":"A","name":"www","content":"192.0.2.1"},
+			{"id":"challenge","type":"TXT","name":"_acme-challenge","content":"xxx"}
+		]`))
+	})
+
+	handleAPI(mux, "/zones/identifier-zone-2/records", http.MethodGet, func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`[
+			{"id":"soa","type":"SOA","name":"","content":"ns1.example.com admin.example.com 1 86400 7200 604800 300"},
+			{"id":"ns","type":"NS","name":"","content":"ns1.example.com"}
+		]`))
+	})
+
+	mux.HandleFunc("DELETE /zones/{zone}", record)
+	mux.HandleFunc("DELETE /zones/{zone}/records/{record}", record)
+
+	return client, &sent
+}
+
+func TestWithGuard_DeleteZone(t *testing.T) {
+	client, sent := setupGuardTest(t, GuardPolicy{ProtectedZones: []string{"example.net.", "identifier-zone-4"}})
+
+	_, _, err := client.DeleteZoneWithContext(t.Context(), "identifier-zone-3")
+	require.ErrorIs(t, err, ErrProtected)
+	require.EqualError(t, err, "protected resource: the zone example.net. is protected")
+
+	_, _, err = client.DeleteZoneWithContext(t.Context(), "identifier-zone-4")
+	require.EqualError(t, err, "protected resource: the zone identifier-zone-4 is protected")
+
+	_, _, err = client.DeleteZoneWithContext(ForceZoneDeletion(t.Context()), "identifier-zone-3")
+	require.ErrorIs(t, err, ErrProtected)
+
+	_, _, err = client.DeleteZoneWithContext(t.Context(), "identifier-zone-1")
+	require.EqualError(t, err, "protected resource: the zone identifier-zone-1 contains 2 records")
+
+	assert.Empty(t, *sent)
+
+	_, _, err = client.DeleteZoneWithContext(ForceZoneDeletion(t.Context()), "identifier-zone-1")
+	require.NoError(t, err)
+
+	_, _, err = client.DeleteZoneWithContext(t.Context(), "identifier-zone-2")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"DELETE /zones/identifier-zone-1", "DELETE /zones/identifier-zone-2"}, *sent)
+}
+
+func TestWithGuard_DeleteRecord(t *testing.T) {
+	client, sent := setupGuardTest(t, GuardPolicy{
+		ProtectedRecords: []GuardRule{{Name: "_acme-*", Type: RecordTypeTXT}},
+	})
+
+	_, _, err := client.DeleteRecordWithContext(t.Context(), "identifier-zone-1", "soa")
+	require.EqualError(t, err, "protected resource: the apex SOA record cannot be deleted")
+
+	_, _, err = client.DeleteRecordWithContext(t.Context(), "identifier-zone-1", "ns")
+	require.EqualError(t, err, "protected resource: the apex NS record cannot be deleted")
+
+	_, _, err = client.DeleteRecordWithContext(t.Context(), "identifier-zone-1", "challenge")
+	require.EqualError(t, err, `protected resource: the record TXT "_acme-challenge" matches the rule TXT "_acme-*"`)
+
+	assert.Empty(t, *sent)
+
+	_, _, err = client.DeleteRecordWithContext(t.Context(), "identifier-zone-1", "www")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"DELETE /zones/identifier-zone-1/records/www"}, *sent)
+}
+
+func TestWithGuard_CreateRecord(t *testing.T) {
+	client, sent := setupGuardTest(t, GuardPolicy{
+		ProtectedRecords: []GuardRule{{Type: RecordTypeMX}, {Name: "MAIL"}},
+	})
+
+	_, _, err := client.CreateRecordWithContext(t.Context(), "identifier-zone-1", Record{RecordType: RecordTypeMX, Name: "", Content: "mx.example.com"})
+	require.ErrorIs(t, err, ErrProtected)
+
+	_, _, err = client.CreateRecordWithContext(t.Context(), "identifier-zone-1", Record{RecordType: RecordTypeA, Name: "mail", Content: "192.0.2.1"})
+	require.ErrorIs(t, err, ErrProtected)
+
+	assert.Empty(t, *sent)
+
+	_, _, err = client.CreateRecordWithContext(t.Context(), "identifier-zone-1", Record{RecordType: RecordTypeA, Name: "www", Content: "192.0.2.1"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"POST /zones/identifier-zone-1/records"}, *sent)
+}
+
+func TestWithGuard_ruleNames(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		rule     GuardRule
+		recordID string
+	}{
+		{
+			desc:     "apex",
+			rule:     GuardRule{Name: "@", Type: RecordTypeNS},
+			recordID: "ns",
+		},
+		{
+			desc:     "absolute name",
+			rule:     GuardRule{Name: "WWW.example.com."},
+			recordID: "www",
+		},
+		{
+			desc:     "absolute pattern",
+			rule:     GuardRule{Name: "_acme-*.example.com"},
+			recordID: "challenge",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			client, sent := setupGuardTest(t, GuardPolicy{ProtectedRecords: []GuardRule{test.rule}})
+
+			call := &Call{
+				Operation: OperationUpdateRecord,
+				ZoneID:    "identifier-zone-1",
+				RecordID:  test.recordID,
+				Request:   httptest.NewRequestWithContext(t.Context(), http.MethodPut, "/", http.NoBody),
+			}
+
+			g := &guard{client: client, policy: GuardPolicy{ProtectedRecords: []GuardRule{test.rule}}}
+
+			err := g.check(call)
+			require.ErrorIs(t, err, ErrProtected)
+
+			assert.Empty(t, *sent)
+		})
+	}
+}
+
+func TestWithGuard_UpdateRecord(t *testing.T) {
+	policy := GuardPolicy{ProtectedRecords: []GuardRule{{Name: "mail"}, {Type: RecordTypeTXT}}}
+
+	client, _ := setupGuardTest(t, policy)
+
+	g := &guard{client: client, policy: policy}
+
+	testCases := []struct {
+		desc     string
+		body     string
+		expected string
+	}{
+		{
+			desc:     "rename",
+			body:     `{"name":"mail.example.com."}`,
+			expected: `protected resource: the record A "mail.example.com." matches the rule  "mail"`,
+		},
+		{
+			desc:     "retype",
+			body:     `{"type":"TXT","content":"xxx"}`,
+			expected: `protected resource: the record TXT "www" matches the rule TXT ""`,
+		},
+		{
+			desc: "content",
+			body: `{"content":"192.0.2.2"}`,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			call := &Call{
+				Operation: OperationUpdateRecord,
+				ZoneID:    "identifier-zone-1",
+				RecordID:  "www",
+				Request:   httptest.NewRequestWithContext(t.Context(), http.MethodPut, "/", strings.NewReader(test.body)),
+			}
+
+			err := g.check(call)
+			if test.expected == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			require.EqualError(t, err, test.expected)
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package auroradns
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Health check types.
+const (
+	HealthCheckTypeHTTP  = "HTTP"
+	HealthCheckTypeHTTPS = "HTTPS"
+	HealthCheckTypeTCP   = "TCP"
+)
+
+// HealthCheck a health check of a zone.
+type HealthCheck struct {
+	ID           string `json:"id,omitempty"`
+	Type         string `json:"type"`
+	IPAddress    string `json:"ip_address,omitempty"`
+	FQDN         string `json:"fqdn,omitempty"`
+	Port         int    `json:"port,omitempty"`
+	Path         string `json:"path,omitempty"`
+	SearchString string `json:"search_string,omitempty"`
+	Interval     int    `json:"interval,omitempty"`
+	Threshold    int    `json:"threshold,omitempty"`
+}
+
+// createHealthCheck Creates a health check.
+func (c *Client) createHealthCheck(ctx context.Context, zoneID string, healthCheck HealthCheck) (*HealthCheck, error) {
+	body, err := json.Marshal(healthCheck)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshall request body: %w", err)
+	}
+
+	endpoint := c.baseURL.JoinPath("zones", zoneID, "health_checks")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	newHealthCheck := new(HealthCheck)
+
+	_, err = c.do(&Call{Operation: OperationCreateHealthCheck, ZoneID: zoneID, Request: req}, newHealthCheck)
+	if err != nil {
+		return nil, err
+	}
+
+	return newHealthCheck, nil
+}
+
+// listHealthChecks returns a list of all health checks in given zone.
+func (c *Client) listHealthChecks(ctx context.Context, zoneID string) ([]HealthCheck, error) {
+	endpoint := c.baseURL.JoinPath("zones", zoneID, "health_checks")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+
+	var healthChecks []HealthCheck
+
+	_, err = c.do(&Call{Operation: OperationListHealthChecks, ZoneID: zoneID, Request: req}, &healthChecks)
+	if err != nil {
+		return nil, err
+	}
+
+	return healthChecks, nil
+}
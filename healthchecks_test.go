@@ -0,0 +1,107 @@
+package auroradns
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_createHealthCheck(t *testing.T) {
+	client, mux := setupTest(t)
+
+	zoneID := "identifier-zone-1"
+
+	handleAPI(mux, "/zones/identifier-zone-1/health_checks", http.MethodPost, func(w http.ResponseWriter, r *http.Request) {
+		reqBody, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if string(reqBody) != `{"type":"HTTP","ip_address":"192.0.2.1","port":80,"path":"/health"}` {
+			http.Error(w, fmt.Sprintf("invalid request body: %s", string(reqBody)), http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+
+		_, err = fmt.Fprintf(w, `{
+				"id":         "identifier-health-check-1",
+				"type":       "HTTP",
+				"ip_address": "192.0.2.1",
+				"port":       80,
+				"path":       "/health",
+				"interval":   10
+			}`)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	healthCheck := HealthCheck{
+		Type:      HealthCheckTypeHTTP,
+		IPAddress: "192.0.2.1",
+		Port:      80,
+		Path:      "/health",
+	}
+
+	newHealthCheck, err := client.createHealthCheck(t.Context(), zoneID, healthCheck)
+	require.NoError(t, err)
+
+	expected := &HealthCheck{
+		ID:        "identifier-health-check-1",
+		Type:      HealthCheckTypeHTTP,
+		IPAddress: "192.0.2.1",
+		Port:      80,
+		Path:      "/health",
+		Interval:  10,
+	}
+	assert.Equal(t, expected, newHealthCheck)
+}
+
+func TestClient_listHealthChecks(t *testing.T) {
+	client, mux := setupTest(t)
+
+	handleAPI(mux, "/zones/identifier-zone-1/health_checks", http.MethodGet, func(w http.ResponseWriter, _ *http.Request) {
+		_, err := fmt.Fprintf(w, `[
+        {
+          "id": "aaa",
+          "type": "TCP",
+          "ip_address": "192.0.2.1",
+          "port": 443
+        }
+      ]`)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	healthChecks, err := client.listHealthChecks(t.Context(), "identifier-zone-1")
+	require.NoError(t, err)
+
+	expected := []HealthCheck{
+		{ID: "aaa", Type: HealthCheckTypeTCP, IPAddress: "192.0.2.1", Port: 443},
+	}
+	assert.Equal(t, expected, healthChecks)
+}
+
+func TestClient_listHealthChecks_error(t *testing.T) {
+	client, mux := setupTest(t)
+
+	handleAPI(mux, "/zones/identifier-zone-1/health_checks", http.MethodGet, func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+
+		_, _ = fmt.Fprintf(w, `{"error": "ZoneNotFound", "errormsg": "the zone does not exist"}`)
+	})
+
+	healthChecks, err := client.listHealthChecks(t.Context(), "identifier-zone-1")
+	require.EqualError(t, err, "ZoneNotFound - the zone does not exist")
+
+	assert.Nil(t, healthChecks)
+}
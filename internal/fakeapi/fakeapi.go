@@ -0,0 +1,407 @@
+// Package fakeapi An in-memory implementation of the Aurora DNS API, for the tests.
+package fakeapi
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Zone a zone stored by the server.
+type Zone struct {
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name"`
+}
+
+// Record a record stored by the server.
+type Record struct {
+	ID            string `json:"id,omitempty"`
+	Type          string `json:"type"`
+	Name          string `json:"name"`
+	Content       string `json:"content"`
+	TTL           int    `json:"ttl,omitempty"`
+	HealthCheckID string `json:"health_check_id,omitempty"`
+}
+
+// HealthCheck a health check stored by the server.
+// The fields other than the ID are stored as is.
+type HealthCheck map[string]any
+
+// Server An in-memory Aurora DNS API.
+//
+// A new zone contains an apex SOA record and an apex NS record.
+// The IDs are sequential ("zone-1", "record-1", "health-check-1").
+type Server struct {
+	*httptest.Server
+
+	apiKey string
+	secret string
+
+	mu           sync.Mutex
+	seq          int
+	zones        []Zone
+	records      map[string][]Record
+	healthChecks map[string][]HealthCheck
+	requests     []string
+}
+
+// NewServer Starts a server.
+// If apiKey and secret are not empty, the requests must be signed with them.
+func NewServer(apiKey, secret string) *Server {
+	s := &Server{
+		apiKey:       apiKey,
+		secret:       secret,
+		records:      make(map[string][]Record),
+		healthChecks: make(map[string][]HealthCheck),
+	}
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /zones", s.listZones)
+	mux.HandleFunc("POST /zones", s.createZone)
+	mux.HandleFunc("DELETE /zones/{zone}", s.deleteZone)
+	mux.HandleFunc("GET /zones/{zone}/records", s.listRecords)
+	mux.HandleFunc("POST /zones/{zone}/records", s.createRecord)
+	mux.HandleFunc("PUT /zones/{zone}/records/{record}", s.updateRecord)
+	mux.HandleFunc("DELETE /zones/{zone}/records/{record}", s.deleteRecord)
+	mux.HandleFunc("GET /zones/{zone}/health_checks", s.listHealthChecks)
+	mux.HandleFunc("POST /zones/{zone}/health_checks", s.createHealthCheck)
+
+	s.Server = httptest.NewServer(s.authenticate(mux))
+
+	return s
+}
+
+// AddZone Adds a zone and returns its ID.
+func (s *Server) AddZone(name string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.addZone(name).ID
+}
+
+// AddRecord Adds a record to a zone and returns its ID.
+func (s *Server) AddRecord(zoneID string, record Record) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record.ID = s.nextID("record")
+	s.records[zoneID] = append(s.records[zoneID], record)
+
+	return record.ID
+}
+
+// AddHealthCheck Adds a health check to a zone and returns its ID.
+func (s *Server) AddHealthCheck(zoneID string, healthCheck HealthCheck) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.addHealthCheck(zoneID, healthCheck)["id"].(string)
+}
+
+// Zones Returns the zones.
+func (s *Server) Zones() []Zone {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Clone(s.zones)
+}
+
+// Records Returns the records of a zone.
+func (s *Server) Records(zoneID string) []Record {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Clone(s.records[zoneID])
+}
+
+// HealthChecks Returns the health checks of a zone.
+func (s *Server) HealthChecks(zoneID string) []HealthCheck {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Clone(s.healthChecks[zoneID])
+}
+
+// Requests Returns the received requests ("METHOD /path"), in order.
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Clone(s.requests)
+}
+
+// Mutations Returns the received requests other than GET, in order.
+func (s *Server) Mutations() []string {
+	return slices.DeleteFunc(s.Requests(), func(r string) bool {
+		return strings.HasPrefix(r, http.MethodGet+" ")
+	})
+}
+
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+		s.mu.Unlock()
+
+		if s.apiKey != "" && r.Header.Get("Authorization") != "AuroraDNSv1 "+s.token(r) {
+			writeError(w, http.StatusUnauthorized, "Unauthorized", "invalid signature")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) token(r *http.Request) string {
+	mac := hmac.New(sha256.New, []byte(s.secret))
+	mac.Write([]byte(r.Method + r.URL.Path + r.Header.Get("X-AuroraDNS-Date")))
+
+	signature := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+	return base64.StdEncoding.EncodeToString([]byte(s.apiKey + ":" + signature))
+}
+
+func (s *Server) listZones(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, append([]Zone{}, s.zones...))
+}
+
+func (s *Server) createZone(w http.ResponseWriter, r *http.Request) {
+	var zone Zone
+
+	if !readJSON(w, r, &zone) {
+		return
+	}
+
+	if zone.Name == "" {
+		writeError(w, http.StatusBadRequest, "InvalidZone", "missing zone name")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if slices.ContainsFunc(s.zones, func(z Zone) bool { return strings.EqualFold(z.Name, zone.Name) }) {
+		writeError(w, http.StatusConflict, "DuplicateZone", fmt.Sprintf("the zone %s already exists", zone.Name))
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, s.addZone(zone.Name))
+}
+
+func (s *Server) deleteZone(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	zoneID := r.PathValue("zone")
+
+	i := slices.IndexFunc(s.zones, func(z Zone) bool { return z.ID == zoneID })
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "ZoneNotFound", fmt.Sprintf("the zone %s does not exist", zoneID))
+		return
+	}
+
+	s.zones = slices.Delete(s.zones, i, i+1)
+	delete(s.records, zoneID)
+	delete(s.healthChecks, zoneID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) listRecords(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	zoneID := r.PathValue("zone")
+	if !s.zoneExists(w, zoneID) {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, append([]Record{}, s.records[zoneID]...))
+}
+
+func (s *Server) createRecord(w http.ResponseWriter, r *http.Request) {
+	var record Record
+
+	if !readJSON(w, r, &record) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	zoneID := r.PathValue("zone")
+	if !s.zoneExists(w, zoneID) || !validRecord(w, record) {
+		return
+	}
+
+	record.ID = s.nextID("record")
+	s.records[zoneID] = append(s.records[zoneID], record)
+
+	writeJSON(w, http.StatusCreated, record)
+}
+
+func (s *Server) updateRecord(w http.ResponseWriter, r *http.Request) {
+	var record Record
+
+	if !readJSON(w, r, &record) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	zoneID := r.PathValue("zone")
+	if !s.zoneExists(w, zoneID) || !validRecord(w, record) {
+		return
+	}
+
+	i := s.recordIndex(w, zoneID, r.PathValue("record"))
+	if i < 0 {
+		return
+	}
+
+	record.ID = s.records[zoneID][i].ID
+	s.records[zoneID][i] = record
+
+	writeJSON(w, http.StatusOK, record)
+}
+
+func (s *Server) deleteRecord(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	zoneID := r.PathValue("zone")
+	if !s.zoneExists(w, zoneID) {
+		return
+	}
+
+	i := s.recordIndex(w, zoneID, r.PathValue("record"))
+	if i < 0 {
+		return
+	}
+
+	s.records[zoneID] = slices.Delete(s.records[zoneID], i, i+1)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) listHealthChecks(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	zoneID := r.PathValue("zone")
+	if !s.zoneExists(w, zoneID) {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, append([]HealthCheck{}, s.healthChecks[zoneID]...))
+}
+
+func (s *Server) createHealthCheck(w http.ResponseWriter, r *http.Request) {
+	var healthCheck HealthCheck
+
+	if !readJSON(w, r, &healthCheck) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	zoneID := r.PathValue("zone")
+	if !s.zoneExists(w, zoneID) {
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, s.addHealthCheck(zoneID, healthCheck))
+}
+
+func (s *Server) addZone(name string) Zone {
+	zone := Zone{ID: s.nextID("zone"), Name: name}
+	s.zones = append(s.zones, zone)
+
+	s.healthChecks[zone.ID] = []HealthCheck{}
+	s.records[zone.ID] = []Record{
+		{ID: s.nextID("record"), Type: "SOA", Name: "", Content: "ns1.auroradns.eu. hostmaster." + name + ". 1 86400 7200 604800 300", TTL: 4800},
+		{ID: s.nextID("record"), Type: "NS", Name: "", Content: "ns1.auroradns.eu.", TTL: 4800},
+	}
+
+	return zone
+}
+
+// addHealthCheck stores a copy of the health check with a new ID: the health check can be nil (a "null" request body).
+func (s *Server) addHealthCheck(zoneID string, healthCheck HealthCheck) HealthCheck {
+	stored := make(HealthCheck, len(healthCheck)+1)
+	maps.Copy(stored, healthCheck)
+	stored["id"] = s.nextID("health-check")
+
+	s.healthChecks[zoneID] = append(s.healthChecks[zoneID], stored)
+
+	return stored
+}
+
+func (s *Server) nextID(kind string) string {
+	s.seq++
+
+	return fmt.Sprintf("%s-%d", kind, s.seq)
+}
+
+func (s *Server) zoneExists(w http.ResponseWriter, zoneID string) bool {
+	if slices.ContainsFunc(s.zones, func(z Zone) bool { return z.ID == zoneID }) {
+		return true
+	}
+
+	writeError(w, http.StatusNotFound, "ZoneNotFound", fmt.Sprintf("the zone %s does not exist", zoneID))
+
+	return false
+}
+
+func (s *Server) recordIndex(w http.ResponseWriter, zoneID, recordID string) int {
+	i := slices.IndexFunc(s.records[zoneID], func(r Record) bool { return r.ID == recordID })
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "RecordNotFound", fmt.Sprintf("the record %s does not exist", recordID))
+	}
+
+	return i
+}
+
+func validRecord(w http.ResponseWriter, record Record) bool {
+	if record.Type == "" {
+		writeError(w, http.StatusBadRequest, "InvalidRecord", "missing record type")
+		return false
+	}
+
+	return true
+}
+
+func readJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	err := json.NewDecoder(r.Body).Decode(v)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "InvalidRequest", err.Error())
+		return false
+	}
+
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, statusCode int, code, message string) {
+	writeJSON(w, statusCode, map[string]string{"error": code, "errormsg": message})
+}
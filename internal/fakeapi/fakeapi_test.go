@@ -0,0 +1,28 @@
+package fakeapi
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_healthChecks(t *testing.T) {
+	server := NewServer("", "")
+	t.Cleanup(server.Close)
+
+	zoneID := server.AddZone("example.com")
+
+	assert.Equal(t, "health-check-4", server.AddHealthCheck(zoneID, nil))
+
+	resp, err := server.Client().Post(server.URL+"/zones/"+zoneID+"/health_checks", "application/json", strings.NewReader("null"))
+	require.NoError(t, err)
+
+	_ = resp.Body.Close()
+
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	assert.Equal(t, []HealthCheck{{"id": "health-check-4"}, {"id": "health-check-5"}}, server.HealthChecks(zoneID))
+}
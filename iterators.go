@@ -0,0 +1,103 @@
+package auroradns
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"net/http"
+)
+
+// AllZones Returns an iterator over all the zones.
+//
+// The response is decoded as a stream: the zones are yielded while the response is read.
+// The Aurora DNS API has no pagination: all the zones are fetched by a single request.
+// The iteration stops after the first error.
+func (c *Client) AllZones(ctx context.Context) iter.Seq2[Zone, error] {
+	return func(yield func(Zone, error) bool) {
+		endpoint := c.baseURL.JoinPath("zones")
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), http.NoBody)
+		if err != nil {
+			yield(Zone{}, err)
+			return
+		}
+
+		streamArray(c, &Call{Operation: OperationListZones, Request: req}, yield)
+	}
+}
+
+// AllRecords Returns an iterator over all the records of a zone.
+//
+// The response is decoded as a stream: the records are yielded while the response is read.
+// The Aurora DNS API has no pagination: all the records are fetched by a single request.
+// The iteration stops after the first error.
+func (c *Client) AllRecords(ctx context.Context, zoneID string) iter.Seq2[Record, error] {
+	return func(yield func(Record, error) bool) {
+		endpoint := c.baseURL.JoinPath("zones", zoneID, "records")
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), http.NoBody)
+		if err != nil {
+			yield(Record{}, err)
+			return
+		}
+
+		streamArray(c, &Call{Operation: OperationListRecords, ZoneID: zoneID, Request: req}, yield)
+	}
+}
+
+// streamArray executes the call and yields the elements of the JSON array of the response, one at a time.
+func streamArray[T any](c *Client, call *Call, yield func(T, error) bool) {
+	var zero T
+
+	resp, err := c.stream(call)
+	if resp != nil && resp.Body != nil {
+		defer func() { _ = resp.Body.Close() }()
+	}
+
+	if err != nil {
+		yield(zero, err)
+		return
+	}
+
+	if resp.Body == nil {
+		return
+	}
+
+	decoder := json.NewDecoder(resp.Body)
+
+	token, err := decoder.Token()
+	if err != nil {
+		yield(zero, fmt.Errorf("unmarshaling %T error: %w", zero, err))
+		return
+	}
+
+	if token == nil {
+		// null
+		return
+	}
+
+	if token != json.Delim('[') {
+		yield(zero, fmt.Errorf("unmarshaling %T error: expected an array, got %v", zero, token))
+		return
+	}
+
+	for decoder.More() {
+		var item T
+
+		err = decoder.Decode(&item)
+		if err != nil {
+			yield(zero, fmt.Errorf("unmarshaling %T error: %w", zero, err))
+			return
+		}
+
+		if !yield(item, nil) {
+			return
+		}
+	}
+
+	_, err = decoder.Token()
+	if err != nil {
+		yield(zero, fmt.Errorf("unmarshaling %T error: %w", zero, err))
+	}
+}
@@ -0,0 +1,135 @@
+package auroradns
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_AllZones(t *testing.T) {
+	client, mux := setupTest(t)
+
+	handleAPI(mux, "/zones", http.MethodGet, func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = fmt.Fprint(w, `[
+				{"id": "identifier-zone-1", "name": "example.com"},
+				{"id": "identifier-zone-2", "name": "example.org"}
+			]`)
+	})
+
+	var zones []Zone
+
+	for zone, err := range client.AllZones(t.Context()) {
+		require.NoError(t, err)
+
+		zones = append(zones, zone)
+	}
+
+	expected := []Zone{
+		{ID: "identifier-zone-1", Name: "example.com"},
+		{ID: "identifier-zone-2", Name: "example.org"},
+	}
+	assert.Equal(t, expected, zones)
+}
+
+func TestClient_AllRecords(t *testing.T) {
+	client, mux := setupTest(t)
+
+	handleAPI(mux, "/zones/identifier-zone-1/records", http.MethodGet, func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = fmt.Fprint(w, `[`)
+
+		for i := range 1000 {
+			if i > 0 {
+				_, _ = fmt.Fprint(w, `,`)
+			}
+
+			_, _ = fmt.Fprintf(w, `{"id":"record-%d","type":"TXT","name":"foo%d","ttl":300}`, i, i)
+		}
+
+		_, _ = fmt.Fprint(w, `]`)
+	})
+
+	var count int
+
+	for record, err := range client.AllRecords(t.Context(), "identifier-zone-1") {
+		require.NoError(t, err)
+
+		assert.Equal(t, Record{ID: fmt.Sprintf("record-%d", count), RecordType: RecordTypeTXT, Name: fmt.Sprintf("foo%d", count), TTL: 300}, record)
+
+		count++
+	}
+
+	assert.Equal(t, 1000, count)
+}
+
+func TestClient_AllRecords_break(t *testing.T) {
+	client, mux := setupTest(t)
+
+	handleAPI(mux, "/zones/identifier-zone-1/records", http.MethodGet, func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = fmt.Fprint(w, `[{"id":"aaa","type":"TXT","name":"foo"},{"id":"bbb","type":"TXT","name":"bar"},{"id":"ccc","type":"TXT","name":"baz"}]`)
+	})
+
+	var ids []string
+
+	for record, err := range client.AllRecords(t.Context(), "identifier-zone-1") {
+		require.NoError(t, err)
+
+		ids = append(ids, record.ID)
+
+		if record.ID == "bbb" {
+			break
+		}
+	}
+
+	assert.Equal(t, []string{"aaa", "bbb"}, ids)
+}
+
+func TestClient_AllRecords_error(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		status   int
+		body     string
+		expected string
+	}{
+		{
+			desc:     "API error",
+			status:   http.StatusUnauthorized,
+			body:     `{"error":"AuthenticationRequiredError","errormsg":"Failed to parse Authorization header"}`,
+			expected: "AuthenticationRequiredError - Failed to parse Authorization header",
+		},
+		{
+			desc:     "not an array",
+			status:   http.StatusOK,
+			body:     `{"id":"aaa"}`,
+			expected: "unmarshaling auroradns.Record error: expected an array, got {",
+		},
+		{
+			desc:     "truncated",
+			status:   http.StatusOK,
+			body:     `[{"id":"aaa","type":"TXT","name":"foo"},{"id":"bbb"`,
+			expected: "unmarshaling auroradns.Record error: unexpected EOF",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			client, mux := setupTest(t)
+
+			handleAPI(mux, "/zones/identifier-zone-1/records", http.MethodGet, func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(test.status)
+
+				_, _ = fmt.Fprint(w, test.body)
+			})
+
+			var lastErr error
+
+			for _, err := range client.AllRecords(t.Context(), "identifier-zone-1") {
+				lastErr = err
+			}
+
+			require.EqualError(t, lastErr, test.expected)
+		})
+	}
+}
@@ -0,0 +1,17 @@
+module github.com/nrdcg/auroradns/libdns
+
+go 1.24.0
+
+require (
+	github.com/libdns/libdns v1.1.1
+	github.com/nrdcg/auroradns v1.3.0
+	github.com/stretchr/testify v1.11.1
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/net v0.40.0 // indirect
+	golang.org/x/text v0.25.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
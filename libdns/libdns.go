@@ -0,0 +1,327 @@
+// Package libdns An adapter of auroradns.Client to the libdns interfaces (used by Caddy, CertMagic, ...).
+//
+//	provider := libdns.NewProvider(client)
+//
+//	records, err := provider.GetRecords(ctx, "example.com.")
+//
+// The names of the input records can be relative to the zone ("www", "@") or absolute, with a trailing dot ("www.example.com.").
+// The names of the output records are relative to the zone.
+//
+// SetRecords is not atomic: it stops at the first error, and the changes applied before are not reverted.
+// The SOA record and the apex NS records are managed by Aurora DNS: SetRecords does not change them, nor return them.
+package libdns
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/libdns/libdns"
+	"github.com/nrdcg/auroradns"
+)
+
+var (
+	_ libdns.RecordGetter   = (*Provider)(nil)
+	_ libdns.RecordAppender = (*Provider)(nil)
+	_ libdns.RecordSetter   = (*Provider)(nil)
+	_ libdns.RecordDeleter  = (*Provider)(nil)
+	_ libdns.ZoneLister     = (*Provider)(nil)
+)
+
+// Provider A libdns provider for Aurora DNS.
+type Provider struct {
+	client *auroradns.Client
+
+	// mu serializes the changes that read the records before changing them (SetRecords, DeleteRecords).
+	mu sync.Mutex
+}
+
+// NewProvider Creates a new Provider.
+func NewProvider(client *auroradns.Client) *Provider {
+	return &Provider{client: client}
+}
+
+// GetRecords Returns all the records of the zone.
+func (p *Provider) GetRecords(ctx context.Context, zone string) ([]libdns.Record, error) {
+	z, err := p.findZone(ctx, zone)
+	if err != nil {
+		return nil, err
+	}
+
+	records, _, err := p.client.ListRecordsWithContext(ctx, z.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the records of the zone %s: %w", z.Name, err)
+	}
+
+	results := make([]libdns.Record, 0, len(records))
+
+	for _, record := range records {
+		results = append(results, toLibdns(record, z.Name))
+	}
+
+	return results, nil
+}
+
+// AppendRecords Creates the records, and returns the created records.
+func (p *Provider) AppendRecords(ctx context.Context, zone string, recs []libdns.Record) ([]libdns.Record, error) {
+	z, err := p.findZone(ctx, zone)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := fromLibdns(recs, z.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	var created []libdns.Record
+
+	for _, record := range records {
+		newRecord, _, err := p.client.CreateRecordWithContext(ctx, z.ID, record)
+		if err != nil {
+			return created, fmt.Errorf("failed to create the record %s %q: %w", record.RecordType, record.Name, err)
+		}
+
+		created = append(created, toLibdns(*newRecord, z.Name))
+	}
+
+	return created, nil
+}
+
+// SetRecords Makes the input records the only records of their RRsets (name and type),
+// and returns the records that were set (without the SOA record and the apex NS records, which are not changed).
+// The records of the other RRsets are not changed.
+func (p *Provider) SetRecords(ctx context.Context, zone string, recs []libdns.Record) ([]libdns.Record, error) {
+	z, err := p.findZone(ctx, zone)
+	if err != nil {
+		return nil, err
+	}
+
+	desired, err := fromLibdns(recs, z.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	type rrsetKey struct{ name, recordType string }
+
+	rrsets := make(map[rrsetKey]bool)
+
+	for _, record := range desired {
+		rrsets[rrsetKey{name: record.Name, recordType: record.RecordType}] = true
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	records, _, err := p.client.ListRecordsWithContext(ctx, z.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the records of the zone %s: %w", z.Name, err)
+	}
+
+	// only the RRsets of the input are planned: the other records are not in the live records, so they are kept.
+	var live []auroradns.Record
+
+	for _, record := range records {
+		normalized := auroradns.NormalizeRecord(record, z.Name)
+
+		if rrsets[rrsetKey{name: normalized.Name, recordType: normalized.RecordType}] {
+			live = append(live, record)
+		}
+	}
+
+	err = p.client.ApplyPlan(ctx, auroradns.NewPlan(z, live, desired))
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]libdns.Record, 0, len(desired))
+
+	for _, record := range desired {
+		// the SOA record and the apex NS records are not planned.
+		if record.RecordType == auroradns.RecordTypeSOA || (record.RecordType == auroradns.RecordTypeNS && record.Name == "") {
+			continue
+		}
+
+		results = append(results, toLibdns(record, z.Name))
+	}
+
+	return results, nil
+}
+
+// DeleteRecords Deletes the records matching the input records, and returns the deleted records.
+// The empty type, TTL and data of an input record match any value.
+func (p *Provider) DeleteRecords(ctx context.Context, zone string, recs []libdns.Record) ([]libdns.Record, error) {
+	z, err := p.findZone(ctx, zone)
+	if err != nil {
+		return nil, err
+	}
+
+	patterns, err := fromLibdns(recs, z.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	records, _, err := p.client.ListRecordsWithContext(ctx, z.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the records of the zone %s: %w", z.Name, err)
+	}
+
+	var deleted []libdns.Record
+
+	for _, record := range records {
+		normalized := auroradns.NormalizeRecord(record, z.Name)
+
+		matched := false
+
+		for _, pattern := range patterns {
+			if matches(pattern, normalized) {
+				matched = true
+				break
+			}
+		}
+
+		if !matched {
+			continue
+		}
+
+		_, _, err := p.client.DeleteRecordWithContext(ctx, z.ID, record.ID)
+		if err != nil {
+			return deleted, fmt.Errorf("failed to delete the record %s %q: %w", record.RecordType, record.Name, err)
+		}
+
+		deleted = append(deleted, toLibdns(record, z.Name))
+	}
+
+	return deleted, nil
+}
+
+// ListZones Returns the zones, as fully qualified names.
+func (p *Provider) ListZones(ctx context.Context) ([]libdns.Zone, error) {
+	zones, _, err := p.client.ListZonesWithContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the zones: %w", err)
+	}
+
+	results := make([]libdns.Zone, 0, len(zones))
+
+	for _, zone := range zones {
+		results = append(results, libdns.Zone{Name: auroradns.NormalizeZoneName(zone.Name) + "."})
+	}
+
+	return results, nil
+}
+
+func (p *Provider) findZone(ctx context.Context, name string) (auroradns.Zone, error) {
+	zones, _, err := p.client.ListZonesWithContext(ctx)
+	if err != nil {
+		return auroradns.Zone{}, fmt.Errorf("failed to list the zones: %w", err)
+	}
+
+	name = auroradns.NormalizeZoneName(name)
+
+	for _, zone := range zones {
+		if auroradns.NormalizeZoneName(zone.Name) == name {
+			return zone, nil
+		}
+	}
+
+	return auroradns.Zone{}, fmt.Errorf("zone %s not found", name)
+}
+
+// matches Returns true if the normalized record matches the pattern: the empty type, TTL and content match any value.
+func matches(pattern, record auroradns.Record) bool {
+	switch {
+	case pattern.Name != record.Name:
+		return false
+	case pattern.RecordType != "" && pattern.RecordType != record.RecordType:
+		return false
+	case pattern.TTL != 0 && pattern.TTL != record.TTL:
+		return false
+	case pattern.Content != "" && auroradns.NormalizeContent(record.RecordType, pattern.Content) != record.Content:
+		return false
+	default:
+		return true
+	}
+}
+
+// toLibdns Converts a record to the libdns type of its record type (libdns.Address, libdns.TXT, ...).
+// The record types not supported by libdns are returned as libdns.RR.
+func toLibdns(record auroradns.Record, zone string) libdns.Record {
+	name := auroradns.NormalizeName(record.Name, zone)
+	if name == "" {
+		name = "@"
+	}
+
+	recordType := strings.ToUpper(record.RecordType)
+
+	data := record.Content
+	if recordType == auroradns.RecordTypeTXT {
+		// the libdns text is not quoted.
+		data = auroradns.NormalizeContent(recordType, data)
+	}
+
+	rr := libdns.RR{
+		Name: name,
+		TTL:  time.Duration(record.TTL) * time.Second,
+		Type: recordType,
+		Data: data,
+	}
+
+	parsed, err := rr.Parse()
+	if err != nil {
+		return rr
+	}
+
+	return parsed
+}
+
+// fromLibdns Converts the libdns records, with their names relative to the zone.
+func fromLibdns(recs []libdns.Record, zone string) ([]auroradns.Record, error) {
+	zone = auroradns.NormalizeZoneName(zone)
+
+	records := make([]auroradns.Record, 0, len(recs))
+
+	for _, rec := range recs {
+		rr := rec.RR()
+
+		name, err := relativeName(rr.Name, zone)
+		if err != nil {
+			return nil, err
+		}
+
+		records = append(records, auroradns.Record{
+			RecordType: strings.ToUpper(rr.Type),
+			Name:       name,
+			Content:    rr.Data,
+			TTL:        int(rr.TTL / time.Second),
+		})
+	}
+
+	return records, nil
+}
+
+// relativeName Returns the name relative to the zone ("" for the apex).
+// A name with a trailing dot is absolute, and must be in the zone.
+// A name without trailing dot is relative: "www.example.com" in the zone "example.com" is "www.example.com.example.com".
+func relativeName(name, zone string) (string, error) {
+	if name == "" {
+		return "", fmt.Errorf("missing record name in the zone %s", zone)
+	}
+
+	if !strings.HasSuffix(name, ".") {
+		return auroradns.NormalizeName(name, ""), nil
+	}
+
+	fqdn := auroradns.NormalizeZoneName(name)
+
+	if fqdn != zone && !strings.HasSuffix(fqdn, "."+zone) {
+		return "", fmt.Errorf("the name %s is not in the zone %s", name, zone)
+	}
+
+	return auroradns.NormalizeName(fqdn, zone), nil
+}
@@ -0,0 +1,194 @@
+package libdns
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/libdns/libdns"
+	"github.com/nrdcg/auroradns"
+	"github.com/nrdcg/auroradns/internal/fakeapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTest(t *testing.T) (*Provider, *fakeapi.Server, string) {
+	t.Helper()
+
+	api := fakeapi.NewServer("", "")
+	t.Cleanup(api.Close)
+
+	client, err := auroradns.NewClient(nil, auroradns.WithBaseURL(api.URL))
+	require.NoError(t, err)
+
+	zoneID := api.AddZone("example.com")
+
+	return NewProvider(client), api, zoneID
+}
+
+func TestProvider_GetRecords(t *testing.T) {
+	provider, api, zoneID := setupTest(t)
+
+	api.AddRecord(zoneID, fakeapi.Record{Type: "A", Name: "www", Content: "192.0.2.1", TTL: 300})
+	api.AddRecord(zoneID, fakeapi.Record{Type: "TXT", Name: "_acme-challenge", Content: `"abc"`, TTL: 60})
+	api.AddRecord(zoneID, fakeapi.Record{Type: "MX", Name: "", Content: "10 mx.example.com.", TTL: 3600})
+
+	records, err := provider.GetRecords(t.Context(), "example.com.")
+	require.NoError(t, err)
+
+	require.Len(t, records, 5)
+
+	assert.Equal(t, "@", records[0].RR().Name)
+	assert.Equal(t, "SOA", records[0].RR().Type)
+
+	assert.Equal(t, libdns.NS{Name: "@", TTL: 4800 * time.Second, Target: "ns1.auroradns.eu."}, records[1])
+	assert.Equal(t, libdns.Address{Name: "www", TTL: 300 * time.Second, IP: netip.MustParseAddr("192.0.2.1")}, records[2])
+	assert.Equal(t, libdns.TXT{Name: "_acme-challenge", TTL: time.Minute, Text: "abc"}, records[3])
+	assert.Equal(t, libdns.MX{Name: "@", TTL: time.Hour, Preference: 10, Target: "mx.example.com."}, records[4])
+
+	_, err = provider.GetRecords(t.Context(), "example.org.")
+	require.EqualError(t, err, "zone example.org not found")
+}
+
+func TestProvider_AppendRecords(t *testing.T) {
+	provider, api, zoneID := setupTest(t)
+
+	created, err := provider.AppendRecords(t.Context(), "example.com.", []libdns.Record{
+		libdns.Address{Name: "www", TTL: 5 * time.Minute, IP: netip.MustParseAddr("192.0.2.1")},
+		libdns.TXT{Name: "_acme-challenge.WWW.example.com.", TTL: time.Minute, Text: "abc"},
+		libdns.RR{Name: "@", Type: "CAA", Data: `0 issue "letsencrypt.org"`},
+	})
+	require.NoError(t, err)
+
+	expected := []libdns.Record{
+		libdns.Address{Name: "www", TTL: 5 * time.Minute, IP: netip.MustParseAddr("192.0.2.1")},
+		libdns.TXT{Name: "_acme-challenge.www", TTL: time.Minute, Text: "abc"},
+		libdns.CAA{Name: "@", Tag: "issue", Value: "letsencrypt.org"},
+	}
+	assert.Equal(t, expected, created)
+
+	assert.Equal(t, []fakeapi.Record{
+		{ID: "record-4", Type: "A", Name: "www", Content: "192.0.2.1", TTL: 300},
+		{ID: "record-5", Type: "TXT", Name: "_acme-challenge.www", Content: "abc", TTL: 60},
+		{ID: "record-6", Type: "CAA", Name: "", Content: `0 issue "letsencrypt.org"`},
+	}, api.Records(zoneID)[2:])
+
+	_, err = provider.AppendRecords(t.Context(), "example.com.", []libdns.Record{
+		libdns.Address{Name: "www.example.org.", IP: netip.MustParseAddr("192.0.2.1")},
+	})
+	require.EqualError(t, err, "the name www.example.org. is not in the zone example.com")
+
+	_, err = provider.AppendRecords(t.Context(), "example.com.", []libdns.Record{libdns.RR{Type: "A", Data: "192.0.2.1"}})
+	require.EqualError(t, err, "missing record name in the zone example.com")
+}
+
+func TestProvider_SetRecords(t *testing.T) {
+	provider, api, zoneID := setupTest(t)
+
+	api.AddRecord(zoneID, fakeapi.Record{Type: "A", Name: "", Content: "192.0.2.1", TTL: 3600})
+	api.AddRecord(zoneID, fakeapi.Record{Type: "A", Name: "", Content: "192.0.2.2", TTL: 3600})
+	api.AddRecord(zoneID, fakeapi.Record{Type: "TXT", Name: "", Content: "hello world", TTL: 3600})
+	api.AddRecord(zoneID, fakeapi.Record{Type: "AAAA", Name: "alpha", Content: "2001:db8::1", TTL: 3600})
+	api.AddRecord(zoneID, fakeapi.Record{Type: "AAAA", Name: "alpha", Content: "2001:db8::2", TTL: 3600})
+	api.AddRecord(zoneID, fakeapi.Record{Type: "AAAA", Name: "beta", Content: "2001:db8::3", TTL: 3600})
+
+	input := []libdns.Record{
+		// the RRset of the apex A records is replaced.
+		libdns.Address{Name: "@", TTL: time.Hour, IP: netip.MustParseAddr("192.0.2.3")},
+		// a record is added to the RRset of alpha.
+		libdns.Address{Name: "alpha", TTL: time.Hour, IP: netip.MustParseAddr("2001:db8::1")},
+		libdns.Address{Name: "alpha", TTL: time.Hour, IP: netip.MustParseAddr("2001:db8::2")},
+		libdns.Address{Name: "alpha.example.com.", TTL: time.Hour, IP: netip.MustParseAddr("2001:db8::5")},
+	}
+
+	set, err := provider.SetRecords(t.Context(), "example.com", input)
+	require.NoError(t, err)
+
+	assert.Len(t, set, 4)
+	assert.Equal(t, libdns.Address{Name: "alpha", TTL: time.Hour, IP: netip.MustParseAddr("2001:db8::5")}, set[3])
+
+	records, err := provider.GetRecords(t.Context(), "example.com.")
+	require.NoError(t, err)
+
+	var contents []string
+
+	for _, record := range records[2:] {
+		rr := record.RR()
+		contents = append(contents, rr.Name+" "+rr.Type+" "+rr.Data)
+	}
+
+	assert.ElementsMatch(t, []string{
+		"@ A 192.0.2.3",
+		"@ TXT hello world",
+		"alpha AAAA 2001:db8::1",
+		"alpha AAAA 2001:db8::2",
+		"alpha AAAA 2001:db8::5",
+		"beta AAAA 2001:db8::3",
+	}, contents)
+
+	mutations := len(api.Mutations())
+
+	// the records are already set.
+	_, err = provider.SetRecords(t.Context(), "example.com", input)
+	require.NoError(t, err)
+
+	assert.Len(t, api.Mutations(), mutations)
+}
+
+func TestProvider_SetRecords_apex(t *testing.T) {
+	provider, api, zoneID := setupTest(t)
+
+	input := []libdns.Record{
+		libdns.NS{Name: "@", TTL: time.Hour, Target: "ns1.example.net."},
+		libdns.RR{Name: "@", TTL: time.Hour, Type: "SOA", Data: "ns1.example.net. admin.example.net. 1 3600 600 86400 300"},
+		libdns.NS{Name: "lab", TTL: time.Hour, Target: "ns1.example.net."},
+	}
+
+	// the SOA record and the apex NS records are not changed, and not returned.
+	set, err := provider.SetRecords(t.Context(), "example.com", input)
+	require.NoError(t, err)
+
+	assert.Equal(t, []libdns.Record{libdns.NS{Name: "lab", TTL: time.Hour, Target: "ns1.example.net."}}, set)
+	assert.Equal(t, []string{"POST /zones/" + zoneID + "/records"}, api.Mutations())
+}
+
+func TestProvider_DeleteRecords(t *testing.T) {
+	provider, api, zoneID := setupTest(t)
+
+	api.AddRecord(zoneID, fakeapi.Record{Type: "TXT", Name: "_acme-challenge", Content: "abc", TTL: 60})
+	api.AddRecord(zoneID, fakeapi.Record{Type: "TXT", Name: "_acme-challenge", Content: "def", TTL: 60})
+	api.AddRecord(zoneID, fakeapi.Record{Type: "A", Name: "www", Content: "192.0.2.1", TTL: 300})
+	api.AddRecord(zoneID, fakeapi.Record{Type: "AAAA", Name: "www", Content: "2001:db8::1", TTL: 300})
+
+	deleted, err := provider.DeleteRecords(t.Context(), "example.com.", []libdns.Record{
+		// exact match.
+		libdns.TXT{Name: "_acme-challenge", TTL: time.Minute, Text: "abc"},
+		// no match: the TTL is different.
+		libdns.TXT{Name: "_acme-challenge", TTL: time.Hour, Text: "def"},
+		// the empty type, TTL and data match any value.
+		libdns.RR{Name: "www"},
+	})
+	require.NoError(t, err)
+
+	expected := []libdns.Record{
+		libdns.TXT{Name: "_acme-challenge", TTL: time.Minute, Text: "abc"},
+		libdns.Address{Name: "www", TTL: 5 * time.Minute, IP: netip.MustParseAddr("192.0.2.1")},
+		libdns.Address{Name: "www", TTL: 5 * time.Minute, IP: netip.MustParseAddr("2001:db8::1")},
+	}
+	assert.Equal(t, expected, deleted)
+
+	assert.Equal(t, []fakeapi.Record{
+		{ID: "record-5", Type: "TXT", Name: "_acme-challenge", Content: "def", TTL: 60},
+	}, api.Records(zoneID)[2:])
+}
+
+func TestProvider_ListZones(t *testing.T) {
+	provider, api, _ := setupTest(t)
+
+	api.AddZone("Example.org")
+
+	zones, err := provider.ListZones(t.Context())
+	require.NoError(t, err)
+
+	assert.Equal(t, []libdns.Zone{{Name: "example.com."}, {Name: "example.org."}}, zones)
+}
@@ -0,0 +1,341 @@
+package auroradns
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Severity The severity of a lint finding.
+type Severity int
+
+// Severities, in increasing order.
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityError
+)
+
+var severityNames = []string{"info", "warning", "error"}
+
+// String Returns the name of the severity.
+func (s Severity) String() string {
+	if s < 0 || int(s) >= len(severityNames) {
+		return fmt.Sprintf("Severity(%d)", int(s))
+	}
+
+	return severityNames[s]
+}
+
+// MarshalText Encodes the severity as its name.
+func (s Severity) MarshalText() ([]byte, error) {
+	if s < 0 || int(s) >= len(severityNames) {
+		return nil, fmt.Errorf("invalid severity: %d", int(s))
+	}
+
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText Decodes the severity from its name.
+func (s *Severity) UnmarshalText(text []byte) error {
+	i := slices.Index(severityNames, strings.ToLower(string(text)))
+	if i < 0 {
+		return fmt.Errorf("invalid severity: %q", text)
+	}
+
+	*s = Severity(i)
+
+	return nil
+}
+
+// Finding A problem found by a lint rule.
+type Finding struct {
+	// Rule the ID of the rule.
+	Rule string `json:"rule"`
+
+	Severity Severity `json:"severity"`
+
+	// Name the record name, relative to the zone ("" for the apex).
+	Name string `json:"name"`
+
+	// Type the record type, if the finding concerns a single type.
+	Type string `json:"type,omitempty"`
+
+	Message string `json:"message"`
+
+	// Records the records involved.
+	Records []Record `json:"records,omitempty"`
+}
+
+// String Returns a one-line description of the finding.
+func (f Finding) String() string {
+	return fmt.Sprintf("%s: %s: %s (%s)", f.Severity, displayName(f.Name), f.Message, f.Rule)
+}
+
+// LintRule A check of the records of a zone.
+type LintRule interface {
+	// ID Returns the identifier of the rule (e.g. "cname-apex").
+	ID() string
+
+	// Check Returns the problems found in the records.
+	// The names, types and contents of the records are normalized, and the names are relative to the zone.
+	Check(zone string, records []Record) []Finding
+}
+
+// LintRuleFunc A LintRule implemented by a function.
+type LintRuleFunc struct {
+	Name string
+	Func func(zone string, records []Record) []Finding
+}
+
+// ID Returns the name of the rule.
+func (r LintRuleFunc) ID() string {
+	return r.Name
+}
+
+// Check Calls the function.
+func (r LintRuleFunc) Check(zone string, records []Record) []Finding {
+	return r.Func(zone, records)
+}
+
+// DefaultLintRules Returns the built-in rules:
+//   - cname-coexistence: a CNAME record with other records at the same name (error).
+//   - cname-apex: a CNAME record at the apex (error).
+//   - target-cname: an MX or SRV target that is a CNAME record of the zone (error).
+//   - multiple-spf: several SPF TXT records at the same name (error).
+//   - ttl-mismatch: records with different TTLs inside an RRset (warning).
+func DefaultLintRules() []LintRule {
+	return []LintRule{
+		LintRuleFunc{Name: "cname-coexistence", Func: lintCNAMECoexistence},
+		LintRuleFunc{Name: "cname-apex", Func: lintCNAMEApex},
+		LintRuleFunc{Name: "target-cname", Func: lintTargetCNAME},
+		LintRuleFunc{Name: "multiple-spf", Func: lintMultipleSPF},
+		LintRuleFunc{Name: "ttl-mismatch", Func: lintTTLMismatch},
+	}
+}
+
+// Lint Checks the records of a zone (e.g. the result of ListRecordsWithContext, or a desired state).
+// The names can be relative to the zone or absolute (see NormalizeName).
+//
+// The records are checked with the rules, or with DefaultLintRules if no rule is given.
+// The findings are sorted by decreasing severity, then by name and type.
+// The Rule field of the findings is set to the ID of the rule if empty.
+func Lint(records []Record, zoneName string, rules ...LintRule) []Finding {
+	if len(rules) == 0 {
+		rules = DefaultLintRules()
+	}
+
+	zone := NormalizeZoneName(zoneName)
+
+	normalized := make([]Record, len(records))
+	for i, record := range records {
+		normalized[i] = NormalizeRecord(record, zone)
+	}
+
+	var findings []Finding
+
+	for _, rule := range rules {
+		for _, finding := range rule.Check(zone, slices.Clone(normalized)) {
+			if finding.Rule == "" {
+				finding.Rule = rule.ID()
+			}
+
+			findings = append(findings, finding)
+		}
+	}
+
+	slices.SortStableFunc(findings, func(a, b Finding) int {
+		return cmp.Or(
+			cmp.Compare(b.Severity, a.Severity),
+			cmp.Compare(a.Name, b.Name),
+			cmp.Compare(a.Type, b.Type),
+		)
+	})
+
+	return findings
+}
+
+// MaxSeverity Returns the highest severity of the findings, and false if there are no findings.
+func MaxSeverity(findings []Finding) (Severity, bool) {
+	if len(findings) == 0 {
+		return SeverityInfo, false
+	}
+
+	highest := findings[0].Severity
+
+	for _, finding := range findings[1:] {
+		highest = max(highest, finding.Severity)
+	}
+
+	return highest, true
+}
+
+func lintCNAMECoexistence(_ string, records []Record) []Finding {
+	var findings []Finding
+
+	for name, set := range groupByName(records) {
+		var cnames, others []Record
+
+		for _, record := range set {
+			if record.RecordType == RecordTypeCNAME {
+				cnames = append(cnames, record)
+			} else {
+				others = append(others, record)
+			}
+		}
+
+		switch {
+		case len(cnames) == 0:
+			continue
+
+		case len(others) > 0:
+			findings = append(findings, Finding{
+				Severity: SeverityError,
+				Name:     name,
+				Type:     RecordTypeCNAME,
+				Message:  fmt.Sprintf("CNAME record coexists with %d other records", len(others)),
+				Records:  set,
+			})
+
+		case len(cnames) > 1:
+			findings = append(findings, Finding{
+				Severity: SeverityError,
+				Name:     name,
+				Type:     RecordTypeCNAME,
+				Message:  fmt.Sprintf("%d CNAME records at the same name", len(cnames)),
+				Records:  cnames,
+			})
+		}
+	}
+
+	return findings
+}
+
+func lintCNAMEApex(_ string, records []Record) []Finding {
+	var findings []Finding
+
+	for _, record := range records {
+		if record.Name == "" && record.RecordType == RecordTypeCNAME {
+			findings = append(findings, Finding{
+				Severity: SeverityError,
+				Type:     RecordTypeCNAME,
+				Message:  "CNAME record at the apex",
+				Records:  []Record{record},
+			})
+		}
+	}
+
+	return findings
+}
+
+func lintTargetCNAME(zone string, records []Record) []Finding {
+	cnames := make(map[string]bool)
+
+	for _, record := range records {
+		if record.RecordType == RecordTypeCNAME {
+			cnames[record.Name] = true
+		}
+	}
+
+	var findings []Finding
+
+	for _, record := range records {
+		if record.RecordType != RecordTypeMX && record.RecordType != RecordTypeSRV {
+			continue
+		}
+
+		fields := strings.Fields(record.Content)
+		if len(fields) == 0 {
+			continue
+		}
+
+		target := NormalizeName(fields[len(fields)-1], zone)
+		if !cnames[target] {
+			continue
+		}
+
+		findings = append(findings, Finding{
+			Severity: SeverityError,
+			Name:     record.Name,
+			Type:     record.RecordType,
+			Message:  fmt.Sprintf("%s target %s is a CNAME record", record.RecordType, displayName(target)),
+			Records:  []Record{record},
+		})
+	}
+
+	return findings
+}
+
+func lintMultipleSPF(_ string, records []Record) []Finding {
+	var findings []Finding
+
+	for name, set := range groupByName(records) {
+		spf := slices.DeleteFunc(set, func(record Record) bool {
+			return record.RecordType != RecordTypeTXT || !isSPF(record.Content)
+		})
+
+		if len(spf) > 1 {
+			findings = append(findings, Finding{
+				Severity: SeverityError,
+				Name:     name,
+				Type:     RecordTypeTXT,
+				Message:  fmt.Sprintf("%d SPF records at the same name", len(spf)),
+				Records:  spf,
+			})
+		}
+	}
+
+	return findings
+}
+
+func lintTTLMismatch(_ string, records []Record) []Finding {
+	sets := make(map[recordKey][]Record)
+
+	for _, record := range records {
+		key := recordKey{name: record.Name, recordType: record.RecordType}
+		sets[key] = append(sets[key], record)
+	}
+
+	var findings []Finding
+
+	for key, set := range sets {
+		ttls := make([]int, 0, len(set))
+		for _, record := range set {
+			ttls = append(ttls, record.TTL)
+		}
+
+		slices.Sort(ttls)
+		ttls = slices.Compact(ttls)
+
+		if len(ttls) < 2 {
+			continue
+		}
+
+		findings = append(findings, Finding{
+			Severity: SeverityWarning,
+			Name:     key.name,
+			Type:     key.recordType,
+			Message:  fmt.Sprintf("different TTLs in the RRset: %v", ttls),
+			Records:  set,
+		})
+	}
+
+	return findings
+}
+
+func groupByName(records []Record) map[string][]Record {
+	sets := make(map[string][]Record)
+
+	for _, record := range records {
+		sets[record.Name] = append(sets[record.Name], record)
+	}
+
+	return sets
+}
+
+func isSPF(content string) bool {
+	content = strings.ToLower(content)
+
+	return content == "v=spf1" || strings.HasPrefix(content, "v=spf1 ")
+}
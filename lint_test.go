@@ -0,0 +1,161 @@
+package auroradns
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLint(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		records  []Record
+		expected []string
+	}{
+		{
+			desc: "valid zone",
+			records: []Record{
+				{RecordType: RecordTypeA, Name: "", Content: "192.0.2.1", TTL: 300},
+				{RecordType: RecordTypeMX, Name: "", Content: "10 mail.example.com.", TTL: 300},
+				{RecordType: RecordTypeA, Name: "mail", Content: "192.0.2.2", TTL: 300},
+				{RecordType: RecordTypeCNAME, Name: "www", Content: "example.com.", TTL: 300},
+				{RecordType: RecordTypeTXT, Name: "", Content: "v=spf1 mx -all", TTL: 300},
+				{RecordType: RecordTypeTXT, Name: "", Content: "google-site-verification=abc", TTL: 300},
+			},
+		},
+		{
+			desc: "CNAME with other data",
+			records: []Record{
+				{RecordType: RecordTypeCNAME, Name: "www", Content: "example.net", TTL: 300},
+				{RecordType: RecordTypeTXT, Name: "WWW.example.com.", Content: "foo", TTL: 300},
+			},
+			expected: []string{"cname-coexistence"},
+		},
+		{
+			desc: "several CNAME",
+			records: []Record{
+				{RecordType: RecordTypeCNAME, Name: "www", Content: "example.net", TTL: 300},
+				{RecordType: RecordTypeCNAME, Name: "www", Content: "example.org", TTL: 300},
+			},
+			expected: []string{"cname-coexistence"},
+		},
+		{
+			desc: "CNAME at the apex",
+			records: []Record{
+				{RecordType: RecordTypeCNAME, Name: "@", Content: "example.net", TTL: 300},
+			},
+			expected: []string{"cname-apex"},
+		},
+		{
+			desc: "MX target is a CNAME",
+			records: []Record{
+				{RecordType: RecordTypeMX, Name: "", Content: "10 mail.example.com", TTL: 300},
+				{RecordType: RecordTypeCNAME, Name: "mail", Content: "mx.example.net", TTL: 300},
+			},
+			expected: []string{"target-cname"},
+		},
+		{
+			desc: "multiple SPF",
+			records: []Record{
+				{RecordType: RecordTypeTXT, Name: "", Content: "v=spf1 mx -all", TTL: 300},
+				{RecordType: RecordTypeTXT, Name: "", Content: `"V=SPF1 include:example.net ~all"`, TTL: 300},
+			},
+			expected: []string{"multiple-spf"},
+		},
+		{
+			desc: "TTL mismatch",
+			records: []Record{
+				{RecordType: RecordTypeA, Name: "www", Content: "192.0.2.1", TTL: 300},
+				{RecordType: RecordTypeA, Name: "www", Content: "192.0.2.2", TTL: 600},
+			},
+			expected: []string{"ttl-mismatch"},
+		},
+		{
+			desc: "sorted by severity",
+			records: []Record{
+				{RecordType: RecordTypeA, Name: "a", Content: "192.0.2.1", TTL: 300},
+				{RecordType: RecordTypeA, Name: "a", Content: "192.0.2.2", TTL: 600},
+				{RecordType: RecordTypeCNAME, Name: "b", Content: "example.net", TTL: 300},
+				{RecordType: RecordTypeTXT, Name: "b", Content: "foo", TTL: 300},
+			},
+			expected: []string{"cname-coexistence", "ttl-mismatch"},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			findings := Lint(test.records, "example.com")
+
+			var rules []string
+			for _, finding := range findings {
+				rules = append(rules, finding.Rule)
+			}
+
+			assert.Equal(t, test.expected, rules)
+		})
+	}
+}
+
+func TestLint_customRule(t *testing.T) {
+	rule := LintRuleFunc{
+		Name: "no-low-ttl",
+		Func: func(_ string, records []Record) []Finding {
+			var findings []Finding
+
+			for _, record := range records {
+				if record.TTL < 60 {
+					findings = append(findings, Finding{Severity: SeverityInfo, Name: record.Name, Type: record.RecordType, Message: "low TTL"})
+				}
+			}
+
+			return findings
+		},
+	}
+
+	records := []Record{
+		{RecordType: RecordTypeA, Name: "www.example.com.", Content: "192.0.2.1", TTL: 30},
+		{RecordType: RecordTypeA, Name: "api", Content: "192.0.2.1", TTL: 300},
+	}
+
+	findings := Lint(records, "example.com", rule)
+
+	expected := []Finding{
+		{Rule: "no-low-ttl", Severity: SeverityInfo, Name: "www", Type: RecordTypeA, Message: "low TTL"},
+	}
+
+	assert.Equal(t, expected, findings)
+}
+
+func TestFinding_json(t *testing.T) {
+	finding := Finding{
+		Rule:     "cname-apex",
+		Severity: SeverityError,
+		Type:     RecordTypeCNAME,
+		Message:  "CNAME record at the apex",
+	}
+
+	raw, err := json.Marshal(finding)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{"rule":"cname-apex","severity":"error","name":"","type":"CNAME","message":"CNAME record at the apex"}`, string(raw))
+
+	var decoded Finding
+
+	err = json.Unmarshal(raw, &decoded)
+	require.NoError(t, err)
+
+	assert.Equal(t, finding, decoded)
+
+	assert.Equal(t, "error: @: CNAME record at the apex (cname-apex)", finding.String())
+}
+
+func TestMaxSeverity(t *testing.T) {
+	_, ok := MaxSeverity(nil)
+	assert.False(t, ok)
+
+	severity, ok := MaxSeverity([]Finding{{Severity: SeverityInfo}, {Severity: SeverityWarning}})
+	require.True(t, ok)
+	assert.Equal(t, SeverityWarning, severity)
+}
@@ -0,0 +1,192 @@
+package auroradns
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+const redacted = "REDACTED"
+
+// sensitiveHeaders the headers never logged in clear text.
+var sensitiveHeaders = []string{authorizationHeader, "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+// sensitiveFields the JSON fields never logged in clear text (case-insensitive).
+var sensitiveFields = []string{"secret", "api_key", "apikey", "password", "token", "authorization"}
+
+// WithLogger Logs each call: operation, method, path, status, latency and error code.
+// At debug level, the headers and the bodies of the request and the response are also logged.
+//
+// The Authorization header and the secrets are always redacted.
+// The logger is a middleware (see WithMiddleware), slog.Default is used if logger is nil.
+func WithLogger(logger *slog.Logger) Option {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return WithMiddleware(loggingMiddleware(logger))
+}
+
+func loggingMiddleware(logger *slog.Logger) Middleware {
+	return func(next Handler) Handler {
+		return func(call *Call) (*http.Response, error) {
+			ctx := call.Request.Context()
+
+			debug := logger.Enabled(ctx, slog.LevelDebug)
+
+			attrs := []slog.Attr{
+				slog.String("operation", call.Operation),
+				slog.String("method", call.Request.Method),
+				slog.String("path", call.Request.URL.Path),
+			}
+
+			if call.ZoneID != "" {
+				attrs = append(attrs, slog.String("zone_id", call.ZoneID))
+			}
+
+			if call.RecordID != "" {
+				attrs = append(attrs, slog.String("record_id", call.RecordID))
+			}
+
+			if debug {
+				logger.LogAttrs(ctx, slog.LevelDebug, "auroradns request",
+					append(slices.Clone(attrs),
+						slog.Any("headers", redactHeaders(call.Request.Header)),
+						slog.String("body", requestBody(call.Request)),
+					)...)
+			}
+
+			start := time.Now()
+
+			resp, err := next(call)
+
+			attrs = append(attrs, slog.Duration("latency", time.Since(start)))
+
+			if resp != nil {
+				attrs = append(attrs, slog.Int("status", resp.StatusCode))
+
+				if debug {
+					logger.LogAttrs(ctx, slog.LevelDebug, "auroradns response",
+						append(slices.Clone(attrs),
+							slog.Any("headers", redactHeaders(resp.Header)),
+							slog.String("body", responseBody(resp)),
+						)...)
+				}
+			}
+
+			if err != nil {
+				var apiErr *ResponseError
+				if errors.As(err, &apiErr) {
+					attrs = append(attrs, slog.String("error_code", apiErr.ErrorCode))
+				}
+
+				logger.LogAttrs(ctx, slog.LevelError, "auroradns call failed", append(attrs, slog.Any("error", err))...)
+
+				return resp, err
+			}
+
+			logger.LogAttrs(ctx, slog.LevelInfo, "auroradns call", attrs...)
+
+			return resp, nil
+		}
+	}
+}
+
+// requestBody returns the redacted body of the request, without consuming it.
+func requestBody(req *http.Request) string {
+	raw, err := readRequestBody(req)
+	if err != nil {
+		return ""
+	}
+
+	return redactBody(raw)
+}
+
+// responseBody returns the redacted body of the response, and restores it.
+func responseBody(resp *http.Response) string {
+	if resp.Body == nil {
+		return ""
+	}
+
+	raw, err := io.ReadAll(resp.Body)
+
+	_ = resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(raw))
+
+	if err != nil {
+		return ""
+	}
+
+	return redactBody(raw)
+}
+
+func redactHeaders(header http.Header) http.Header {
+	clone := header.Clone()
+
+	for _, key := range sensitiveHeaders {
+		if clone.Get(key) != "" {
+			clone.Set(key, redacted)
+		}
+	}
+
+	return clone
+}
+
+// redactBody replaces the values of the sensitive fields of a JSON body.
+// A body that is not JSON cannot be redacted: only its length is returned.
+func redactBody(raw []byte) string {
+	if len(raw) == 0 {
+		return ""
+	}
+
+	var data any
+
+	err := json.Unmarshal(raw, &data)
+	if err != nil {
+		return fmt.Sprintf("%s (%d bytes)", redacted, len(raw))
+	}
+
+	if !redactValue(data) {
+		return string(raw)
+	}
+
+	redactedBody, err := json.Marshal(data)
+	if err != nil {
+		return redacted
+	}
+
+	return string(redactedBody)
+}
+
+// redactValue redacts the sensitive fields of a decoded JSON value, it returns true if something was redacted.
+func redactValue(value any) bool {
+	var found bool
+
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			if slices.Contains(sensitiveFields, strings.ToLower(key)) {
+				v[key] = redacted
+				found = true
+
+				continue
+			}
+
+			found = redactValue(field) || found
+		}
+
+	case []any:
+		for _, item := range v {
+			found = redactValue(item) || found
+		}
+	}
+
+	return found
+}
@@ -0,0 +1,168 @@
+package auroradns
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readLogs(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+
+	var logs []map[string]any
+
+	decoder := json.NewDecoder(buf)
+
+	for decoder.More() {
+		entry := make(map[string]any)
+
+		err := decoder.Decode(&entry)
+		require.NoError(t, err)
+
+		delete(entry, "time")
+		delete(entry, "latency")
+
+		logs = append(logs, entry)
+	}
+
+	return logs
+}
+
+func TestWithLogger(t *testing.T) {
+	buf := new(bytes.Buffer)
+	logger := slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelInfo}))
+
+	client, mux := setupTest(t, WithLogger(logger))
+
+	handleAPI(mux, "/zones/identifier-zone-1/records", http.MethodGet, func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`[]`))
+	})
+
+	_, _, err := client.ListRecordsWithContext(t.Context(), "identifier-zone-1")
+	require.NoError(t, err)
+
+	expected := []map[string]any{{
+		"level":     "INFO",
+		"msg":       "auroradns call",
+		"operation": "ListRecords",
+		"method":    "GET",
+		"path":      "/zones/identifier-zone-1/records",
+		"zone_id":   "identifier-zone-1",
+		"status":    float64(200),
+	}}
+	assert.Equal(t, expected, readLogs(t, buf))
+}
+
+func TestWithLogger_error(t *testing.T) {
+	buf := new(bytes.Buffer)
+	logger := slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelInfo}))
+
+	client, mux := setupTest(t, WithLogger(logger))
+
+	handleAPI(mux, "/zones/identifier-zone-1", http.MethodDelete, func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+
+		_, _ = fmt.Fprint(w, `{"error":"AuthenticationRequiredError","errormsg":"Failed to parse Authorization header"}`)
+	})
+
+	_, _, err := client.DeleteZoneWithContext(t.Context(), "identifier-zone-1")
+	require.Error(t, err)
+
+	expected := []map[string]any{{
+		"level":      "ERROR",
+		"msg":        "auroradns call failed",
+		"operation":  "DeleteZone",
+		"method":     "DELETE",
+		"path":       "/zones/identifier-zone-1",
+		"zone_id":    "identifier-zone-1",
+		"status":     float64(401),
+		"error_code": "AuthenticationRequiredError",
+		"error":      "AuthenticationRequiredError - Failed to parse Authorization header",
+	}}
+	assert.Equal(t, expected, readLogs(t, buf))
+}
+
+func TestWithLogger_debug(t *testing.T) {
+	buf := new(bytes.Buffer)
+	logger := slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	authorize := func(next Handler) Handler {
+		return func(call *Call) (*http.Response, error) {
+			call.Request.Header.Set("Authorization", "AuroraDNSv1 secret-token")
+
+			return next(call)
+		}
+	}
+
+	client, mux := setupTest(t, WithMiddleware(authorize), WithLogger(logger))
+
+	handleAPI(mux, "/zones", http.MethodPost, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != `{"name":"example.com"}` {
+			http.Error(w, "invalid body", http.StatusBadRequest)
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"id":"identifier-zone-1","name":"example.com"}`))
+	})
+
+	zone, _, err := client.CreateZoneWithContext(t.Context(), "example.com")
+	require.NoError(t, err)
+
+	assert.Equal(t, &Zone{ID: "identifier-zone-1", Name: "example.com"}, zone)
+
+	logs := readLogs(t, buf)
+	require.Len(t, logs, 3)
+
+	assert.Equal(t, "auroradns request", logs[0]["msg"])
+	assert.JSONEq(t, `{"name":"example.com"}`, logs[0]["body"].(string))
+	assert.Equal(t, []any{"REDACTED"}, logs[0]["headers"].(map[string]any)["Authorization"])
+
+	assert.Equal(t, "auroradns response", logs[1]["msg"])
+	assert.JSONEq(t, `{"id":"identifier-zone-1","name":"example.com"}`, logs[1]["body"].(string))
+
+	assert.Equal(t, "auroradns call", logs[2]["msg"])
+	assert.NotContains(t, buf.String(), "secret-token")
+}
+
+func TestRedactBody(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		body     string
+		expected string
+	}{
+		{
+			desc:     "no secret",
+			body:     `{"name":"example.com"}`,
+			expected: `{"name":"example.com"}`,
+		},
+		{
+			desc:     "nested secrets",
+			body:     `{"name":"example.com","Secret":"s3cr3t","items":[{"api_key":"key"}]}`,
+			expected: `{"Secret":"REDACTED","items":[{"api_key":"REDACTED"}],"name":"example.com"}`,
+		},
+		{
+			desc:     "not JSON",
+			body:     `invalid secret=s3cr3t`,
+			expected: `REDACTED (21 bytes)`,
+		},
+		{
+			desc:     "empty",
+			body:     ``,
+			expected: ``,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			assert.Equal(t, test.expected, redactBody([]byte(test.body)))
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package auroradns
+
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
+// Metrics Receives the measurements of the client.
+// The implementations must be safe for concurrent use.
+type Metrics interface {
+	// ObserveLatency records the duration of a call, retries included.
+	ObserveLatency(operation string, duration time.Duration)
+
+	// CountResponse counts a finished call.
+	// The status code is 0 if no response was received,
+	// the error code is the code of the ResponseError if any.
+	CountResponse(operation string, statusCode int, errorCode string)
+
+	// CountRetry counts a retried request.
+	CountRetry(operation string)
+
+	// ObserveRateLimitWait records the time spent waiting for the rate limiter before sending a request.
+	ObserveRateLimitWait(operation string, duration time.Duration)
+
+	// AddInFlight adds delta to the number of calls in progress.
+	AddInFlight(operation string, delta int)
+}
+
+// WithMetrics Reports the latency, the responses, the retries, the rate limit waits and the calls in progress.
+// The latency, the responses and the calls in progress are measured by a middleware (see WithMiddleware).
+func WithMetrics(metrics Metrics) Option {
+	return func(client *Client) error {
+		if metrics == nil {
+			return errors.New("metrics is nil")
+		}
+
+		client.metrics = metrics
+
+		return WithMiddleware(metricsMiddleware(metrics))(client)
+	}
+}
+
+func metricsMiddleware(metrics Metrics) Middleware {
+	return func(next Handler) Handler {
+		return func(call *Call) (*http.Response, error) {
+			metrics.AddInFlight(call.Operation, 1)
+			defer metrics.AddInFlight(call.Operation, -1)
+
+			start := time.Now()
+
+			resp, err := next(call)
+
+			metrics.ObserveLatency(call.Operation, time.Since(start))
+
+			var statusCode int
+			if resp != nil {
+				statusCode = resp.StatusCode
+			}
+
+			var errorCode string
+
+			var apiErr *ResponseError
+			if errors.As(err, &apiErr) {
+				errorCode = apiErr.ErrorCode
+			}
+
+			metrics.CountResponse(call.Operation, statusCode, errorCode)
+
+			return resp, err
+		}
+	}
+}
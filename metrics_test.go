@@ -0,0 +1,86 @@
+package auroradns
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeMetrics struct {
+	mu sync.Mutex
+
+	events []string
+}
+
+func (f *fakeMetrics) ObserveLatency(operation string, _ time.Duration) {
+	f.add("latency " + operation)
+}
+
+func (f *fakeMetrics) CountResponse(operation string, statusCode int, errorCode string) {
+	f.add(fmt.Sprintf("response %s %d %q", operation, statusCode, errorCode))
+}
+
+func (f *fakeMetrics) CountRetry(operation string) {
+	f.add("retry " + operation)
+}
+
+func (f *fakeMetrics) ObserveRateLimitWait(operation string, _ time.Duration) {
+	f.add("wait " + operation)
+}
+
+func (f *fakeMetrics) AddInFlight(operation string, delta int) {
+	f.add(fmt.Sprintf("in-flight %s %+d", operation, delta))
+}
+
+func (f *fakeMetrics) add(event string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.events = append(f.events, event)
+}
+
+func TestWithMetrics(t *testing.T) {
+	metrics := &fakeMetrics{}
+
+	client, mux := setupTest(t, WithMetrics(metrics), WithRetry(1, time.Millisecond), WithRateLimit(1000, 10))
+
+	var calls int
+
+	handleAPI(mux, "/zones/identifier-zone-1", http.MethodDelete, func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+
+		if calls == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"error":"TooManyRequestsError","errormsg":"slow down"}`))
+
+			return
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error":"NotFoundError","errormsg":"zone not found"}`))
+	})
+
+	_, _, err := client.DeleteZoneWithContext(t.Context(), "identifier-zone-1")
+	require.Error(t, err)
+
+	expected := []string{
+		"in-flight DeleteZone +1",
+		"wait DeleteZone",
+		"retry DeleteZone",
+		"wait DeleteZone",
+		"latency DeleteZone",
+		`response DeleteZone 404 "NotFoundError"`,
+		"in-flight DeleteZone -1",
+	}
+	assert.Equal(t, expected, metrics.events)
+}
+
+func TestWithMetrics_nil(t *testing.T) {
+	_, err := NewClient(nil, WithMetrics(nil))
+	require.EqualError(t, err, "metrics is nil")
+}
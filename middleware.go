@@ -0,0 +1,70 @@
+package auroradns
+
+import "net/http"
+
+// Operation names.
+const (
+	OperationCreateZone        = "CreateZone"
+	OperationDeleteZone        = "DeleteZone"
+	OperationListZones         = "ListZones"
+	OperationCreateRecord      = "CreateRecord"
+	OperationUpdateRecord      = "UpdateRecord"
+	OperationDeleteRecord      = "DeleteRecord"
+	OperationListRecords       = "ListRecords"
+	OperationCreateHealthCheck = "CreateHealthCheck"
+	OperationListHealthChecks  = "ListHealthChecks"
+)
+
+// Call An API operation executed by the client.
+type Call struct {
+	// Operation the name of the operation (e.g. "CreateRecord").
+	Operation string
+
+	// ZoneID the zone targeted by the operation, if any.
+	ZoneID string
+
+	// RecordID the record targeted by the operation, if any.
+	RecordID string
+
+	// RecordType the type of the record targeted by the operation, if known.
+	RecordType string
+
+	// Request the HTTP request of the operation.
+	Request *http.Request
+
+	// Retries the number of times the request has been retried, set by the client.
+	Retries int
+}
+
+// Handler Executes a call.
+//
+// A non-2xx response is returned along with a *ResponseError.
+// The client reads and closes the body of the response: a handler must not close it.
+type Handler func(call *Call) (*http.Response, error)
+
+// Middleware Wraps a Handler.
+//
+// A middleware can modify the call before calling next,
+// inspect or replace the response and the error returned by next,
+// or short-circuit the call by returning without calling next.
+type Middleware func(next Handler) Handler
+
+// WithMiddleware Registers middlewares.
+//
+// The middlewares are called in the order of registration, across all the options registering middlewares:
+// the first middleware receives the call first and the response last.
+func WithMiddleware(middlewares ...Middleware) Option {
+	return func(client *Client) error {
+		client.middlewares = append(client.middlewares, middlewares...)
+
+		return nil
+	}
+}
+
+func chain(handler Handler, middlewares []Middleware) Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+
+	return handler
+}
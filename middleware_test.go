@@ -0,0 +1,151 @@
+package auroradns
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithMiddleware_order(t *testing.T) {
+	var trace []string
+
+	record := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(call *Call) (*http.Response, error) {
+				trace = append(trace, name+" before "+call.Operation+" "+call.ZoneID+" "+call.RecordID)
+
+				resp, err := next(call)
+
+				trace = append(trace, name+" after "+resp.Status)
+
+				return resp, err
+			}
+		}
+	}
+
+	client, mux := setupTest(t,
+		WithMiddleware(record("first"), record("second")),
+		WithMiddleware(record("third")),
+	)
+
+	handleAPI(mux, "/zones/identifier-zone-1/records/identifier-record-1", http.MethodDelete, nil)
+
+	_, _, err := client.DeleteRecordWithContext(t.Context(), "identifier-zone-1", "identifier-record-1")
+	require.NoError(t, err)
+
+	expected := []string{
+		"first before DeleteRecord identifier-zone-1 identifier-record-1",
+		"second before DeleteRecord identifier-zone-1 identifier-record-1",
+		"third before DeleteRecord identifier-zone-1 identifier-record-1",
+		"third after 200 OK",
+		"second after 200 OK",
+		"first after 200 OK",
+	}
+	assert.Equal(t, expected, trace)
+}
+
+func TestWithMiddleware_modify_request(t *testing.T) {
+	injectHeader := func(next Handler) Handler {
+		return func(call *Call) (*http.Response, error) {
+			call.Request.Header.Set("X-Request-Id", "123")
+
+			return next(call)
+		}
+	}
+
+	client, mux := setupTest(t, WithMiddleware(injectHeader))
+
+	handleAPI(mux, "/zones", http.MethodGet, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Request-Id") != "123" {
+			http.Error(w, "missing X-Request-Id", http.StatusBadRequest)
+			return
+		}
+
+		_, _ = w.Write([]byte(`[]`))
+	})
+
+	zones, _, err := client.ListZonesWithContext(t.Context())
+	require.NoError(t, err)
+
+	assert.Empty(t, zones)
+}
+
+func TestWithMiddleware_short_circuit(t *testing.T) {
+	fake := func(Handler) Handler {
+		return func(*Call) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Status:     "200 OK",
+				Header:     make(http.Header),
+				Body:       io.NopCloser(strings.NewReader(`[{"id":"identifier-zone-1","name":"example.com"}]`)),
+			}, nil
+		}
+	}
+
+	client, mux := setupTest(t, WithMiddleware(fake))
+
+	handleAPI(mux, "/zones", http.MethodGet, func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "unexpected call", http.StatusInternalServerError)
+	})
+
+	zones, resp, err := client.ListZonesWithContext(t.Context())
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []Zone{{ID: "identifier-zone-1", Name: "example.com"}}, zones)
+}
+
+func TestWithMiddleware_no_response(t *testing.T) {
+	noResponse := func(Handler) Handler {
+		return func(*Call) (*http.Response, error) {
+			return nil, nil
+		}
+	}
+
+	client, _ := setupTest(t, WithMiddleware(noResponse))
+
+	zones, resp, err := client.ListZonesWithContext(t.Context())
+	require.EqualError(t, err, "ListZones: the middlewares returned neither a response nor an error")
+
+	assert.Nil(t, resp)
+	assert.Nil(t, zones)
+}
+
+func TestWithMiddleware_error(t *testing.T) {
+	var apiErr *ResponseError
+
+	var body string
+
+	observe := func(next Handler) Handler {
+		return func(call *Call) (*http.Response, error) {
+			resp, err := next(call)
+
+			errors.As(err, &apiErr)
+
+			raw, _ := io.ReadAll(resp.Body)
+			body = string(raw)
+
+			return resp, err
+		}
+	}
+
+	client, mux := setupTest(t, WithMiddleware(observe))
+
+	handleAPI(mux, "/zones/identifier-zone-1", http.MethodDelete, func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error":"NotFoundError","errormsg":"zone not found"}`))
+	})
+
+	_, _, err := client.DeleteZoneWithContext(t.Context(), "identifier-zone-1")
+	require.EqualError(t, err, "NotFoundError - zone not found")
+
+	require.NotNil(t, apiErr)
+	assert.Equal(t, "NotFoundError", apiErr.ErrorCode)
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+	assert.JSONEq(t, `{"error":"NotFoundError","errormsg":"zone not found"}`, body)
+}
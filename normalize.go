@@ -0,0 +1,182 @@
+package auroradns
+
+import (
+	"errors"
+	"net/netip"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/idna"
+)
+
+// NormalizeName Returns the canonical form of a record name, relative to the zone.
+//
+// The name is lowercased, the trailing dot is removed and the internationalized labels are converted to their ASCII form (xn--).
+// An invalid internationalized label is only lowercased: use ToASCII to validate a name.
+// The apex ("@", "" or the zone name) is returned as "".
+// A name under the zone (e.g. "www.example.com." in the zone "example.com") is returned relative to the zone ("www").
+func NormalizeName(name, zone string) string {
+	name = normalizeDomain(name)
+	if name == "@" {
+		return ""
+	}
+
+	zone = normalizeDomain(zone)
+	if zone == "" {
+		return name
+	}
+
+	if name == zone {
+		return ""
+	}
+
+	if relative, ok := strings.CutSuffix(name, "."+zone); ok {
+		return relative
+	}
+
+	return name
+}
+
+// NormalizeZoneName Returns the canonical form of a zone name:
+// lowercased, without the trailing dot, and with the internationalized labels converted to their ASCII form (xn--).
+func NormalizeZoneName(zone string) string {
+	return normalizeDomain(zone)
+}
+
+// NormalizeContent Returns the canonical form of the content of a record.
+//
+//   - A, AAAA: the address in its canonical form (e.g. "2001:db8::1").
+//   - CNAME, NS, PTR: the target normalized like a zone name.
+//   - MX, SRV: the target (last field) normalized like a zone name, the other fields separated by a single space.
+//   - TXT: the text without the zone file quoting (`"foo" "bar"` becomes `foobar`).
+//   - other types: the content without the leading and trailing spaces.
+func NormalizeContent(recordType, content string) string {
+	content = strings.TrimSpace(content)
+
+	switch strings.ToUpper(recordType) {
+	case RecordTypeA, RecordTypeAAAA:
+		addr, err := netip.ParseAddr(content)
+		if err != nil {
+			return content
+		}
+
+		return addr.Unmap().String()
+
+	case RecordTypeCNAME, RecordTypeNS, RecordTypePTR:
+		return normalizeDomain(content)
+
+	case RecordTypeMX, RecordTypeSRV:
+		fields := strings.Fields(content)
+		if len(fields) == 0 {
+			return ""
+		}
+
+		fields[len(fields)-1] = normalizeDomain(fields[len(fields)-1])
+
+		return strings.Join(fields, " ")
+
+	case RecordTypeTXT:
+		return unquoteTXT(content)
+
+	default:
+		return content
+	}
+}
+
+// NormalizeRecord Returns a copy of the record with its name, type and content normalized.
+func NormalizeRecord(record Record, zone string) Record {
+	record.RecordType = strings.ToUpper(record.RecordType)
+	record.Name = NormalizeName(record.Name, zone)
+	record.Content = NormalizeContent(record.RecordType, record.Content)
+
+	return record
+}
+
+// ToASCII Returns the ASCII form of a domain name:
+// lowercased, without the trailing dot, and with the internationalized labels converted to their ASCII form (xn--).
+// It returns an error if an internationalized label is not a valid IDNA label.
+func ToASCII(name string) (string, error) {
+	name = strings.TrimSuffix(strings.TrimSpace(name), ".")
+
+	labels := strings.Split(name, ".")
+
+	var errs []error
+
+	for i, label := range labels {
+		label = strings.ToLower(label)
+
+		ascii, err := toASCIILabel(label)
+		if err != nil {
+			errs = append(errs, err)
+			ascii = label
+		}
+
+		labels[i] = ascii
+	}
+
+	return strings.Join(labels, "."), errors.Join(errs...)
+}
+
+// normalizeDomain lowercases a domain name, removes the trailing dot, and converts the internationalized labels.
+// The invalid internationalized labels are only lowercased (see ToASCII).
+func normalizeDomain(name string) string {
+	name, _ = ToASCII(name)
+
+	return name
+}
+
+// unquoteTXT removes the zone file quoting of a TXT content.
+// A content that is not entirely made of quoted strings is returned as is.
+func unquoteTXT(content string) string {
+	if !strings.HasPrefix(content, `"`) {
+		return content
+	}
+
+	var (
+		sb      strings.Builder
+		quoted  bool
+		escaped bool
+	)
+
+	for _, r := range content {
+		switch {
+		case escaped:
+			sb.WriteRune(r)
+
+			escaped = false
+
+		case quoted && r == '\\':
+			escaped = true
+
+		case r == '"':
+			quoted = !quoted
+
+		case quoted:
+			sb.WriteRune(r)
+
+		case r == ' ' || r == '\t':
+			// separator between strings
+
+		default:
+			return content
+		}
+	}
+
+	if quoted || escaped {
+		return content
+	}
+
+	return sb.String()
+}
+
+// toASCIILabel converts an internationalized label to its ASCII form (see idna.Lookup).
+// The ASCII labels are returned as is: the underscore labels (e.g. "_acme-challenge") are not valid IDNA labels.
+func toASCIILabel(label string) (string, error) {
+	for i := range len(label) {
+		if label[i] >= utf8.RuneSelf {
+			return idna.Lookup.ToASCII(label)
+		}
+	}
+
+	return label, nil
+}
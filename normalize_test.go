@@ -0,0 +1,89 @@
+package auroradns
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeName(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		name     string
+		zone     string
+		expected string
+	}{
+		{desc: "relative", name: "www", zone: "example.com", expected: "www"},
+		{desc: "case", name: "WWW", zone: "example.com", expected: "www"},
+		{desc: "trailing dot", name: "www.example.com.", zone: "Example.COM.", expected: "www"},
+		{desc: "absolute", name: "_acme-challenge.www.example.com", zone: "example.com", expected: "_acme-challenge.www"},
+		{desc: "apex @", name: "@", zone: "example.com", expected: ""},
+		{desc: "apex empty", name: "", zone: "example.com", expected: ""},
+		{desc: "apex zone name", name: "example.com.", zone: "example.com", expected: ""},
+		{desc: "apex without zone", name: "@", expected: ""},
+		{desc: "other zone", name: "www.example.org", zone: "example.com", expected: "www.example.org"},
+		{desc: "not on a label boundary", name: "fooexample.com", zone: "example.com", expected: "fooexample.com"},
+		{desc: "IDN", name: "Bücher", zone: "example.com", expected: "xn--bcher-kva"},
+		{desc: "IDN zone", name: "www.münchen.de.", zone: "xn--mnchen-3ya.de", expected: "www"},
+		{desc: "IDN without ASCII", name: "日本語", expected: "xn--wgv71a119e"},
+		{desc: "already encoded", name: "XN--BCHER-KVA", expected: "xn--bcher-kva"},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			assert.Equal(t, test.expected, NormalizeName(test.name, test.zone))
+		})
+	}
+}
+
+func TestToASCII(t *testing.T) {
+	name, err := ToASCII("_acme-challenge.Bücher.example.com.")
+	require.NoError(t, err)
+
+	assert.Equal(t, "_acme-challenge.xn--bcher-kva.example.com", name)
+
+	name, err = ToASCII("www.bücher-.example.com")
+	require.EqualError(t, err, `idna: invalid label "bücher-"`)
+
+	assert.Equal(t, "www.bücher-.example.com", name)
+	assert.Equal(t, "www.bücher-", NormalizeName(name, "example.com"))
+}
+
+func TestNormalizeContent(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		recordType string
+		content    string
+		expected   string
+	}{
+		{desc: "IPv4", recordType: RecordTypeA, content: " 192.0.2.1 ", expected: "192.0.2.1"},
+		{desc: "IPv6 expanded", recordType: RecordTypeAAAA, content: "2001:0DB8:0000:0000:0000:0000:0000:0001", expected: "2001:db8::1"},
+		{desc: "IPv6 compressed", recordType: RecordTypeAAAA, content: "2001:db8::1", expected: "2001:db8::1"},
+		{desc: "invalid address", recordType: RecordTypeA, content: "foo", expected: "foo"},
+		{desc: "CNAME", recordType: RecordTypeCNAME, content: "Target.Example.com.", expected: "target.example.com"},
+		{desc: "IDN CNAME", recordType: RecordTypeCNAME, content: "münchen.de.", expected: "xn--mnchen-3ya.de"},
+		{desc: "MX", recordType: RecordTypeMX, content: "10   Mail.Example.com.", expected: "10 mail.example.com"},
+		{desc: "MX without priority", recordType: RecordTypeMX, content: "Mail.Example.com.", expected: "mail.example.com"},
+		{desc: "SRV", recordType: RecordTypeSRV, content: "5 5060 SIP.example.com.", expected: "5 5060 sip.example.com"},
+		{desc: "TXT", recordType: RecordTypeTXT, content: "v=spf1 -all", expected: "v=spf1 -all"},
+		{desc: "TXT quoted", recordType: RecordTypeTXT, content: `"v=spf1 -all"`, expected: "v=spf1 -all"},
+		{desc: "TXT multiple strings", recordType: RecordTypeTXT, content: `"v=DKIM1; k=rsa; " "p=MIGf"`, expected: "v=DKIM1; k=rsa; p=MIGf"},
+		{desc: "TXT escaped quote", recordType: RecordTypeTXT, content: `"say \"hi\""`, expected: `say "hi"`},
+		{desc: "TXT not only quoted strings", recordType: RecordTypeTXT, content: `"foo" bar`, expected: `"foo" bar`},
+		{desc: "lower case type", recordType: "aaaa", content: "2001:db8:0::1", expected: "2001:db8::1"},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			assert.Equal(t, test.expected, NormalizeContent(test.recordType, test.content))
+		})
+	}
+}
+
+func TestNormalizeRecord(t *testing.T) {
+	a := NormalizeRecord(Record{ID: "aaa", RecordType: "aaaa", Name: "WWW.example.com.", Content: "2001:0db8::0001", TTL: 300}, "example.com")
+	b := NormalizeRecord(Record{ID: "aaa", RecordType: RecordTypeAAAA, Name: "www", Content: "2001:db8::1", TTL: 300}, "example.com")
+
+	assert.Equal(t, a, b)
+}
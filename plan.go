@@ -0,0 +1,99 @@
+package auroradns
+
+import (
+	"context"
+	"fmt"
+)
+
+// Plan The changes needed to bring the records of a zone to a desired state.
+type Plan struct {
+	ZoneID   string      `json:"zone_id"`
+	ZoneName string      `json:"zone_name"`
+	Diff     *RecordDiff `json:"diff"`
+}
+
+// NewPlan Computes the changes needed to go from the live records of a zone to the desired records.
+// The names of the desired records can be relative to the zone or absolute (see NormalizeName).
+//
+// The apex NS and SOA records are managed by the API: they are not part of the plan.
+func NewPlan(zone Zone, live, desired []Record) *Plan {
+	return &Plan{
+		ZoneID:   zone.ID,
+		ZoneName: zone.Name,
+		Diff:     diffZone(zone, live, desired),
+	}
+}
+
+// ApplyPlan Applies the changes of a plan: the records are deleted, then updated, then created.
+// It stops at the first error: the changes applied before are not reverted.
+func (c *Client) ApplyPlan(ctx context.Context, plan *Plan) error {
+	return c.applyDiff(ctx, plan.ZoneID, plan.Diff)
+}
+
+// planZone Computes the changes needed to bring the records of a zone to the desired records.
+func (c *Client) planZone(ctx context.Context, zone Zone, desired []Record) (*RecordDiff, error) {
+	live, _, err := c.ListRecordsWithContext(ctx, zone.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return diffZone(zone, live, desired), nil
+}
+
+// diffZone Computes the changes needed to go from the live records of a zone to the desired records.
+// The apex NS and SOA records are managed by the API: they are not part of the changes.
+func diffZone(zone Zone, live, desired []Record) *RecordDiff {
+	return DiffZoneRecords(zone.Name, withoutApexNSorSOA(zone.Name, live), withoutApexNSorSOA(zone.Name, desired))
+}
+
+// applyDiff Applies the changes to the records of a zone: the records are deleted, then updated, then created.
+// It stops at the first error: the changes applied before are not reverted.
+func (c *Client) applyDiff(ctx context.Context, zoneID string, diff *RecordDiff) error {
+	for _, record := range diff.Removed {
+		_, _, err := c.DeleteRecordWithContext(ctx, zoneID, record.ID)
+		if err != nil {
+			return fmt.Errorf("failed to delete the record %s %q: %w", record.RecordType, record.Name, err)
+		}
+	}
+
+	for _, change := range diff.Changed {
+		record := change.New
+
+		// the unspecified fields keep their live values.
+		if record.TTL == 0 {
+			record.TTL = change.Old.TTL
+		}
+
+		if record.HealthCheckID == "" {
+			record.HealthCheckID = change.Old.HealthCheckID
+		}
+
+		_, _, err := c.UpdateRecordWithContext(ctx, zoneID, change.Old.ID, record)
+		if err != nil {
+			return fmt.Errorf("failed to update the record %s %q: %w", record.RecordType, record.Name, err)
+		}
+	}
+
+	for _, record := range diff.Added {
+		record.ID = ""
+
+		_, _, err := c.CreateRecordWithContext(ctx, zoneID, record)
+		if err != nil {
+			return fmt.Errorf("failed to create the record %s %q: %w", record.RecordType, record.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func withoutApexNSorSOA(zone string, records []Record) []Record {
+	var filtered []Record
+
+	for _, record := range records {
+		if !isApexNSorSOA(NormalizeRecord(record, zone)) {
+			filtered = append(filtered, record)
+		}
+	}
+
+	return filtered
+}
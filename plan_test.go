@@ -0,0 +1,109 @@
+package auroradns
+
+import (
+	"testing"
+
+	"github.com/nrdcg/auroradns/internal/fakeapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_diffZone(t *testing.T) {
+	zone := Zone{ID: "identifier-zone-1", Name: "example.com"}
+
+	live := []Record{
+		{ID: "1", RecordType: RecordTypeSOA, Name: "", Content: "ns1.example.com admin.example.com 1 86400 7200 604800 300"},
+		{ID: "2", RecordType: RecordTypeNS, Name: "", Content: "ns1.example.com"},
+		{ID: "3", RecordType: RecordTypeA, Name: "www", Content: "192.0.2.1", TTL: 300},
+	}
+
+	desired := []Record{
+		{RecordType: RecordTypeNS, Name: "example.com.", Content: "ns2.example.com"},
+		{RecordType: RecordTypeA, Name: "www.example.com.", Content: "192.0.2.1", TTL: 300},
+	}
+
+	assert.True(t, diffZone(zone, live, desired).IsEmpty())
+}
+
+func TestNewPlan(t *testing.T) {
+	zone := Zone{ID: "identifier-zone-1", Name: "example.com"}
+
+	live := []Record{
+		{ID: "1", RecordType: RecordTypeNS, Name: "", Content: "ns1.example.com"},
+		{ID: "2", RecordType: RecordTypeA, Name: "www", Content: "192.0.2.1", TTL: 300},
+	}
+
+	desired := []Record{
+		{RecordType: RecordTypeA, Name: "www.example.com.", Content: "192.0.2.2", TTL: 300},
+	}
+
+	plan := NewPlan(zone, live, desired)
+
+	assert.Equal(t, "identifier-zone-1", plan.ZoneID)
+	assert.Equal(t, "example.com", plan.ZoneName)
+	assert.Equal(t, "~ A www.example.com. content: 192.0.2.1 -> 192.0.2.2\n", plan.Diff.String())
+}
+
+func TestClient_ApplyPlan(t *testing.T) {
+	client, server := setupFakeAPI(t)
+
+	zoneID := server.AddZone("example.com")
+	server.AddRecord(zoneID, fakeapi.Record{Type: "A", Name: "www", Content: "192.0.2.1", TTL: 300})
+
+	zone := Zone{ID: zoneID, Name: "example.com"}
+
+	live, _, err := client.ListRecordsWithContext(t.Context(), zoneID)
+	require.NoError(t, err)
+
+	plan := NewPlan(zone, live, []Record{{RecordType: RecordTypeA, Name: "www", Content: "192.0.2.2"}})
+
+	err = client.ApplyPlan(t.Context(), plan)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"PUT /zones/zone-1/records/record-4"}, server.Mutations())
+
+	records := server.Records(zoneID)
+	require.Len(t, records, 3)
+	assert.Equal(t, fakeapi.Record{ID: "record-4", Type: "A", Name: "www", Content: "192.0.2.2", TTL: 300}, records[2])
+}
+
+func TestClient_applyDiff(t *testing.T) {
+	client, server := setupFakeAPI(t)
+
+	zoneID := server.AddZone("example.com")
+	server.AddRecord(zoneID, fakeapi.Record{Type: "A", Name: "www", Content: "192.0.2.1", TTL: 300})
+	server.AddRecord(zoneID, fakeapi.Record{Type: "A", Name: "api", Content: "192.0.2.1", TTL: 300})
+	server.AddRecord(zoneID, fakeapi.Record{Type: "TXT", Name: "old", Content: "foo", TTL: 300})
+
+	desired := []Record{
+		{RecordType: RecordTypeA, Name: "www", Content: "192.0.2.1", TTL: 300},
+		{RecordType: RecordTypeA, Name: "api", Content: "192.0.2.2"},
+		{RecordType: RecordTypeTXT, Name: "new", Content: "bar", TTL: 600},
+	}
+
+	zone := Zone{ID: zoneID, Name: "example.com"}
+
+	diff, err := client.planZone(t.Context(), zone, desired)
+	require.NoError(t, err)
+
+	assert.Equal(t, "- TXT old \"foo\" ttl=300\n~ A api content: 192.0.2.1 -> 192.0.2.2\n+ TXT new \"bar\" ttl=600\n", diff.String())
+
+	err = client.applyDiff(t.Context(), zoneID, diff)
+	require.NoError(t, err)
+
+	expected := []string{
+		"DELETE /zones/zone-1/records/record-6",
+		"PUT /zones/zone-1/records/record-5",
+		"POST /zones/zone-1/records",
+	}
+	assert.Equal(t, expected, server.Mutations())
+
+	diff, err = client.planZone(t.Context(), zone, desired)
+	require.NoError(t, err)
+
+	assert.True(t, diff.IsEmpty())
+
+	records := server.Records(zoneID)
+	require.Len(t, records, 5)
+	assert.Equal(t, fakeapi.Record{ID: "record-5", Type: "A", Name: "api", Content: "192.0.2.2", TTL: 300}, records[3])
+}
@@ -0,0 +1,268 @@
+// Package prometheus exposes the metrics of an auroradns.Client in the Prometheus text format.
+//
+// The package has no dependencies:
+//
+//	metrics := prometheus.NewMetrics()
+//
+//	client, err := auroradns.NewClient(httpClient, auroradns.WithMetrics(metrics))
+//
+//	http.Handle("/metrics", metrics)
+package prometheus
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nrdcg/auroradns"
+)
+
+const contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefaultBuckets the default upper bounds (in seconds) of the histogram buckets.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+var _ auroradns.Metrics = (*Metrics)(nil)
+
+type responseKey struct {
+	operation string
+	status    int
+	errorCode string
+}
+
+// Metrics Collects the metrics of a client and serves them in the Prometheus text format.
+type Metrics struct {
+	mu sync.Mutex
+
+	buckets []float64
+
+	latency       map[string]*histogram
+	responses     map[responseKey]uint64
+	retries       map[string]uint64
+	rateLimitWait map[string]*histogram
+	inFlight      map[string]int64
+}
+
+// NewMetrics Creates a new Metrics.
+// The histograms use DefaultBuckets if no buckets are provided.
+func NewMetrics(buckets ...float64) *Metrics {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+
+	buckets = slices.Clone(buckets)
+	slices.Sort(buckets)
+
+	return &Metrics{
+		buckets:       buckets,
+		latency:       make(map[string]*histogram),
+		responses:     make(map[responseKey]uint64),
+		retries:       make(map[string]uint64),
+		rateLimitWait: make(map[string]*histogram),
+		inFlight:      make(map[string]int64),
+	}
+}
+
+// ObserveLatency implements auroradns.Metrics.
+func (m *Metrics) ObserveLatency(operation string, duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.histogram(m.latency, operation).observe(duration.Seconds())
+}
+
+// CountResponse implements auroradns.Metrics.
+func (m *Metrics) CountResponse(operation string, statusCode int, errorCode string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.responses[responseKey{operation: operation, status: statusCode, errorCode: errorCode}]++
+}
+
+// CountRetry implements auroradns.Metrics.
+func (m *Metrics) CountRetry(operation string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.retries[operation]++
+}
+
+// ObserveRateLimitWait implements auroradns.Metrics.
+func (m *Metrics) ObserveRateLimitWait(operation string, duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.histogram(m.rateLimitWait, operation).observe(duration.Seconds())
+}
+
+// AddInFlight implements auroradns.Metrics.
+func (m *Metrics) AddInFlight(operation string, delta int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.inFlight[operation] += int64(delta)
+}
+
+// ServeHTTP Writes the metrics in the Prometheus text format.
+func (m *Metrics) ServeHTTP(rw http.ResponseWriter, _ *http.Request) {
+	rw.Header().Set("Content-Type", contentType)
+
+	_, _ = m.WriteTo(rw)
+}
+
+// WriteTo Writes the metrics in the Prometheus text format.
+// The output is sorted by metric and labels.
+// The metrics are copied before writing: a slow client does not block the updates of the metrics.
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	snap := m.snapshot()
+
+	cw := &countWriter{w: bufio.NewWriter(w)}
+
+	writeHistograms(cw, "auroradns_request_duration_seconds", "Duration of the API calls, retries included.", snap.latency)
+
+	cw.header("auroradns_responses_total", "Number of finished API calls by status code and error code.", "counter")
+
+	keys := slices.SortedFunc(maps.Keys(snap.responses), func(a, b responseKey) int {
+		return cmp.Or(
+			cmp.Compare(a.operation, b.operation),
+			cmp.Compare(a.status, b.status),
+			cmp.Compare(a.errorCode, b.errorCode),
+		)
+	})
+
+	for _, key := range keys {
+		cw.printf("auroradns_responses_total{operation=%s,status=%s,error_code=%s} %d\n",
+			quote(key.operation), quote(strconv.Itoa(key.status)), quote(key.errorCode), snap.responses[key])
+	}
+
+	cw.header("auroradns_retries_total", "Number of retried requests.", "counter")
+
+	for _, operation := range slices.Sorted(maps.Keys(snap.retries)) {
+		cw.printf("auroradns_retries_total{operation=%s} %d\n", quote(operation), snap.retries[operation])
+	}
+
+	writeHistograms(cw, "auroradns_rate_limit_wait_seconds", "Time spent waiting for the rate limiter.", snap.rateLimitWait)
+
+	cw.header("auroradns_requests_in_flight", "Number of API calls in progress.", "gauge")
+
+	for _, operation := range slices.Sorted(maps.Keys(snap.inFlight)) {
+		cw.printf("auroradns_requests_in_flight{operation=%s} %d\n", quote(operation), snap.inFlight[operation])
+	}
+
+	if cw.err == nil {
+		cw.err = cw.w.Flush()
+	}
+
+	return cw.n, cw.err
+}
+
+// snapshot Returns a copy of the metrics.
+func (m *Metrics) snapshot() *Metrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return &Metrics{
+		buckets:       m.buckets,
+		latency:       cloneHistograms(m.latency),
+		responses:     maps.Clone(m.responses),
+		retries:       maps.Clone(m.retries),
+		rateLimitWait: cloneHistograms(m.rateLimitWait),
+		inFlight:      maps.Clone(m.inFlight),
+	}
+}
+
+func (m *Metrics) histogram(histograms map[string]*histogram, operation string) *histogram {
+	h, ok := histograms[operation]
+	if !ok {
+		h = &histogram{buckets: m.buckets, counts: make([]uint64, len(m.buckets))}
+		histograms[operation] = h
+	}
+
+	return h
+}
+
+type histogram struct {
+	buckets []float64
+	counts  []uint64 // non-cumulative counts
+	count   uint64
+	sum     float64
+}
+
+func (h *histogram) observe(value float64) {
+	h.count++
+	h.sum += value
+
+	for i, bound := range h.buckets {
+		if value <= bound {
+			h.counts[i]++
+			return
+		}
+	}
+}
+
+func cloneHistograms(histograms map[string]*histogram) map[string]*histogram {
+	clone := make(map[string]*histogram, len(histograms))
+
+	for operation, h := range histograms {
+		clone[operation] = &histogram{buckets: h.buckets, counts: slices.Clone(h.counts), count: h.count, sum: h.sum}
+	}
+
+	return clone
+}
+
+func writeHistograms(cw *countWriter, name, help string, histograms map[string]*histogram) {
+	cw.header(name, help, "histogram")
+
+	for _, operation := range slices.Sorted(maps.Keys(histograms)) {
+		h := histograms[operation]
+
+		var cumulative uint64
+
+		for i, bound := range h.buckets {
+			cumulative += h.counts[i]
+
+			cw.printf("%s_bucket{operation=%s,le=%s} %d\n",
+				name, quote(operation), quote(strconv.FormatFloat(bound, 'g', -1, 64)), cumulative)
+		}
+
+		cw.printf("%s_bucket{operation=%s,le=\"+Inf\"} %d\n", name, quote(operation), h.count)
+		cw.printf("%s_sum{operation=%s} %s\n", name, quote(operation), strconv.FormatFloat(h.sum, 'g', -1, 64))
+		cw.printf("%s_count{operation=%s} %d\n", name, quote(operation), h.count)
+	}
+}
+
+var labelReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// quote quotes a label value.
+func quote(value string) string {
+	return `"` + labelReplacer.Replace(value) + `"`
+}
+
+// countWriter keeps the first error and the number of bytes written.
+type countWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (cw *countWriter) header(name, help, kind string) {
+	cw.printf("# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func (cw *countWriter) printf(format string, args ...any) {
+	if cw.err != nil {
+		return
+	}
+
+	n, err := fmt.Fprintf(cw.w, format, args...)
+	cw.n += int64(n)
+	cw.err = err
+}
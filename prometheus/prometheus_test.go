@@ -0,0 +1,150 @@
+package prometheus
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/nrdcg/auroradns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics_ServeHTTP(t *testing.T) {
+	metrics := NewMetrics(0.1, 1)
+
+	metrics.ObserveLatency(auroradns.OperationListZones, 50*time.Millisecond)
+	metrics.ObserveLatency(auroradns.OperationListZones, 2*time.Second)
+	metrics.ObserveLatency(auroradns.OperationCreateRecord, 500*time.Millisecond)
+	metrics.CountResponse(auroradns.OperationListZones, http.StatusOK, "")
+	metrics.CountResponse(auroradns.OperationListZones, http.StatusOK, "")
+	metrics.CountResponse(auroradns.OperationCreateRecord, http.StatusUnauthorized, "AuthenticationRequiredError")
+	metrics.CountRetry(auroradns.OperationListZones)
+	metrics.ObserveRateLimitWait(auroradns.OperationListZones, 0)
+	metrics.AddInFlight(auroradns.OperationListZones, 1)
+	metrics.AddInFlight(auroradns.OperationListZones, 1)
+	metrics.AddInFlight(auroradns.OperationListZones, -1)
+
+	recorder := httptest.NewRecorder()
+
+	metrics.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", http.NoBody))
+
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", recorder.Header().Get("Content-Type"))
+
+	expected := `# HELP auroradns_request_duration_seconds Duration of the API calls, retries included.
+# TYPE auroradns_request_duration_seconds histogram
+auroradns_request_duration_seconds_bucket{operation="CreateRecord",le="0.1"} 0
+auroradns_request_duration_seconds_bucket{operation="CreateRecord",le="1"} 1
+auroradns_request_duration_seconds_bucket{operation="CreateRecord",le="+Inf"} 1
+auroradns_request_duration_seconds_sum{operation="CreateRecord"} 0.5
+auroradns_request_duration_seconds_count{operation="CreateRecord"} 1
+auroradns_request_duration_seconds_bucket{operation="ListZones",le="0.1"} 1
+auroradns_request_duration_seconds_bucket{operation="ListZones",le="1"} 1
+auroradns_request_duration_seconds_bucket{operation="ListZones",le="+Inf"} 2
+auroradns_request_duration_seconds_sum{operation="ListZones"} 2.05
+auroradns_request_duration_seconds_count{operation="ListZones"} 2
+# HELP auroradns_responses_total Number of finished API calls by status code and error code.
+# TYPE auroradns_responses_total counter
+auroradns_responses_total{operation="CreateRecord",status="401",error_code="AuthenticationRequiredError"} 1
+auroradns_responses_total{operation="ListZones",status="200",error_code=""} 2
+# HELP auroradns_retries_total Number of retried requests.
+# TYPE auroradns_retries_total counter
+auroradns_retries_total{operation="ListZones"} 1
+# HELP auroradns_rate_limit_wait_seconds Time spent waiting for the rate limiter.
+# TYPE auroradns_rate_limit_wait_seconds histogram
+auroradns_rate_limit_wait_seconds_bucket{operation="ListZones",le="0.1"} 1
+auroradns_rate_limit_wait_seconds_bucket{operation="ListZones",le="1"} 1
+auroradns_rate_limit_wait_seconds_bucket{operation="ListZones",le="+Inf"} 1
+auroradns_rate_limit_wait_seconds_sum{operation="ListZones"} 0
+auroradns_rate_limit_wait_seconds_count{operation="ListZones"} 1
+# HELP auroradns_requests_in_flight Number of API calls in progress.
+# TYPE auroradns_requests_in_flight gauge
+auroradns_requests_in_flight{operation="ListZones"} 1
+`
+	assert.Equal(t, expected, recorder.Body.String())
+}
+
+func TestMetrics_client(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`[{"id":"identifier-zone-1","name":"example.com"}]`))
+	}))
+	t.Cleanup(server.Close)
+
+	metrics := NewMetrics()
+
+	client, err := auroradns.NewClient(nil, auroradns.WithBaseURL(server.URL), auroradns.WithMetrics(metrics))
+	require.NoError(t, err)
+
+	_, _, err = client.ListZonesWithContext(t.Context())
+	require.NoError(t, err)
+
+	metricsServer := httptest.NewServer(metrics)
+	t.Cleanup(metricsServer.Close)
+
+	resp, err := http.Get(metricsServer.URL)
+	require.NoError(t, err)
+
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	assert.Contains(t, string(body), `auroradns_request_duration_seconds_count{operation="ListZones"} 1`)
+	assert.Contains(t, string(body), `auroradns_responses_total{operation="ListZones",status="200",error_code=""} 1`)
+	assert.Contains(t, string(body), `auroradns_requests_in_flight{operation="ListZones"} 0`)
+}
+
+// blockingWriter blocks the first write until released.
+type blockingWriter struct {
+	writing chan struct{}
+	release chan struct{}
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	select {
+	case <-w.writing:
+	default:
+		close(w.writing)
+		<-w.release
+	}
+
+	return len(p), nil
+}
+
+func TestMetrics_WriteTo_slowWriter(t *testing.T) {
+	metrics := NewMetrics()
+
+	writer := &blockingWriter{writing: make(chan struct{}), release: make(chan struct{})}
+
+	written := make(chan struct{})
+
+	go func() {
+		_, _ = metrics.WriteTo(writer)
+
+		close(written)
+	}()
+
+	<-writer.writing
+
+	// the metrics are updated while the scrape is blocked.
+	updated := make(chan struct{})
+
+	go func() {
+		metrics.CountRetry(auroradns.OperationListZones)
+
+		close(updated)
+	}()
+
+	select {
+	case <-updated:
+	case <-time.After(time.Second):
+		t.Error("the update is blocked by the scrape")
+	}
+
+	close(writer.release)
+
+	<-written
+	<-updated
+}
@@ -0,0 +1,524 @@
+// Package propagation Checks that the authoritative name servers of a zone serve the records.
+//
+// The name servers are the apex NS records of the zone in the API (or the NS records served by the resolvers),
+// and each of them is queried directly, without recursion:
+//
+//	checker := propagation.NewChecker(client, nil)
+//
+//	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+//	defer cancel()
+//
+//	report, err := checker.WaitForPropagation(ctx, zone, propagation.RRset{Name: "www", Type: "A", Contents: []string{"192.0.2.1"}})
+package propagation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/nrdcg/auroradns"
+)
+
+// Default values of the configuration.
+const (
+	DefaultPort            = "53"
+	DefaultTimeout         = 5 * time.Second
+	DefaultInitialInterval = time.Second
+	DefaultMaxInterval     = 30 * time.Second
+)
+
+// Config the configuration of a Checker.
+type Config struct {
+	// Resolvers the recursive resolvers ("host:port") used to resolve the names of the name servers,
+	// and to find the NS records of a zone without client.
+	// By default, the resolvers of /etc/resolv.conf.
+	Resolvers []string
+
+	// Nameservers the name servers ("host:port") to query, instead of the name servers of the zone.
+	Nameservers []string
+
+	// Port of the name servers of the zone (DefaultPort by default).
+	Port string
+
+	// Net the protocol of the queries: "udp" (by default, with a retry over TCP for the truncated responses) or "tcp".
+	Net string
+
+	// Timeout of a query (DefaultTimeout by default).
+	Timeout time.Duration
+
+	// InitialInterval and MaxInterval bound the exponential backoff of WaitForPropagation
+	// (DefaultInitialInterval and DefaultMaxInterval by default).
+	InitialInterval time.Duration
+	MaxInterval     time.Duration
+}
+
+// Checker Queries the authoritative name servers of the zones.
+type Checker struct {
+	client *auroradns.Client
+	config Config
+	dns    *dns.Client
+}
+
+// Nameserver An authoritative name server.
+type Nameserver struct {
+	// Name the host name of the server, empty for the servers of Config.Nameservers.
+	Name string `json:"name,omitempty"`
+
+	// Address the address of the server ("host:port").
+	Address string `json:"address"`
+}
+
+// String Returns the name and the address of the server.
+func (n Nameserver) String() string {
+	if n.Name == "" {
+		return n.Address
+	}
+
+	return n.Name + " (" + n.Address + ")"
+}
+
+// RRset The expected records of a name and a type.
+type RRset struct {
+	// Name the name, relative to the zone ("" or "@" for the apex) or fully qualified.
+	Name string
+
+	// Type the record type.
+	Type string
+
+	// Contents the expected contents, in the format of Record.Content (compared with auroradns.NormalizeContent).
+	// Without contents, the name servers must not serve any record of this name and type.
+	Contents []string
+
+	// Exact the name servers must serve exactly these contents.
+	// Otherwise, the other contents are accepted (e.g. several ACME challenges for the same name).
+	Exact bool
+}
+
+// ServerStatus The state of the RRset on a name server.
+type ServerStatus struct {
+	Nameserver Nameserver `json:"nameserver"`
+	Propagated bool       `json:"propagated"`
+
+	// Contents the contents served by the server.
+	Contents []string `json:"contents,omitempty"`
+
+	// Missing the expected contents not served.
+	Missing []string `json:"missing,omitempty"`
+
+	// Unexpected the contents served but not expected (with RRset.Exact, or when no content is expected).
+	Unexpected []string `json:"unexpected,omitempty"`
+
+	// Err the query error.
+	Err error `json:"-"`
+}
+
+// Report The state of an RRset on the name servers of a zone.
+type Report struct {
+	Name    string         `json:"name"`
+	Type    string         `json:"type"`
+	Servers []ServerStatus `json:"servers"`
+}
+
+// Propagated Returns true if all the name servers serve the RRset.
+func (r *Report) Propagated() bool {
+	return len(r.Servers) > 0 && len(r.Pending()) == 0
+}
+
+// Pending Returns the name servers that do not serve the RRset.
+func (r *Report) Pending() []ServerStatus {
+	var pending []ServerStatus
+
+	for _, status := range r.Servers {
+		if !status.Propagated {
+			pending = append(pending, status)
+		}
+	}
+
+	return pending
+}
+
+// NewChecker Creates a new Checker.
+// The client is used to find the name servers of the zones in their NS records, it can be nil.
+// A nil configuration uses the default values.
+func NewChecker(client *auroradns.Client, config *Config) *Checker {
+	cfg := Config{}
+	if config != nil {
+		cfg = *config
+	}
+
+	if cfg.Port == "" {
+		cfg.Port = DefaultPort
+	}
+
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+
+	if cfg.InitialInterval <= 0 {
+		cfg.InitialInterval = DefaultInitialInterval
+	}
+
+	if cfg.MaxInterval <= 0 {
+		cfg.MaxInterval = DefaultMaxInterval
+	}
+
+	return &Checker{
+		client: client,
+		config: cfg,
+		dns:    &dns.Client{Net: cfg.Net, Timeout: cfg.Timeout},
+	}
+}
+
+// Nameservers Returns the name servers of a zone.
+//
+// The name servers are, in order of preference:
+// the servers of Config.Nameservers, the apex NS records of the zone in the API (if the checker has a client and the zone has an ID),
+// and the NS records of the zone served by the resolvers.
+func (c *Checker) Nameservers(ctx context.Context, zone auroradns.Zone) ([]Nameserver, error) {
+	if len(c.config.Nameservers) > 0 {
+		var nameservers []Nameserver
+		for _, address := range c.config.Nameservers {
+			nameservers = append(nameservers, Nameserver{Address: address})
+		}
+
+		return nameservers, nil
+	}
+
+	hosts, glue, err := c.nameserverHosts(ctx, zone)
+	if err != nil {
+		return nil, err
+	}
+
+	var nameservers []Nameserver
+
+	for _, host := range hosts {
+		ips := glue[host]
+
+		if len(ips) == 0 {
+			ips, err = c.resolveHost(ctx, host)
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve the name server %s: %w", host, err)
+			}
+		}
+
+		for _, ip := range ips {
+			nameservers = append(nameservers, Nameserver{Name: host, Address: net.JoinHostPort(ip, c.config.Port)})
+		}
+	}
+
+	if len(nameservers) == 0 {
+		return nil, fmt.Errorf("no name servers found for %s", auroradns.NormalizeZoneName(zone.Name))
+	}
+
+	return nameservers, nil
+}
+
+// Check Queries the name servers of the zone for the RRset.
+func (c *Checker) Check(ctx context.Context, zone auroradns.Zone, rrset RRset) (*Report, error) {
+	nameservers, err := c.Nameservers(ctx, zone)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.check(ctx, zone, rrset, nameservers)
+}
+
+// CheckRecord Queries the name servers of the zone for a record.
+// The other records with the same name and type are accepted.
+func (c *Checker) CheckRecord(ctx context.Context, zone auroradns.Zone, record auroradns.Record) (*Report, error) {
+	return c.Check(ctx, zone, RRset{Name: record.Name, Type: record.RecordType, Contents: []string{record.Content}})
+}
+
+// WaitForPropagation Queries the name servers of the zone until all of them serve the RRset,
+// with an exponential backoff between the attempts.
+// It returns the last report, and an error if the context ends before the propagation.
+func (c *Checker) WaitForPropagation(ctx context.Context, zone auroradns.Zone, rrset RRset) (*Report, error) {
+	nameservers, err := c.Nameservers(ctx, zone)
+	if err != nil {
+		return nil, err
+	}
+
+	interval := c.config.InitialInterval
+
+	for {
+		report, err := c.check(ctx, zone, rrset, nameservers)
+		if err != nil {
+			return nil, err
+		}
+
+		if report.Propagated() {
+			return report, nil
+		}
+
+		timer := time.NewTimer(interval)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return report, notPropagatedError(report, ctx.Err())
+
+		case <-timer.C:
+		}
+
+		interval = min(2*interval, c.config.MaxInterval)
+	}
+}
+
+func (c *Checker) check(ctx context.Context, zone auroradns.Zone, rrset RRset, nameservers []Nameserver) (*Report, error) {
+	qtype, ok := dns.StringToType[strings.ToUpper(rrset.Type)]
+	if !ok {
+		return nil, fmt.Errorf("unknown record type %q", rrset.Type)
+	}
+
+	zoneName := auroradns.NormalizeZoneName(zone.Name)
+
+	name := zoneName
+	if relative := auroradns.NormalizeName(rrset.Name, zoneName); relative != "" {
+		name = relative + "." + zoneName
+	}
+
+	expected := make([]string, 0, len(rrset.Contents))
+	for _, content := range rrset.Contents {
+		expected = append(expected, auroradns.NormalizeContent(rrset.Type, content))
+	}
+
+	report := &Report{Name: name, Type: dns.TypeToString[qtype], Servers: make([]ServerStatus, len(nameservers))}
+
+	var wg sync.WaitGroup
+
+	for i, nameserver := range nameservers {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			report.Servers[i] = c.checkServer(ctx, nameserver, name, qtype, expected, rrset.Exact)
+		}()
+	}
+
+	wg.Wait()
+
+	return report, nil
+}
+
+func (c *Checker) checkServer(ctx context.Context, nameserver Nameserver, name string, qtype uint16, expected []string, exact bool) ServerStatus {
+	status := ServerStatus{Nameserver: nameserver}
+
+	msg, err := c.exchange(ctx, nameserver.Address, name, qtype, false)
+	if err != nil {
+		status.Err = err
+		return status
+	}
+
+	for _, rr := range msg.Answer {
+		if rr.Header().Rrtype != qtype || !strings.EqualFold(rr.Header().Name, dns.Fqdn(name)) {
+			continue
+		}
+
+		content := strings.TrimPrefix(rr.String(), rr.Header().String())
+
+		status.Contents = append(status.Contents, auroradns.NormalizeContent(dns.TypeToString[qtype], content))
+	}
+
+	for _, content := range expected {
+		if !slices.Contains(status.Contents, content) {
+			status.Missing = append(status.Missing, content)
+		}
+	}
+
+	if exact || len(expected) == 0 {
+		for _, content := range status.Contents {
+			if !slices.Contains(expected, content) {
+				status.Unexpected = append(status.Unexpected, content)
+			}
+		}
+	}
+
+	status.Propagated = len(status.Missing) == 0 && len(status.Unexpected) == 0
+
+	return status
+}
+
+// nameserverHosts Returns the host names of the name servers of the zone, and the addresses found in the responses.
+func (c *Checker) nameserverHosts(ctx context.Context, zone auroradns.Zone) ([]string, map[string][]string, error) {
+	if c.client != nil && zone.ID != "" {
+		records, _, err := c.client.ListRecordsWithContext(ctx, zone.ID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list the records of %s: %w", zone.Name, err)
+		}
+
+		var hosts []string
+
+		for _, record := range records {
+			if strings.EqualFold(record.RecordType, auroradns.RecordTypeNS) && auroradns.NormalizeName(record.Name, zone.Name) == "" {
+				hosts = append(hosts, auroradns.NormalizeZoneName(record.Content))
+			}
+		}
+
+		if len(hosts) > 0 {
+			return hosts, nil, nil
+		}
+	}
+
+	msg, err := c.resolve(ctx, zone.Name, dns.TypeNS)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to find the name servers of %s: %w", zone.Name, err)
+	}
+
+	var hosts []string
+
+	glue := make(map[string][]string)
+
+	for _, rr := range msg.Answer {
+		ns, ok := rr.(*dns.NS)
+		if !ok {
+			continue
+		}
+
+		host := auroradns.NormalizeZoneName(ns.Ns)
+
+		hosts = append(hosts, host)
+		glue[host] = addresses(msg, ns.Ns)
+	}
+
+	return hosts, glue, nil
+}
+
+// resolveHost Returns the IPv4 and IPv6 addresses of a host.
+func (c *Checker) resolveHost(ctx context.Context, host string) ([]string, error) {
+	if ip, err := netip.ParseAddr(host); err == nil {
+		return []string{ip.String()}, nil
+	}
+
+	var (
+		ips  []string
+		errs []error
+	)
+
+	for _, qtype := range []uint16{dns.TypeA, dns.TypeAAAA} {
+		msg, err := c.resolve(ctx, host, qtype)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		ips = append(ips, addresses(msg, dns.Fqdn(host))...)
+	}
+
+	if len(ips) == 0 {
+		return nil, errors.Join(append(errs, errors.New("no addresses"))...)
+	}
+
+	return ips, nil
+}
+
+// resolve Sends a recursive query to the resolvers, in order, until one of them answers.
+func (c *Checker) resolve(ctx context.Context, name string, qtype uint16) (*dns.Msg, error) {
+	resolvers, err := c.resolvers()
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []error
+
+	for _, resolver := range resolvers {
+		msg, err := c.exchange(ctx, resolver, name, qtype, true)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		return msg, nil
+	}
+
+	return nil, errors.Join(errs...)
+}
+
+// resolvers Returns the configured resolvers, or the resolvers of /etc/resolv.conf.
+func (c *Checker) resolvers() ([]string, error) {
+	if len(c.config.Resolvers) > 0 {
+		return c.config.Resolvers, nil
+	}
+
+	conf, err := dns.ClientConfigFromFile("/etc/resolv.conf")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the resolvers: %w", err)
+	}
+
+	var resolvers []string
+	for _, server := range conf.Servers {
+		resolvers = append(resolvers, net.JoinHostPort(server, conf.Port))
+	}
+
+	return resolvers, nil
+}
+
+// exchange Sends a query, and retries over TCP if the response is truncated.
+func (c *Checker) exchange(ctx context.Context, server, name string, qtype uint16, recursive bool) (*dns.Msg, error) {
+	query := new(dns.Msg)
+	query.SetQuestion(dns.Fqdn(name), qtype)
+	query.RecursionDesired = recursive
+
+	msg, _, err := c.dns.ExchangeContext(ctx, query, server)
+	if err == nil && msg.Truncated && c.dns.Net != "tcp" {
+		tcp := &dns.Client{Net: "tcp", Timeout: c.dns.Timeout}
+		msg, _, err = tcp.ExchangeContext(ctx, query, server)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", server, err)
+	}
+
+	if msg.Rcode != dns.RcodeSuccess && msg.Rcode != dns.RcodeNameError {
+		return nil, fmt.Errorf("%s: %s %s: %s", server, dns.TypeToString[qtype], name, dns.RcodeToString[msg.Rcode])
+	}
+
+	return msg, nil
+}
+
+// addresses Returns the addresses of a host found in a response.
+func addresses(msg *dns.Msg, host string) []string {
+	var ips []string
+
+	for _, rr := range slices.Concat(msg.Answer, msg.Extra) {
+		if !strings.EqualFold(rr.Header().Name, host) {
+			continue
+		}
+
+		switch v := rr.(type) {
+		case *dns.A:
+			ips = append(ips, v.A.String())
+		case *dns.AAAA:
+			ips = append(ips, v.AAAA.String())
+		}
+	}
+
+	return ips
+}
+
+func notPropagatedError(report *Report, cause error) error {
+	var (
+		servers []string
+		errs    []error
+	)
+
+	for _, status := range report.Pending() {
+		servers = append(servers, status.Nameserver.Address)
+
+		if status.Err != nil {
+			errs = append(errs, status.Err)
+		}
+	}
+
+	err := fmt.Errorf("%s %s is not served by %s: %w", report.Type, report.Name, strings.Join(servers, ", "), cause)
+
+	return errors.Join(append([]error{err}, errs...)...)
+}
@@ -0,0 +1,217 @@
+package propagation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nrdcg/auroradns"
+	"github.com/nrdcg/auroradns/internal/fakeapi"
+	"github.com/nrdcg/auroradns/propagation/internal/fakedns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTest(t *testing.T) (*auroradns.Client, *fakeapi.Server, auroradns.Zone) {
+	t.Helper()
+
+	api := fakeapi.NewServer("", "")
+	t.Cleanup(api.Close)
+
+	client, err := auroradns.NewClient(nil, auroradns.WithBaseURL(api.URL))
+	require.NoError(t, err)
+
+	zoneID := api.AddZone("example.com")
+	api.AddRecord(zoneID, fakeapi.Record{Type: "A", Name: "www", Content: "192.0.2.1", TTL: 300})
+	api.AddRecord(zoneID, fakeapi.Record{Type: "TXT", Name: "_acme-challenge", Content: "abc"})
+	api.AddRecord(zoneID, fakeapi.Record{Type: "TXT", Name: "_acme-challenge", Content: "def"})
+
+	return client, api, auroradns.Zone{ID: zoneID, Name: "example.com"}
+}
+
+func newDNSServer(t *testing.T, api *fakeapi.Server) *fakedns.Server {
+	t.Helper()
+
+	server, err := fakedns.NewServer(api)
+	require.NoError(t, err)
+
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestChecker_Nameservers(t *testing.T) {
+	client, api, zone := setupTest(t)
+
+	dnsServer := newDNSServer(t, api)
+
+	expected := []Nameserver{{Name: "ns1.auroradns.eu", Address: dnsServer.Addr}}
+
+	testCases := []struct {
+		desc     string
+		client   *auroradns.Client
+		config   *Config
+		expected []Nameserver
+	}{
+		{
+			desc:     "NS records of the API",
+			client:   client,
+			config:   &Config{Resolvers: []string{dnsServer.Addr}, Port: dnsServer.Port()},
+			expected: expected,
+		},
+		{
+			desc:     "NS records of the resolvers",
+			config:   &Config{Resolvers: []string{"127.0.0.1:1", dnsServer.Addr}, Port: dnsServer.Port()},
+			expected: expected,
+		},
+		{
+			desc:     "configured name servers",
+			client:   client,
+			config:   &Config{Resolvers: []string{"127.0.0.1:1"}, Nameservers: []string{"192.0.2.53:53", "192.0.2.54:5353"}},
+			expected: []Nameserver{{Address: "192.0.2.53:53"}, {Address: "192.0.2.54:5353"}},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			nameservers, err := NewChecker(test.client, test.config).Nameservers(t.Context(), zone)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, nameservers)
+		})
+	}
+}
+
+func TestChecker_Nameservers_error(t *testing.T) {
+	_, api, _ := setupTest(t)
+
+	dnsServer := newDNSServer(t, api)
+
+	checker := NewChecker(nil, &Config{Resolvers: []string{dnsServer.Addr}})
+
+	_, err := checker.Nameservers(t.Context(), auroradns.Zone{Name: "example.org"})
+	require.ErrorContains(t, err, "failed to find the name servers of example.org: "+dnsServer.Addr+": NS example.org: REFUSED")
+}
+
+func TestChecker_Check(t *testing.T) {
+	client, api, zone := setupTest(t)
+
+	upToDate := newDNSServer(t, api)
+	late := newDNSServer(t, api)
+	late.SetLag(1000)
+
+	checker := NewChecker(client, &Config{Nameservers: []string{upToDate.Addr, late.Addr}})
+
+	report, err := checker.CheckRecord(t.Context(), zone, auroradns.Record{RecordType: "A", Name: "www", Content: "192.0.2.1"})
+	require.NoError(t, err)
+
+	expected := &Report{
+		Name: "www.example.com",
+		Type: "A",
+		Servers: []ServerStatus{
+			{Nameserver: Nameserver{Address: upToDate.Addr}, Propagated: true, Contents: []string{"192.0.2.1"}},
+			{Nameserver: Nameserver{Address: late.Addr}, Missing: []string{"192.0.2.1"}},
+		},
+	}
+	assert.Equal(t, expected, report)
+	assert.False(t, report.Propagated())
+	assert.Equal(t, expected.Servers[1:], report.Pending())
+}
+
+func TestChecker_Check_rrset(t *testing.T) {
+	client, api, zone := setupTest(t)
+
+	dnsServer := newDNSServer(t, api)
+
+	for _, network := range []string{"udp", "tcp"} {
+		t.Run(network, func(t *testing.T) {
+			checker := NewChecker(client, &Config{Nameservers: []string{dnsServer.Addr}, Net: network})
+
+			testCases := []struct {
+				desc       string
+				rrset      RRset
+				propagated bool
+				unexpected []string
+			}{
+				{
+					desc:       "subset",
+					rrset:      RRset{Name: "_acme-challenge.example.com.", Type: "txt", Contents: []string{"def"}},
+					propagated: true,
+				},
+				{
+					desc:       "exact",
+					rrset:      RRset{Name: "_acme-challenge", Type: "TXT", Contents: []string{`"def"`, "abc"}, Exact: true},
+					propagated: true,
+				},
+				{
+					desc:       "exact with another content",
+					rrset:      RRset{Name: "_acme-challenge", Type: "TXT", Contents: []string{"def"}, Exact: true},
+					unexpected: []string{"abc"},
+				},
+				{
+					desc:       "absent",
+					rrset:      RRset{Name: "api", Type: "A"},
+					propagated: true,
+				},
+				{
+					desc:       "not yet deleted",
+					rrset:      RRset{Name: "www", Type: "A"},
+					unexpected: []string{"192.0.2.1"},
+				},
+			}
+
+			for _, test := range testCases {
+				t.Run(test.desc, func(t *testing.T) {
+					report, err := checker.Check(t.Context(), zone, test.rrset)
+					require.NoError(t, err)
+
+					require.Len(t, report.Servers, 1)
+					assert.Equal(t, test.propagated, report.Propagated())
+					assert.Equal(t, test.unexpected, report.Servers[0].Unexpected)
+				})
+			}
+		})
+	}
+}
+
+func TestChecker_WaitForPropagation(t *testing.T) {
+	client, api, zone := setupTest(t)
+
+	dnsServer := newDNSServer(t, api)
+	dnsServer.SetLag(3)
+
+	checker := NewChecker(client, &Config{
+		Resolvers:       []string{dnsServer.Addr},
+		Port:            dnsServer.Port(),
+		InitialInterval: time.Millisecond,
+		MaxInterval:     10 * time.Millisecond,
+	})
+
+	report, err := checker.WaitForPropagation(t.Context(), zone, RRset{Name: "www", Type: "A", Contents: []string{"192.0.2.1"}})
+	require.NoError(t, err)
+
+	assert.True(t, report.Propagated())
+	assert.Equal(t, 4, dnsServer.Queries())
+}
+
+func TestChecker_WaitForPropagation_timeout(t *testing.T) {
+	client, api, zone := setupTest(t)
+
+	dnsServer := newDNSServer(t, api)
+	dnsServer.SetLag(1000)
+
+	checker := NewChecker(client, &Config{
+		Nameservers:     []string{dnsServer.Addr},
+		InitialInterval: time.Millisecond,
+		MaxInterval:     10 * time.Millisecond,
+	})
+
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+
+	report, err := checker.WaitForPropagation(ctx, zone, RRset{Name: "www", Type: "A", Contents: []string{"192.0.2.1"}})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.ErrorContains(t, err, "A www.example.com is not served by "+dnsServer.Addr)
+
+	assert.False(t, report.Propagated())
+}
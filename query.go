@@ -0,0 +1,147 @@
+package auroradns
+
+import (
+	"context"
+	"net/netip"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// RecordQuery The criteria matched by a record.
+// A record matches the query if it matches all the non-zero criteria.
+type RecordQuery struct {
+	// Name matches the records with this name (compared with NormalizeName).
+	Name string
+
+	// NameSuffix matches the records with this name or a name under it (e.g. "www" matches "www" and "_acme-challenge.www").
+	NameSuffix string
+
+	// NameGlob matches the records with a name matching this pattern (path.Match syntax, case-insensitive).
+	NameGlob string
+
+	// Types matches the records with one of these types.
+	Types []string
+
+	// Content matches the records with this content (compared with NormalizeContent).
+	Content string
+
+	// ContentRegexp matches the records with a content matching this regular expression.
+	ContentRegexp *regexp.Regexp
+
+	// ContentPrefix matches the A and AAAA records with an address contained in this prefix (e.g. 192.0.2.0/24).
+	ContentPrefix netip.Prefix
+
+	// MinTTL and MaxTTL match the records with a TTL in this range (bounds included).
+	MinTTL int
+	MaxTTL int
+
+	// HealthCheckID matches the records with this health check.
+	HealthCheckID string
+
+	// HasHealthCheck matches the records with (true) or without (false) a health check.
+	HasHealthCheck *bool
+}
+
+// ZoneRecord A record and its zone.
+type ZoneRecord struct {
+	Zone   Zone
+	Record Record
+}
+
+// Match Returns true if the record matches the query.
+func (q RecordQuery) Match(record Record) bool {
+	name := NormalizeName(record.Name, "")
+
+	switch {
+	case q.Name != "" && name != NormalizeName(q.Name, ""):
+		return false
+	case q.NameSuffix != "" && !hasNameSuffix(name, NormalizeName(q.NameSuffix, "")):
+		return false
+	case q.NameGlob != "" && !globMatch(strings.ToLower(q.NameGlob), name):
+		return false
+	case len(q.Types) > 0 && !slices.ContainsFunc(q.Types, func(t string) bool { return strings.EqualFold(t, record.RecordType) }):
+		return false
+	case q.Content != "" && NormalizeContent(record.RecordType, q.Content) != NormalizeContent(record.RecordType, record.Content):
+		return false
+	case q.ContentRegexp != nil && !q.ContentRegexp.MatchString(record.Content):
+		return false
+	case q.ContentPrefix.IsValid() && !prefixContains(q.ContentPrefix, record):
+		return false
+	case q.MinTTL > 0 && record.TTL < q.MinTTL:
+		return false
+	case q.MaxTTL > 0 && record.TTL > q.MaxTTL:
+		return false
+	case q.HealthCheckID != "" && q.HealthCheckID != record.HealthCheckID:
+		return false
+	case q.HasHealthCheck != nil && *q.HasHealthCheck != (record.HealthCheckID != ""):
+		return false
+	default:
+		return true
+	}
+}
+
+// ListRecordsMatching Returns the records of a zone matching the query.
+func (c *Client) ListRecordsMatching(ctx context.Context, zoneID string, query RecordQuery) ([]Record, error) {
+	var records []Record
+
+	for record, err := range c.AllRecords(ctx, zoneID) {
+		if err != nil {
+			return nil, err
+		}
+
+		if query.Match(record) {
+			records = append(records, record)
+		}
+	}
+
+	return records, nil
+}
+
+// SearchRecords Returns the records of all the zones matching the query.
+func (c *Client) SearchRecords(ctx context.Context, query RecordQuery) ([]ZoneRecord, error) {
+	zones, _, err := c.ListZonesWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []ZoneRecord
+
+	for _, zone := range zones {
+		records, err := c.ListRecordsMatching(ctx, zone.ID, query)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, record := range records {
+			results = append(results, ZoneRecord{Zone: zone, Record: record})
+		}
+	}
+
+	return results, nil
+}
+
+func hasNameSuffix(name, suffix string) bool {
+	return name == suffix || strings.HasSuffix(name, "."+suffix)
+}
+
+func globMatch(pattern, name string) bool {
+	matched, err := path.Match(pattern, name)
+
+	return err == nil && matched
+}
+
+func prefixContains(prefix netip.Prefix, record Record) bool {
+	recordType := strings.ToUpper(record.RecordType)
+	if recordType != RecordTypeA && recordType != RecordTypeAAAA {
+		return false
+	}
+
+	addr, err := netip.ParseAddr(record.Content)
+	if err != nil {
+		return false
+	}
+
+	return prefix.Contains(addr.Unmap())
+}
@@ -0,0 +1,208 @@
+package auroradns
+
+import (
+	"fmt"
+	"net/http"
+	"net/netip"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordQuery_Match(t *testing.T) {
+	yes, no := true, false
+
+	testCases := []struct {
+		desc     string
+		query    RecordQuery
+		record   Record
+		expected bool
+	}{
+		{
+			desc:     "empty query",
+			record:   Record{RecordType: RecordTypeA, Name: "www", Content: "192.0.2.10"},
+			expected: true,
+		},
+		{
+			desc:     "name",
+			query:    RecordQuery{Name: "WWW."},
+			record:   Record{RecordType: RecordTypeA, Name: "www"},
+			expected: true,
+		},
+		{
+			desc:   "other name",
+			query:  RecordQuery{Name: "www"},
+			record: Record{RecordType: RecordTypeA, Name: "www2"},
+		},
+		{
+			desc:     "name suffix: same name",
+			query:    RecordQuery{NameSuffix: "www"},
+			record:   Record{RecordType: RecordTypeTXT, Name: "www"},
+			expected: true,
+		},
+		{
+			desc:     "name suffix: sub-domain",
+			query:    RecordQuery{NameSuffix: "www"},
+			record:   Record{RecordType: RecordTypeTXT, Name: "_acme-challenge.www"},
+			expected: true,
+		},
+		{
+			desc:   "name suffix: not on a label boundary",
+			query:  RecordQuery{NameSuffix: "www"},
+			record: Record{RecordType: RecordTypeTXT, Name: "foowww"},
+		},
+		{
+			desc:     "name glob",
+			query:    RecordQuery{NameGlob: "_acme-challenge*", Types: []string{RecordTypeTXT}},
+			record:   Record{RecordType: RecordTypeTXT, Name: "_acme-challenge.www"},
+			expected: true,
+		},
+		{
+			desc:   "other type",
+			query:  RecordQuery{NameGlob: "_acme-challenge*", Types: []string{RecordTypeTXT}},
+			record: Record{RecordType: RecordTypeCNAME, Name: "_acme-challenge.www"},
+		},
+		{
+			desc:     "content",
+			query:    RecordQuery{Content: "192.0.2.10"},
+			record:   Record{RecordType: RecordTypeA, Name: "www", Content: "192.0.2.10"},
+			expected: true,
+		},
+		{
+			desc:     "normalized content",
+			query:    RecordQuery{Content: "2001:db8::1"},
+			record:   Record{RecordType: RecordTypeAAAA, Name: "www", Content: "2001:0db8:0000:0000:0000:0000:0000:0001"},
+			expected: true,
+		},
+		{
+			desc:     "normalized name",
+			query:    RecordQuery{Name: "@"},
+			record:   Record{RecordType: RecordTypeA, Name: ""},
+			expected: true,
+		},
+		{
+			desc:     "content regexp",
+			query:    RecordQuery{ContentRegexp: regexp.MustCompile(`^v=spf1 `)},
+			record:   Record{RecordType: RecordTypeTXT, Content: "v=spf1 -all"},
+			expected: true,
+		},
+		{
+			desc:     "IPv4 prefix",
+			query:    RecordQuery{ContentPrefix: netip.MustParsePrefix("192.0.2.0/24")},
+			record:   Record{RecordType: RecordTypeA, Content: "192.0.2.10"},
+			expected: true,
+		},
+		{
+			desc:     "IPv6 prefix",
+			query:    RecordQuery{ContentPrefix: netip.MustParsePrefix("2001:db8::/32")},
+			record:   Record{RecordType: RecordTypeAAAA, Content: "2001:0db8:0000:0000:0000:0000:0000:0001"},
+			expected: true,
+		},
+		{
+			desc:     "prefix and lowercase record type",
+			query:    RecordQuery{ContentPrefix: netip.MustParsePrefix("192.0.2.0/24")},
+			record:   Record{RecordType: "a", Content: "192.0.2.10"},
+			expected: true,
+		},
+		{
+			desc:   "address outside of the prefix",
+			query:  RecordQuery{ContentPrefix: netip.MustParsePrefix("192.0.2.0/24")},
+			record: Record{RecordType: RecordTypeA, Content: "198.51.100.1"},
+		},
+		{
+			desc:   "prefix and not an address record",
+			query:  RecordQuery{ContentPrefix: netip.MustParsePrefix("192.0.2.0/24")},
+			record: Record{RecordType: RecordTypeTXT, Content: "192.0.2.10"},
+		},
+		{
+			desc:     "TTL range",
+			query:    RecordQuery{MinTTL: 300, MaxTTL: 3600},
+			record:   Record{RecordType: RecordTypeA, TTL: 300},
+			expected: true,
+		},
+		{
+			desc:   "TTL above the range",
+			query:  RecordQuery{MinTTL: 300, MaxTTL: 3600},
+			record: Record{RecordType: RecordTypeA, TTL: 7200},
+		},
+		{
+			desc:     "health check",
+			query:    RecordQuery{HealthCheckID: "hc-1"},
+			record:   Record{RecordType: RecordTypeA, HealthCheckID: "hc-1"},
+			expected: true,
+		},
+		{
+			desc:     "with a health check",
+			query:    RecordQuery{HasHealthCheck: &yes},
+			record:   Record{RecordType: RecordTypeA, HealthCheckID: "hc-1"},
+			expected: true,
+		},
+		{
+			desc:   "without a health check",
+			query:  RecordQuery{HasHealthCheck: &no},
+			record: Record{RecordType: RecordTypeA, HealthCheckID: "hc-1"},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.query.Match(test.record))
+		})
+	}
+}
+
+func TestClient_ListRecordsMatching(t *testing.T) {
+	client, mux := setupTest(t)
+
+	handleAPI(mux, "/zones/identifier-zone-1/records", http.MethodGet, func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = fmt.Fprint(w, `[
+			{"id":"aaa","type":"A","name":"www","content":"192.0.2.10","ttl":300},
+			{"id":"bbb","type":"TXT","name":"_acme-challenge.www","content":"xxx","ttl":60},
+			{"id":"ccc","type":"A","name":"mail","content":"192.0.2.20","ttl":300}
+		]`)
+	})
+
+	records, err := client.ListRecordsMatching(t.Context(), "identifier-zone-1", RecordQuery{Types: []string{RecordTypeA}, Content: "192.0.2.10"})
+	require.NoError(t, err)
+
+	expected := []Record{{ID: "aaa", RecordType: RecordTypeA, Name: "www", Content: "192.0.2.10", TTL: 300}}
+	assert.Equal(t, expected, records)
+}
+
+func TestClient_SearchRecords(t *testing.T) {
+	client, mux := setupTest(t)
+
+	handleAPI(mux, "/zones", http.MethodGet, func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = fmt.Fprint(w, `[{"id":"identifier-zone-1","name":"example.com"},{"id":"identifier-zone-2","name":"example.org"}]`)
+	})
+
+	handleAPI(mux, "/zones/identifier-zone-1/records", http.MethodGet, func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = fmt.Fprint(w, `[
+			{"id":"aaa","type":"A","name":"www","content":"192.0.2.10","ttl":300},
+			{"id":"bbb","type":"TXT","name":"_acme-challenge.www","content":"xxx","ttl":60}
+		]`)
+	})
+
+	handleAPI(mux, "/zones/identifier-zone-2/records", http.MethodGet, func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = fmt.Fprint(w, `[
+			{"id":"ccc","type":"TXT","name":"_acme-challenge","content":"yyy","ttl":60}
+		]`)
+	})
+
+	results, err := client.SearchRecords(t.Context(), RecordQuery{NameGlob: "_acme-challenge*", Types: []string{RecordTypeTXT}})
+	require.NoError(t, err)
+
+	expected := []ZoneRecord{
+		{
+			Zone:   Zone{ID: "identifier-zone-1", Name: "example.com"},
+			Record: Record{ID: "bbb", RecordType: RecordTypeTXT, Name: "_acme-challenge.www", Content: "xxx", TTL: 60},
+		},
+		{
+			Zone:   Zone{ID: "identifier-zone-2", Name: "example.org"},
+			Record: Record{ID: "ccc", RecordType: RecordTypeTXT, Name: "_acme-challenge", Content: "yyy", TTL: 60},
+		},
+	}
+	assert.Equal(t, expected, results)
+}
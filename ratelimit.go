@@ -0,0 +1,59 @@
+package auroradns
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// WithRateLimit Allows to limit the number of requests sent per second.
+// Up to burst requests can be sent at once (1 if burst is lower than 1).
+func WithRateLimit(requestsPerSecond float64, burst int) Option {
+	return func(client *Client) error {
+		if requestsPerSecond <= 0 {
+			return fmt.Errorf("invalid rate limit: %v", requestsPerSecond)
+		}
+
+		client.limiter = newRateLimiter(requestsPerSecond, burst)
+
+		return nil
+	}
+}
+
+// rateLimiter a token bucket.
+type rateLimiter struct {
+	mu sync.Mutex
+
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	b := float64(max(burst, 1))
+
+	return &rateLimiter{
+		rate:   rate,
+		burst:  b,
+		tokens: b,
+		last:   time.Now(),
+	}
+}
+
+// reserve takes a token and returns how long to wait before it is available.
+func (l *rateLimiter) reserve(now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+
+	l.tokens--
+
+	if l.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
@@ -0,0 +1,32 @@
+package auroradns
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter_reserve(t *testing.T) {
+	limiter := newRateLimiter(2, 2)
+
+	now := limiter.last
+
+	assert.Zero(t, limiter.reserve(now))
+	assert.Zero(t, limiter.reserve(now))
+	assert.Equal(t, 500*time.Millisecond, limiter.reserve(now))
+	assert.Equal(t, time.Second, limiter.reserve(now))
+
+	// 2 seconds later, the bucket holds 2 tokens again.
+	now = now.Add(2500 * time.Millisecond)
+
+	assert.Zero(t, limiter.reserve(now))
+	assert.Zero(t, limiter.reserve(now))
+	assert.Equal(t, 500*time.Millisecond, limiter.reserve(now))
+}
+
+func TestWithRateLimit_invalid(t *testing.T) {
+	_, err := NewClient(nil, WithRateLimit(0, 1))
+	require.EqualError(t, err, "invalid rate limit: 0")
+}
@@ -26,11 +26,12 @@ const (
 
 // Record a DNS record.
 type Record struct {
-	ID         string `json:"id,omitempty"`
-	RecordType string `json:"type"`
-	Name       string `json:"name"`
-	Content    string `json:"content"`
-	TTL        int    `json:"ttl,omitempty"`
+	ID            string `json:"id,omitempty"`
+	RecordType    string `json:"type"`
+	Name          string `json:"name"`
+	Content       string `json:"content"`
+	TTL           int    `json:"ttl,omitempty"`
+	HealthCheckID string `json:"health_check_id,omitempty"`
 }
 
 // CreateRecord Creates a new record.
@@ -54,7 +55,7 @@ func (c *Client) CreateRecordWithContext(ctx context.Context, zoneID string, rec
 
 	newRecord := new(Record)
 
-	resp, err := c.do(req, newRecord)
+	resp, err := c.do(&Call{Operation: OperationCreateRecord, ZoneID: zoneID, RecordType: record.RecordType, Request: req}, newRecord)
 	if err != nil {
 		return nil, resp, err
 	}
@@ -62,6 +63,39 @@ func (c *Client) CreateRecordWithContext(ctx context.Context, zoneID string, rec
 	return newRecord, resp, nil
 }
 
+// UpdateRecord Updates a record.
+func (c *Client) UpdateRecord(zoneID, recordID string, record Record) (*Record, *http.Response, error) {
+	return c.UpdateRecordWithContext(context.Background(), zoneID, recordID, record)
+}
+
+// UpdateRecordWithContext Updates a record.
+func (c *Client) UpdateRecordWithContext(ctx context.Context, zoneID, recordID string, record Record) (*Record, *http.Response, error) {
+	record.ID = ""
+
+	body, err := json.Marshal(record)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshall request body: %w", err)
+	}
+
+	endpoint := c.baseURL.JoinPath("zones", zoneID, "records", recordID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	updatedRecord := new(Record)
+
+	call := &Call{Operation: OperationUpdateRecord, ZoneID: zoneID, RecordID: recordID, RecordType: record.RecordType, Request: req}
+
+	resp, err := c.do(call, updatedRecord)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return updatedRecord, resp, nil
+}
+
 // DeleteRecord Delete a record.
 func (c *Client) DeleteRecord(zoneID, recordID string) (bool, *http.Response, error) {
 	return c.DeleteRecordWithContext(context.Background(), zoneID, recordID)
@@ -76,7 +110,7 @@ func (c *Client) DeleteRecordWithContext(ctx context.Context, zoneID, recordID s
 		return false, nil, err
 	}
 
-	resp, err := c.do(req, nil)
+	resp, err := c.do(&Call{Operation: OperationDeleteRecord, ZoneID: zoneID, RecordID: recordID, Request: req}, nil)
 	if err != nil {
 		return false, resp, err
 	}
@@ -100,7 +134,7 @@ func (c *Client) ListRecordsWithContext(ctx context.Context, zoneID string) ([]R
 
 	var records []Record
 
-	resp, err := c.do(req, &records)
+	resp, err := c.do(&Call{Operation: OperationListRecords, ZoneID: zoneID, Request: req}, &records)
 	if err != nil {
 		return nil, resp, err
 	}
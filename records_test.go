@@ -109,6 +109,61 @@ func TestClient_CreateRecordWithContext_error(t *testing.T) {
 	assert.Nil(t, newRecord)
 }
 
+func TestClient_UpdateRecordWithContext(t *testing.T) {
+	client, mux := setupTest(t)
+
+	zoneID := "identifier-zone-2"
+	recordID := "identifier-record-1"
+
+	handleAPI(mux, "/zones/identifier-zone-2/records/identifier-record-1", http.MethodPut, func(w http.ResponseWriter, r *http.Request) {
+		reqBody, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if string(reqBody) != `{"type":"A","name":"www","content":"192.0.2.2","ttl":600}` {
+			http.Error(w, fmt.Sprintf("invalid request body: %s", string(reqBody)), http.StatusInternalServerError)
+			return
+		}
+
+		_, err = fmt.Fprintf(w, `{
+				"id":      "identifier-record-1",
+				"type":    "A",
+				"name":    "www",
+				"content": "192.0.2.2",
+				"ttl":     600
+			}`)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	record := Record{
+		ID:         "ignored",
+		RecordType: RecordTypeA,
+		Name:       "www",
+		Content:    "192.0.2.2",
+		TTL:        600,
+	}
+
+	updatedRecord, resp, err := client.UpdateRecordWithContext(t.Context(), zoneID, recordID, record)
+	require.NoError(t, err)
+
+	require.NotNil(t, resp)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	expected := &Record{
+		ID:         "identifier-record-1",
+		RecordType: RecordTypeA,
+		Name:       "www",
+		Content:    "192.0.2.2",
+		TTL:        600,
+	}
+	assert.Equal(t, expected, updatedRecord)
+}
+
 func TestClient_RemoveRecordWithContext(t *testing.T) {
 	client, mux := setupTest(t)
 
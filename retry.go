@@ -0,0 +1,190 @@
+package auroradns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultRetryWait = 500 * time.Millisecond
+	maxRetryWait     = 30 * time.Second
+)
+
+// WithRetry Allows to retry requests that failed because of rate limiting or temporary server errors.
+// The wait between attempts doubles after each attempt, starting at wait (500ms if zero),
+// unless the server sends a Retry-After header.
+//
+// Responses with the status 429 are always retried.
+// Network errors and the statuses 502, 503 and 504 are only retried for idempotent methods (GET, PUT, DELETE).
+func WithRetry(maxRetries int, wait time.Duration) Option {
+	return func(client *Client) error {
+		if maxRetries < 0 {
+			return fmt.Errorf("invalid number of retries: %d", maxRetries)
+		}
+
+		if wait < 0 {
+			return fmt.Errorf("invalid retry wait: %s", wait)
+		}
+
+		if wait == 0 {
+			wait = defaultRetryWait
+		}
+
+		client.maxRetries = maxRetries
+		client.retryWait = wait
+
+		return nil
+	}
+}
+
+// send sends the request of the call, waiting for the rate limiter and retrying when allowed.
+func (c *Client) send(call *Call) (*http.Response, error) {
+	req := call.Request
+	ctx := req.Context()
+
+	for attempt := 0; ; attempt++ {
+		if c.limiter != nil {
+			wait := c.limiter.reserve(time.Now())
+
+			if c.metrics != nil {
+				c.metrics.ObserveRateLimitWait(call.Operation, wait)
+			}
+
+			err := sleep(ctx, wait)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err := c.httpClient.Do(req)
+
+		if attempt >= c.maxRetries || !shouldRetry(req, resp, err) {
+			return resp, err
+		}
+
+		wait := c.backoff(attempt, resp)
+
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}
+
+		err = rewindBody(req)
+		if err != nil {
+			return nil, err
+		}
+
+		err = sleep(ctx, wait)
+		if err != nil {
+			return nil, err
+		}
+
+		call.Retries++
+
+		if c.metrics != nil {
+			c.metrics.CountRetry(call.Operation)
+		}
+	}
+}
+
+func (c *Client) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		wait, ok := retryAfter(resp.Header.Get("Retry-After"), time.Now())
+		if ok {
+			return wait
+		}
+	}
+
+	// compared before shifting: the shifted wait overflows after a few dozen attempts.
+	if c.retryWait > maxRetryWait>>attempt {
+		return maxRetryWait
+	}
+
+	return c.retryWait << attempt
+}
+
+// retryAfter Returns the wait of a Retry-After header (RFC 9110): a number of seconds, or an HTTP date.
+func retryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	seconds, err := strconv.Atoi(value)
+	if err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+
+		return time.Duration(min(seconds, int(maxRetryWait/time.Second))) * time.Second, true
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+
+	return min(max(date.Sub(now), 0), maxRetryWait), true
+}
+
+func shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	if req.Context().Err() != nil {
+		return false
+	}
+
+	idempotent := req.Method == http.MethodGet || req.Method == http.MethodPut || req.Method == http.MethodDelete
+
+	if err != nil {
+		return idempotent
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return true
+
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return idempotent
+
+	default:
+		return false
+	}
+}
+
+func rewindBody(req *http.Request) error {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil
+	}
+
+	if req.GetBody == nil {
+		return errors.New("unable to retry: the request body cannot be rewound")
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return fmt.Errorf("failed to rewind body: %w", err)
+	}
+
+	req.Body = body
+
+	return nil
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
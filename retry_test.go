@@ -0,0 +1,166 @@
+package auroradns
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_retry(t *testing.T) {
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+
+		_, _ = w.Write([]byte(`[{"id":"identifier-zone-1","name":"example.com"}]`))
+	}))
+	t.Cleanup(server.Close)
+
+	var retries int
+
+	observe := func(next Handler) Handler {
+		return func(call *Call) (*http.Response, error) {
+			resp, err := next(call)
+
+			retries = call.Retries
+
+			return resp, err
+		}
+	}
+
+	client, err := NewClient(nil, WithBaseURL(server.URL), WithRetry(2, time.Millisecond), WithMiddleware(observe))
+	require.NoError(t, err)
+
+	zones, _, err := client.ListZonesWithContext(t.Context())
+	require.NoError(t, err)
+
+	assert.Equal(t, []Zone{{ID: "identifier-zone-1", Name: "example.com"}}, zones)
+	assert.EqualValues(t, 3, calls.Load())
+	assert.Equal(t, 2, retries)
+}
+
+func TestClient_retry_exhausted(t *testing.T) {
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(`{"error":"ServiceUnavailableError","errormsg":"try again later"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(nil, WithBaseURL(server.URL), WithRetry(2, time.Millisecond))
+	require.NoError(t, err)
+
+	_, resp, err := client.DeleteZoneWithContext(t.Context(), "identifier-zone-1")
+	require.EqualError(t, err, "ServiceUnavailableError - try again later")
+
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.EqualValues(t, 3, calls.Load())
+}
+
+func TestClient_retry_not_idempotent(t *testing.T) {
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(`{"error":"ServiceUnavailableError","errormsg":"try again later"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(nil, WithBaseURL(server.URL), WithRetry(2, time.Millisecond))
+	require.NoError(t, err)
+
+	_, _, err = client.CreateZoneWithContext(t.Context(), "example.com")
+	require.Error(t, err)
+
+	assert.EqualValues(t, 1, calls.Load())
+}
+
+func TestClient_retry_body(t *testing.T) {
+	var bodies []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		bodies = append(bodies, string(body))
+
+		if len(bodies) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"id":"identifier-zone-1","name":"example.com"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(nil, WithBaseURL(server.URL), WithRetry(1, time.Hour))
+	require.NoError(t, err)
+
+	_, _, err = client.CreateZoneWithContext(t.Context(), "example.com")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{`{"name":"example.com"}`, `{"name":"example.com"}`}, bodies)
+}
+
+func TestWithRetry_invalid(t *testing.T) {
+	_, err := NewClient(nil, WithRetry(-1, 0))
+	require.EqualError(t, err, "invalid number of retries: -1")
+}
+
+func TestClient_backoff(t *testing.T) {
+	client, err := NewClient(nil, WithRetry(100, time.Second))
+	require.NoError(t, err)
+
+	assert.Equal(t, time.Second, client.backoff(0, nil))
+	assert.Equal(t, 16*time.Second, client.backoff(4, nil))
+	assert.Equal(t, maxRetryWait, client.backoff(5, nil))
+	assert.Equal(t, maxRetryWait, client.backoff(40, nil))
+	assert.Equal(t, maxRetryWait, client.backoff(100, nil))
+
+	resp := &http.Response{Header: http.Header{"Retry-After": []string{"2"}}}
+	assert.Equal(t, 2*time.Second, client.backoff(40, resp))
+}
+
+func Test_retryAfter(t *testing.T) {
+	now := time.Date(2026, time.October, 18, 10, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		desc     string
+		value    string
+		expected time.Duration
+		ok       bool
+	}{
+		{desc: "empty", value: ""},
+		{desc: "seconds", value: "5", expected: 5 * time.Second, ok: true},
+		{desc: "seconds above the maximum", value: "99999999999", expected: maxRetryWait, ok: true},
+		{desc: "negative seconds", value: "-1"},
+		{desc: "HTTP date", value: "Sun, 18 Oct 2026 10:00:07 GMT", expected: 7 * time.Second, ok: true},
+		{desc: "HTTP date in the past", value: "Sun, 18 Oct 2026 09:00:00 GMT", expected: 0, ok: true},
+		{desc: "HTTP date above the maximum", value: "Mon, 19 Oct 2026 10:00:00 GMT", expected: maxRetryWait, ok: true},
+		{desc: "invalid", value: "soon"},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			wait, ok := retryAfter(test.value, now)
+
+			assert.Equal(t, test.ok, ok)
+			assert.Equal(t, test.expected, wait)
+		})
+	}
+}
@@ -0,0 +1,309 @@
+// Package rfc2136 A DNS UPDATE (RFC 2136) gateway to the Aurora DNS API.
+//
+// The gateway receives the DNS UPDATE messages of the existing tools (nsupdate, certbot-dns-rfc2136, ISC DHCP, ...),
+// authenticated with TSIG (RFC 8945), checks their prerequisites against the records of the zone,
+// and applies their changes with the API:
+//
+//	gateway, err := rfc2136.NewGateway(client, rfc2136.Config{
+//		Keys: []rfc2136.Key{{Name: "dhcp.", Secret: "base64 secret", Zones: []string{"example.com"}}},
+//	})
+//
+//	err = gateway.ListenAndServe(ctx, ":53")
+//
+// The prerequisites are checked, then the changes are applied, while holding a lock of the gateway:
+// the changes made by the other clients of the API at the same time are not seen.
+// The API calls of an update end with the context of the server, or after Config.UpdateTimeout.
+// The SOA record and the apex NS records are managed by Aurora DNS: their updates are ignored.
+package rfc2136
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/nrdcg/auroradns"
+)
+
+// DefaultUpdateTimeout the default timeout of the API calls of an update.
+const DefaultUpdateTimeout = 30 * time.Second
+
+// Key A TSIG key, and the zones it can update.
+type Key struct {
+	// Name the name of the key (e.g. "dhcp.").
+	Name string `yaml:"name"`
+
+	// Algorithm the TSIG algorithm (hmac-sha256 by default).
+	Algorithm string `yaml:"algorithm"`
+
+	// Secret the secret, base64-encoded.
+	Secret string `yaml:"secret"`
+
+	// Zones the zones the key can update.
+	Zones []string `yaml:"zones"`
+}
+
+// Config the configuration of a Gateway.
+type Config struct {
+	// Keys the TSIG keys of the clients.
+	Keys []Key
+
+	// UpdateTimeout the timeout of the API calls of an update (DefaultUpdateTimeout by default).
+	UpdateTimeout time.Duration
+
+	// Logger logs the updates (slog.Default by default).
+	Logger *slog.Logger
+}
+
+// Gateway A DNS UPDATE server applying the changes with the API.
+type Gateway struct {
+	client        *auroradns.Client
+	keys          map[string]Key
+	updateTimeout time.Duration
+	logger        *slog.Logger
+
+	// mu serializes the updates, between the check of the prerequisites and the changes.
+	mu sync.Mutex
+}
+
+// NewGateway Creates a new Gateway.
+func NewGateway(client *auroradns.Client, config Config) (*Gateway, error) {
+	if client == nil {
+		return nil, errors.New("the client is required")
+	}
+
+	if len(config.Keys) == 0 {
+		return nil, errors.New("at least one key is required")
+	}
+
+	keys := make(map[string]Key)
+
+	for _, key := range config.Keys {
+		key.Name = dns.CanonicalName(key.Name)
+
+		if key.Algorithm == "" {
+			key.Algorithm = dns.HmacSHA256
+		}
+
+		key.Algorithm = dns.CanonicalName(key.Algorithm)
+
+		if _, ok := keys[key.Name]; ok {
+			return nil, fmt.Errorf("duplicate key %s", key.Name)
+		}
+
+		if key.Secret == "" {
+			return nil, fmt.Errorf("missing secret for the key %s", key.Name)
+		}
+
+		for i, zone := range key.Zones {
+			key.Zones[i] = auroradns.NormalizeZoneName(zone)
+		}
+
+		keys[key.Name] = key
+	}
+
+	updateTimeout := config.UpdateTimeout
+	if updateTimeout <= 0 {
+		updateTimeout = DefaultUpdateTimeout
+	}
+
+	logger := config.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &Gateway{client: client, keys: keys, updateTimeout: updateTimeout, logger: logger}, nil
+}
+
+// TsigSecrets Returns the secrets of the keys, for dns.Server.TsigSecret.
+func (g *Gateway) TsigSecrets() map[string]string {
+	secrets := make(map[string]string, len(g.keys))
+
+	for name, key := range g.keys {
+		secrets[name] = key.Secret
+	}
+
+	return secrets
+}
+
+// ListenAndServe Serves the DNS UPDATE messages on the address, over UDP and TCP, until the context ends.
+func (g *Gateway) ListenAndServe(ctx context.Context, addr string) error {
+	var servers []*dns.Server
+
+	shutdown := func() {
+		for _, server := range servers {
+			_ = server.ShutdownContext(context.WithoutCancel(ctx))
+		}
+	}
+
+	errs := make(chan error, 2)
+
+	for _, network := range []string{"udp", "tcp"} {
+		server := g.Server(ctx, addr, network)
+
+		started := make(chan struct{})
+		server.NotifyStartedFunc = func() { close(started) }
+
+		go func() { errs <- server.ListenAndServe() }()
+
+		// the server can only be shut down once started.
+		select {
+		case <-started:
+			servers = append(servers, server)
+
+		case err := <-errs:
+			shutdown()
+			return err
+		}
+	}
+
+	var err error
+
+	select {
+	case <-ctx.Done():
+	case err = <-errs:
+	}
+
+	shutdown()
+
+	return err
+}
+
+// Server Returns a dns.Server for the gateway, on the address and the network ("udp" or "tcp").
+// The updates in progress are canceled when the context ends.
+func (g *Gateway) Server(ctx context.Context, addr, network string) *dns.Server {
+	return &dns.Server{
+		Addr:          addr,
+		Net:           network,
+		Handler:       dns.HandlerFunc(func(w dns.ResponseWriter, req *dns.Msg) { g.serveDNS(ctx, w, req) }),
+		TsigSecret:    g.TsigSecrets(),
+		MsgAcceptFunc: acceptUpdates,
+	}
+}
+
+// acceptUpdates Accepts the DNS UPDATE messages, which are rejected by dns.DefaultMsgAcceptFunc.
+func acceptUpdates(dh dns.Header) dns.MsgAcceptAction {
+	const qr = 1 << 15
+
+	if dh.Bits&qr != 0 {
+		return dns.MsgIgnore
+	}
+
+	if opcode := int(dh.Bits>>11) & 0xF; opcode == dns.OpcodeUpdate {
+		return dns.MsgAccept
+	}
+
+	return dns.DefaultMsgAcceptFunc(dh)
+}
+
+// serveDNS Handles a DNS UPDATE message.
+func (g *Gateway) serveDNS(ctx context.Context, w dns.ResponseWriter, req *dns.Msg) {
+	resp := new(dns.Msg)
+	resp.SetRcode(req, g.handle(ctx, w, req))
+
+	if tsig := req.IsTsig(); tsig != nil && w.TsigStatus() == nil {
+		resp.SetTsig(tsig.Hdr.Name, tsig.Algorithm, tsig.Fudge, time.Now().Unix())
+	}
+
+	_ = w.WriteMsg(resp)
+}
+
+func (g *Gateway) handle(ctx context.Context, w dns.ResponseWriter, req *dns.Msg) int {
+	if req.Opcode != dns.OpcodeUpdate {
+		return dns.RcodeNotImplemented
+	}
+
+	tsig := req.IsTsig()
+	if tsig == nil {
+		g.logger.Warn("rfc2136: unsigned update refused", slog.String("remote", w.RemoteAddr().String()))
+		return dns.RcodeRefused
+	}
+
+	if err := w.TsigStatus(); err != nil {
+		g.logger.Warn("rfc2136: invalid TSIG", slog.String("remote", w.RemoteAddr().String()),
+			slog.String("key", tsig.Hdr.Name), slog.Any("error", err))
+
+		return dns.RcodeNotAuth
+	}
+
+	key, ok := g.keys[dns.CanonicalName(tsig.Hdr.Name)]
+	if !ok || !strings.EqualFold(dns.CanonicalName(tsig.Algorithm), key.Algorithm) {
+		return dns.RcodeNotAuth
+	}
+
+	// RFC 2136 section 3.1.1: the zone section contains one SOA question.
+	if len(req.Question) != 1 || req.Question[0].Qtype != dns.TypeSOA || req.Question[0].Qclass != dns.ClassINET {
+		return dns.RcodeFormatError
+	}
+
+	zoneName := auroradns.NormalizeZoneName(req.Question[0].Name)
+
+	logger := g.logger.With(slog.String("key", key.Name), slog.String("zone", zoneName))
+
+	if !slices.Contains(key.Zones, zoneName) {
+		logger.Warn("rfc2136: zone not allowed for the key")
+		return dns.RcodeRefused
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, g.updateTimeout)
+	defer cancel()
+
+	update, rcode := g.newUpdate(ctx, zoneName)
+	if rcode != dns.RcodeSuccess {
+		return rcode
+	}
+
+	rcode = update.checkPrerequisites(req.Answer)
+	if rcode != dns.RcodeSuccess {
+		logger.Info("rfc2136: prerequisites not satisfied", slog.String("rcode", dns.RcodeToString[rcode]))
+		return rcode
+	}
+
+	rcode = update.prescan(req.Ns)
+	if rcode != dns.RcodeSuccess {
+		return rcode
+	}
+
+	err := update.apply(ctx, req.Ns)
+	if err != nil {
+		logger.Error("rfc2136: update failed", slog.Any("error", err))
+		return dns.RcodeServerFailure
+	}
+
+	logger.Info("rfc2136: update applied", slog.Int("changes", len(req.Ns)))
+
+	return dns.RcodeSuccess
+}
+
+func (g *Gateway) newUpdate(ctx context.Context, zoneName string) (*update, int) {
+	zones, _, err := g.client.ListZonesWithContext(ctx)
+	if err != nil {
+		g.logger.Error("rfc2136: failed to list the zones", slog.Any("error", err))
+		return nil, dns.RcodeServerFailure
+	}
+
+	for _, zone := range zones {
+		if auroradns.NormalizeZoneName(zone.Name) != zoneName {
+			continue
+		}
+
+		records, _, err := g.client.ListRecordsWithContext(ctx, zone.ID)
+		if err != nil {
+			g.logger.Error("rfc2136: failed to list the records", slog.String("zone", zoneName), slog.Any("error", err))
+			return nil, dns.RcodeServerFailure
+		}
+
+		return &update{client: g.client, zone: zone, zoneName: zoneName, records: records}, dns.RcodeSuccess
+	}
+
+	// RFC 2136 section 3.1.2: the server is not authoritative for the zone.
+	return nil, dns.RcodeNotAuth
+}
@@ -0,0 +1,339 @@
+package rfc2136
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/nrdcg/auroradns"
+	"github.com/nrdcg/auroradns/internal/fakeapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	dhcpSecret    = "c2VjcmV0LWRoY3Ata2V5LTAxMjM0NTY3ODk="
+	certbotSecret = "c2VjcmV0LWNlcnRib3Qta2V5LTAxMjM0NTY="
+)
+
+func setupTest(t *testing.T, ctx context.Context) (*fakeapi.Server, string, string) {
+	t.Helper()
+
+	api := fakeapi.NewServer("", "")
+	t.Cleanup(api.Close)
+
+	client, err := auroradns.NewClient(nil, auroradns.WithBaseURL(api.URL))
+	require.NoError(t, err)
+
+	zoneID := api.AddZone("example.com")
+	api.AddRecord(zoneID, fakeapi.Record{Type: "A", Name: "www", Content: "192.0.2.1", TTL: 300})
+
+	gateway, err := NewGateway(client, Config{
+		Keys: []Key{
+			{Name: "dhcp", Secret: dhcpSecret, Zones: []string{"Example.com."}},
+			{Name: "certbot.", Algorithm: dns.HmacSHA512, Secret: certbotSecret, Zones: []string{"example.org"}},
+		},
+		Logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+	require.NoError(t, err)
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := gateway.Server(ctx, "", "udp")
+	server.PacketConn = conn
+
+	started := make(chan struct{})
+	server.NotifyStartedFunc = func() { close(started) }
+
+	go func() { _ = server.ActivateAndServe() }()
+
+	<-started
+
+	t.Cleanup(func() { _ = server.Shutdown() })
+
+	return api, zoneID, conn.LocalAddr().String()
+}
+
+func exchange(t *testing.T, addr string, msg *dns.Msg, keyName, algorithm string) int {
+	t.Helper()
+
+	client := &dns.Client{
+		TsigSecret: map[string]string{"dhcp.": dhcpSecret, "certbot.": certbotSecret, "unknown.": dhcpSecret},
+	}
+
+	if keyName != "" {
+		msg.SetTsig(keyName, algorithm, 300, time.Now().Unix())
+	}
+
+	resp, _, err := client.Exchange(msg, addr)
+
+	// the client does not verify the signed NOTAUTH responses.
+	if errors.Is(err, dns.ErrAuth) {
+		return dns.RcodeNotAuth
+	}
+
+	require.NoError(t, err)
+
+	return resp.Rcode
+}
+
+func newRRs(t *testing.T, values ...string) []dns.RR {
+	t.Helper()
+
+	var rrs []dns.RR
+
+	for _, value := range values {
+		rr, err := dns.NewRR(value)
+		require.NoError(t, err)
+
+		rrs = append(rrs, rr)
+	}
+
+	return rrs
+}
+
+func TestGateway_update(t *testing.T) {
+	api, zoneID, addr := setupTest(t, t.Context())
+
+	// add a name, only if it is not in use.
+	msg := new(dns.Msg)
+	msg.SetUpdate("example.com.")
+	msg.NameNotUsed(newRRs(t, "host.example.com. A 192.0.2.10"))
+	msg.Insert(newRRs(t,
+		"host.example.com. 600 IN A 192.0.2.10",
+		`host.example.com. 600 IN TXT "dhcid"`,
+	))
+
+	assert.Equal(t, dns.RcodeSuccess, exchange(t, addr, msg, "dhcp.", dns.HmacSHA256))
+
+	msg = new(dns.Msg)
+	msg.SetUpdate("example.com.")
+	msg.NameNotUsed(newRRs(t, "host.example.com. A 192.0.2.10"))
+	msg.Insert(newRRs(t, "host.example.com. 600 IN A 192.0.2.11"))
+
+	assert.Equal(t, dns.RcodeYXDomain, exchange(t, addr, msg, "dhcp.", dns.HmacSHA256))
+
+	// replace an RRset, only if it has the expected value.
+	msg = new(dns.Msg)
+	msg.SetUpdate("example.com.")
+	msg.Used(newRRs(t, "www.example.com. A 192.0.2.9"))
+	msg.RemoveRRset(newRRs(t, "www.example.com. A 192.0.2.1"))
+	msg.Insert(newRRs(t, "www.example.com. 300 IN A 192.0.2.2"))
+
+	assert.Equal(t, dns.RcodeNXRrset, exchange(t, addr, msg, "dhcp.", dns.HmacSHA256))
+
+	msg = new(dns.Msg)
+	msg.SetUpdate("example.com.")
+	msg.Used(newRRs(t, "WWW.example.com. A 192.0.2.1"))
+	msg.RemoveRRset(newRRs(t, "www.example.com. A 192.0.2.1"))
+	msg.Insert(newRRs(t, "www.example.com. 300 IN A 192.0.2.2"))
+
+	assert.Equal(t, dns.RcodeSuccess, exchange(t, addr, msg, "dhcp.", dns.HmacSHA256))
+
+	// remove a single RR, and the apex: the SOA and NS records are kept.
+	msg = new(dns.Msg)
+	msg.SetUpdate("example.com.")
+	msg.RRsetUsed(newRRs(t, "host.example.com. TXT x"))
+	msg.Remove(newRRs(t, "host.example.com. A 192.0.2.10"))
+	msg.RemoveName(newRRs(t, "example.com. A 192.0.2.1"))
+
+	assert.Equal(t, dns.RcodeSuccess, exchange(t, addr, msg, "dhcp.", dns.HmacSHA256))
+
+	records := api.Records(zoneID)
+	require.Len(t, records, 4)
+
+	assert.Equal(t, "SOA", records[0].Type)
+	assert.Equal(t, "NS", records[1].Type)
+
+	expected := []fakeapi.Record{
+		{ID: "record-6", Type: "TXT", Name: "host", Content: "dhcid", TTL: 600},
+		{ID: "record-7", Type: "A", Name: "www", Content: "192.0.2.2", TTL: 300},
+	}
+	assert.Equal(t, expected, records[2:])
+
+	assert.Equal(t, []string{
+		"POST /zones/zone-1/records",
+		"POST /zones/zone-1/records",
+		"DELETE /zones/zone-1/records/record-4",
+		"POST /zones/zone-1/records",
+		"DELETE /zones/zone-1/records/record-5",
+	}, api.Mutations())
+}
+
+func TestGateway_update_add(t *testing.T) {
+	api, zoneID, addr := setupTest(t, t.Context())
+
+	// the duplicates are replaced with the new TTL, the apex NS records are ignored,
+	// and a CNAME is not added to a name with other data.
+	msg := new(dns.Msg)
+	msg.SetUpdate("example.com.")
+	msg.Insert(newRRs(t,
+		"www.example.com. 60 IN A 192.0.2.1",
+		"www.example.com. 60 IN CNAME example.net.",
+		"example.com. 60 IN NS ns.example.net.",
+		"mail.example.com. 300 IN MX 10 mx.example.net.",
+	))
+
+	assert.Equal(t, dns.RcodeSuccess, exchange(t, addr, msg, "dhcp.", dns.HmacSHA256))
+
+	records := api.Records(zoneID)
+	require.Len(t, records, 4)
+
+	assert.Equal(t, fakeapi.Record{ID: "record-4", Type: "A", Name: "www", Content: "192.0.2.1", TTL: 60}, records[2])
+	assert.Equal(t, fakeapi.Record{ID: "record-5", Type: "MX", Name: "mail", Content: "10 mx.example.net", TTL: 300}, records[3])
+}
+
+func TestGateway_update_canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	api, _, addr := setupTest(t, ctx)
+
+	msg := new(dns.Msg)
+	msg.SetUpdate("example.com.")
+	msg.Insert(newRRs(t, "host.example.com. 600 IN A 192.0.2.10"))
+
+	assert.Equal(t, dns.RcodeServerFailure, exchange(t, addr, msg, "dhcp.", dns.HmacSHA256))
+	assert.Empty(t, api.Mutations())
+}
+
+func TestGateway_update_errors(t *testing.T) {
+	_, _, addr := setupTest(t, t.Context())
+
+	testCases := []struct {
+		desc      string
+		zone      string
+		keyName   string
+		algorithm string
+		update    func(msg *dns.Msg)
+		expected  int
+	}{
+		{
+			desc:     "unsigned",
+			zone:     "example.com.",
+			expected: dns.RcodeRefused,
+		},
+		{
+			desc:      "unknown key",
+			zone:      "example.com.",
+			keyName:   "unknown.",
+			algorithm: dns.HmacSHA256,
+			expected:  dns.RcodeNotAuth,
+		},
+		{
+			desc:      "algorithm mismatch",
+			zone:      "example.com.",
+			keyName:   "dhcp.",
+			algorithm: dns.HmacSHA512,
+			expected:  dns.RcodeNotAuth,
+		},
+		{
+			desc:      "zone not allowed",
+			zone:      "example.com.",
+			keyName:   "certbot.",
+			algorithm: dns.HmacSHA512,
+			expected:  dns.RcodeRefused,
+		},
+		{
+			desc:      "zone not served",
+			zone:      "example.org.",
+			keyName:   "certbot.",
+			algorithm: dns.HmacSHA512,
+			expected:  dns.RcodeNotAuth,
+		},
+		{
+			desc:      "name not in the zone",
+			zone:      "example.com.",
+			keyName:   "dhcp.",
+			algorithm: dns.HmacSHA256,
+			update: func(msg *dns.Msg) {
+				msg.Insert(newRRs(t, "www.example.org. 300 IN A 192.0.2.1"))
+			},
+			expected: dns.RcodeNotZone,
+		},
+		{
+			desc:      "RRset exists",
+			zone:      "example.com.",
+			keyName:   "dhcp.",
+			algorithm: dns.HmacSHA256,
+			update: func(msg *dns.Msg) {
+				msg.RRsetNotUsed(newRRs(t, "www.example.com. A 192.0.2.1"))
+			},
+			expected: dns.RcodeYXRrset,
+		},
+		{
+			desc:      "name not in use",
+			zone:      "example.com.",
+			keyName:   "dhcp.",
+			algorithm: dns.HmacSHA256,
+			update: func(msg *dns.Msg) {
+				msg.NameUsed(newRRs(t, "host.example.com. A 192.0.2.1"))
+			},
+			expected: dns.RcodeNameError,
+		},
+		{
+			desc:      "meta type",
+			zone:      "example.com.",
+			keyName:   "dhcp.",
+			algorithm: dns.HmacSHA256,
+			update: func(msg *dns.Msg) {
+				msg.Ns = append(msg.Ns, &dns.ANY{Hdr: dns.RR_Header{Name: "www.example.com.", Rrtype: dns.TypeANY, Class: dns.ClassINET}})
+			},
+			expected: dns.RcodeFormatError,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			msg := new(dns.Msg)
+			msg.SetUpdate(test.zone)
+
+			if test.update != nil {
+				test.update(msg)
+			}
+
+			assert.Equal(t, test.expected, exchange(t, addr, msg, test.keyName, test.algorithm))
+		})
+	}
+}
+
+func TestGateway_query(t *testing.T) {
+	_, _, addr := setupTest(t, t.Context())
+
+	msg := new(dns.Msg)
+	msg.SetQuestion("www.example.com.", dns.TypeA)
+
+	assert.Equal(t, dns.RcodeNotImplemented, exchange(t, addr, msg, "dhcp.", dns.HmacSHA256))
+}
+
+func TestNewGateway_invalid(t *testing.T) {
+	client, err := auroradns.NewClient(nil)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc     string
+		config   Config
+		expected string
+	}{
+		{desc: "no key", config: Config{}, expected: "at least one key is required"},
+		{
+			desc:     "duplicate key",
+			config:   Config{Keys: []Key{{Name: "dhcp", Secret: dhcpSecret}, {Name: "DHCP.", Secret: dhcpSecret}}},
+			expected: "duplicate key dhcp.",
+		},
+		{desc: "no secret", config: Config{Keys: []Key{{Name: "dhcp"}}}, expected: "missing secret for the key dhcp."},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			_, err := NewGateway(client, test.config)
+			require.EqualError(t, err, test.expected)
+		})
+	}
+}
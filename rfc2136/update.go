@@ -0,0 +1,296 @@
+package rfc2136
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/miekg/dns"
+	"github.com/nrdcg/auroradns"
+)
+
+// update The processing of an UPDATE message, on the records of a zone.
+type update struct {
+	client   *auroradns.Client
+	zone     auroradns.Zone
+	zoneName string
+
+	// records the records of the zone, kept up to date with the changes.
+	records []auroradns.Record
+}
+
+// rrsetKey identifies an RRset: a name relative to the zone and a type.
+type rrsetKey struct {
+	name  string
+	rtype string
+}
+
+// checkPrerequisites Checks the prerequisite section (RFC 2136 section 3.2).
+func (u *update) checkPrerequisites(prerequisites []dns.RR) int {
+	// the value-dependent prerequisites are compared per RRset, once they are all collected.
+	expected := make(map[rrsetKey][]string)
+
+	for _, rr := range prerequisites {
+		hdr := rr.Header()
+
+		if hdr.Ttl != 0 {
+			return dns.RcodeFormatError
+		}
+
+		name, ok := u.relativeName(hdr.Name)
+		if !ok {
+			return dns.RcodeNotZone
+		}
+
+		rtype := dns.TypeToString[hdr.Rrtype]
+
+		switch hdr.Class {
+		case dns.ClassANY:
+			switch {
+			case hdr.Rdlength != 0:
+				return dns.RcodeFormatError
+			case hdr.Rrtype == dns.TypeANY && !u.nameInUse(name):
+				return dns.RcodeNameError
+			case hdr.Rrtype != dns.TypeANY && len(u.rrset(name, rtype)) == 0:
+				return dns.RcodeNXRrset
+			}
+
+		case dns.ClassNONE:
+			switch {
+			case hdr.Rdlength != 0:
+				return dns.RcodeFormatError
+			case hdr.Rrtype == dns.TypeANY && u.nameInUse(name):
+				return dns.RcodeYXDomain
+			case hdr.Rrtype != dns.TypeANY && len(u.rrset(name, rtype)) != 0:
+				return dns.RcodeYXRrset
+			}
+
+		case dns.ClassINET:
+			key := rrsetKey{name: name, rtype: rtype}
+			expected[key] = append(expected[key], content(rr))
+
+		default:
+			return dns.RcodeFormatError
+		}
+	}
+
+	for key, contents := range expected {
+		var live []string
+		for _, record := range u.rrset(key.name, key.rtype) {
+			live = append(live, record.Content)
+		}
+
+		slices.Sort(live)
+		slices.Sort(contents)
+
+		if !slices.Equal(slices.Compact(live), slices.Compact(contents)) {
+			return dns.RcodeNXRrset
+		}
+	}
+
+	return dns.RcodeSuccess
+}
+
+// prescan Checks the update section before any change (RFC 2136 section 3.4.1).
+func (u *update) prescan(changes []dns.RR) int {
+	for _, rr := range changes {
+		hdr := rr.Header()
+
+		if _, ok := u.relativeName(hdr.Name); !ok {
+			return dns.RcodeNotZone
+		}
+
+		switch hdr.Class {
+		case dns.ClassINET:
+			if isMetaType(hdr.Rrtype) {
+				return dns.RcodeFormatError
+			}
+
+		case dns.ClassANY:
+			if hdr.Ttl != 0 || hdr.Rdlength != 0 || (isMetaType(hdr.Rrtype) && hdr.Rrtype != dns.TypeANY) {
+				return dns.RcodeFormatError
+			}
+
+		case dns.ClassNONE:
+			if hdr.Ttl != 0 || isMetaType(hdr.Rrtype) {
+				return dns.RcodeFormatError
+			}
+
+		default:
+			return dns.RcodeFormatError
+		}
+	}
+
+	return dns.RcodeSuccess
+}
+
+// apply Applies the update section (RFC 2136 section 3.4.2), in order.
+// It stops at the first error: the changes applied before are not reverted.
+func (u *update) apply(ctx context.Context, changes []dns.RR) error {
+	for _, rr := range changes {
+		hdr := rr.Header()
+
+		name, _ := u.relativeName(hdr.Name)
+		rtype := dns.TypeToString[hdr.Rrtype]
+
+		var err error
+
+		switch hdr.Class {
+		case dns.ClassINET:
+			err = u.add(ctx, name, rtype, content(rr), int(hdr.Ttl))
+
+		case dns.ClassANY:
+			err = u.deleteMatching(ctx, func(record auroradns.Record) bool {
+				return record.Name == name && (hdr.Rrtype == dns.TypeANY || record.RecordType == rtype)
+			})
+
+		case dns.ClassNONE:
+			value := content(rr)
+
+			err = u.deleteMatching(ctx, func(record auroradns.Record) bool {
+				return record.Name == name && record.RecordType == rtype && record.Content == value
+			})
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// add Adds a record to an RRset: the duplicates are replaced, and a CNAME cannot coexist with other data.
+func (u *update) add(ctx context.Context, name, rtype, value string, ttl int) error {
+	if rtype == auroradns.RecordTypeSOA || (rtype == auroradns.RecordTypeNS && name == "") {
+		return nil
+	}
+
+	for _, record := range u.normalized() {
+		if record.Name != name {
+			continue
+		}
+
+		isCNAME := record.RecordType == auroradns.RecordTypeCNAME
+
+		switch {
+		// RFC 2136 section 3.4.2.2: a CNAME is not added to a name with other data, and the other data are not added to a CNAME.
+		case (rtype == auroradns.RecordTypeCNAME) != isCNAME:
+			return nil
+
+		// the CNAME is replaced.
+		case isCNAME && record.Content != value,
+			// the duplicate is replaced, with the new TTL.
+			record.RecordType == rtype && record.Content == value:
+			if record.Content == value && record.TTL == ttl {
+				return nil
+			}
+
+			return u.replace(ctx, record, value, ttl)
+		}
+	}
+
+	created, _, err := u.client.CreateRecordWithContext(ctx, u.zone.ID, auroradns.Record{RecordType: rtype, Name: name, Content: value, TTL: ttl})
+	if err != nil {
+		return fmt.Errorf("failed to create the record %s %q: %w", rtype, name, err)
+	}
+
+	u.records = append(u.records, *created)
+
+	return nil
+}
+
+func (u *update) replace(ctx context.Context, record auroradns.Record, value string, ttl int) error {
+	record.Content = value
+	record.TTL = ttl
+
+	updated, _, err := u.client.UpdateRecordWithContext(ctx, u.zone.ID, record.ID, record)
+	if err != nil {
+		return fmt.Errorf("failed to update the record %s %q: %w", record.RecordType, record.Name, err)
+	}
+
+	if updated.ID == "" {
+		updated.ID = record.ID
+	}
+
+	for i := range u.records {
+		if u.records[i].ID == record.ID {
+			u.records[i] = *updated
+		}
+	}
+
+	return nil
+}
+
+// deleteMatching Deletes the records matching the predicate (on the normalized records), except the SOA record and the apex NS records.
+func (u *update) deleteMatching(ctx context.Context, match func(record auroradns.Record) bool) error {
+	for _, record := range u.normalized() {
+		if record.RecordType == auroradns.RecordTypeSOA || (record.RecordType == auroradns.RecordTypeNS && record.Name == "") {
+			continue
+		}
+
+		if !match(record) {
+			continue
+		}
+
+		_, _, err := u.client.DeleteRecordWithContext(ctx, u.zone.ID, record.ID)
+		if err != nil {
+			return fmt.Errorf("failed to delete the record %s %q: %w", record.RecordType, record.Name, err)
+		}
+
+		u.records = slices.DeleteFunc(u.records, func(r auroradns.Record) bool { return r.ID == record.ID })
+	}
+
+	return nil
+}
+
+// normalized Returns the records of the zone, normalized (see auroradns.NormalizeRecord).
+func (u *update) normalized() []auroradns.Record {
+	records := make([]auroradns.Record, 0, len(u.records))
+
+	for _, record := range u.records {
+		records = append(records, auroradns.NormalizeRecord(record, u.zoneName))
+	}
+
+	return records
+}
+
+func (u *update) rrset(name, rtype string) []auroradns.Record {
+	var rrset []auroradns.Record
+
+	for _, record := range u.normalized() {
+		if record.Name == name && record.RecordType == rtype {
+			rrset = append(rrset, record)
+		}
+	}
+
+	return rrset
+}
+
+func (u *update) nameInUse(name string) bool {
+	return slices.ContainsFunc(u.normalized(), func(record auroradns.Record) bool { return record.Name == name })
+}
+
+// relativeName Returns the name relative to the zone, and false if the name is not in the zone.
+func (u *update) relativeName(fqdn string) (string, bool) {
+	name := auroradns.NormalizeZoneName(fqdn)
+
+	if name != u.zoneName && !strings.HasSuffix(name, "."+u.zoneName) {
+		return "", false
+	}
+
+	return auroradns.NormalizeName(name, u.zoneName), true
+}
+
+// content Returns the normalized content of a resource record, in the format of auroradns.Record.
+func content(rr dns.RR) string {
+	rtype := dns.TypeToString[rr.Header().Rrtype]
+
+	return auroradns.NormalizeContent(rtype, strings.TrimPrefix(rr.String(), rr.Header().String()))
+}
+
+// isMetaType Returns true for the types that cannot be stored (RFC 6895 section 3.1: the QTYPEs and the meta-TYPEs).
+func isMetaType(rrtype uint16) bool {
+	return rrtype == dns.TypeOPT || (rrtype >= 128 && rrtype <= 255)
+}
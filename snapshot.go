@@ -0,0 +1,437 @@
+package auroradns
+
+import (
+	"archive/tar"
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"time"
+)
+
+// SnapshotVersion the version of the snapshot archive format.
+const SnapshotVersion = 1
+
+const (
+	snapshotManifestFile = "manifest.json"
+	snapshotZonesDir     = "zones"
+)
+
+// Snapshot The zones, records and health checks of an account.
+type Snapshot struct {
+	Version   int
+	Timestamp time.Time
+	Zones     []ZoneSnapshot
+}
+
+// ZoneSnapshot The records and health checks of a zone.
+type ZoneSnapshot struct {
+	Zone         Zone          `json:"zone"`
+	Records      []Record      `json:"records"`
+	HealthChecks []HealthCheck `json:"health_checks,omitempty"`
+}
+
+// SnapshotManifest The manifest of a snapshot archive.
+type SnapshotManifest struct {
+	Version   int            `json:"version"`
+	Timestamp time.Time      `json:"timestamp"`
+	Files     []SnapshotFile `json:"files"`
+}
+
+// SnapshotFile A zone file of a snapshot archive.
+type SnapshotFile struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Snapshot Reads all the zones, with their records and health checks.
+// The zones are sorted by name, the records by name, type and content, and the health checks by ID.
+func (c *Client) Snapshot(ctx context.Context) (*Snapshot, error) {
+	zones, _, err := c.ListZonesWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &Snapshot{
+		Version:   SnapshotVersion,
+		Timestamp: time.Now().UTC().Truncate(time.Second),
+	}
+
+	for _, zone := range zones {
+		records, _, err := c.ListRecordsWithContext(ctx, zone.ID)
+		if err != nil {
+			return nil, fmt.Errorf("zone %s: %w", zone.Name, err)
+		}
+
+		healthChecks, err := c.listHealthChecks(ctx, zone.ID)
+		if err != nil {
+			return nil, fmt.Errorf("zone %s: %w", zone.Name, err)
+		}
+
+		slices.SortFunc(records, func(a, b Record) int {
+			return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.RecordType, b.RecordType), cmp.Compare(a.Content, b.Content))
+		})
+
+		slices.SortFunc(healthChecks, func(a, b HealthCheck) int {
+			return cmp.Compare(a.ID, b.ID)
+		})
+
+		if len(healthChecks) == 0 {
+			// omitted from the archive.
+			healthChecks = nil
+		}
+
+		snapshot.Zones = append(snapshot.Zones, ZoneSnapshot{Zone: zone, Records: records, HealthChecks: healthChecks})
+	}
+
+	slices.SortFunc(snapshot.Zones, func(a, b ZoneSnapshot) int {
+		return cmp.Compare(NormalizeZoneName(a.Zone.Name), NormalizeZoneName(b.Zone.Name))
+	})
+
+	return snapshot, nil
+}
+
+// WriteTo Writes the snapshot as a tar archive:
+// a manifest (manifest.json) with the version, the timestamp and the SHA-256 checksums of the zone files,
+// then one file per zone (zones/<name>.json).
+//
+// The archive is deterministic: the same snapshot always produces the same bytes.
+func (s *Snapshot) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+	tw := tar.NewWriter(cw)
+
+	manifest := SnapshotManifest{Version: s.Version, Timestamp: s.Timestamp}
+
+	var files [][]byte
+
+	for _, zone := range s.Zones {
+		data, err := json.MarshalIndent(zone, "", "  ")
+		if err != nil {
+			return cw.n, fmt.Errorf("failed to marshal the zone %s: %w", zone.Zone.Name, err)
+		}
+
+		data = append(data, '\n')
+
+		sum := sha256.Sum256(data)
+
+		manifest.Files = append(manifest.Files, SnapshotFile{
+			Name:   snapshotFileName(zone.Zone),
+			Size:   int64(len(data)),
+			SHA256: hex.EncodeToString(sum[:]),
+		})
+
+		files = append(files, data)
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return cw.n, fmt.Errorf("failed to marshal the manifest: %w", err)
+	}
+
+	err = s.writeFile(tw, snapshotManifestFile, append(data, '\n'))
+	if err != nil {
+		return cw.n, err
+	}
+
+	for i, file := range manifest.Files {
+		err = s.writeFile(tw, file.Name, files[i])
+		if err != nil {
+			return cw.n, err
+		}
+	}
+
+	err = tw.Close()
+	if err != nil {
+		return cw.n, err
+	}
+
+	return cw.n, nil
+}
+
+func (s *Snapshot) writeFile(tw *tar.Writer, name string, data []byte) error {
+	err := tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     0o644,
+		Size:     int64(len(data)),
+		ModTime:  s.Timestamp,
+		Format:   tar.FormatUSTAR,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+
+	_, err = tw.Write(data)
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// ReadSnapshot Reads a snapshot archive written by Snapshot.WriteTo.
+// The version and the checksums of the zone files are verified.
+func ReadSnapshot(r io.Reader) (*Snapshot, error) {
+	tr := tar.NewReader(r)
+
+	files := make(map[string][]byte)
+
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the snapshot: %w", err)
+		}
+
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", header.Name, err)
+		}
+
+		files[header.Name] = data
+	}
+
+	raw, ok := files[snapshotManifestFile]
+	if !ok {
+		return nil, errors.New("invalid snapshot: missing manifest")
+	}
+
+	var manifest SnapshotManifest
+
+	err := json.Unmarshal(raw, &manifest)
+	if err != nil {
+		return nil, fmt.Errorf("invalid snapshot manifest: %w", err)
+	}
+
+	if manifest.Version < 1 || manifest.Version > SnapshotVersion {
+		return nil, fmt.Errorf("unsupported snapshot version: %d", manifest.Version)
+	}
+
+	snapshot := &Snapshot{Version: manifest.Version, Timestamp: manifest.Timestamp}
+
+	for _, file := range manifest.Files {
+		data, ok := files[file.Name]
+		if !ok {
+			return nil, fmt.Errorf("invalid snapshot: missing %s", file.Name)
+		}
+
+		sum := sha256.Sum256(data)
+		if hex.EncodeToString(sum[:]) != file.SHA256 {
+			return nil, fmt.Errorf("invalid snapshot: checksum mismatch for %s", file.Name)
+		}
+
+		var zone ZoneSnapshot
+
+		err = json.Unmarshal(data, &zone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid snapshot file %s: %w", file.Name, err)
+		}
+
+		snapshot.Zones = append(snapshot.Zones, zone)
+	}
+
+	return snapshot, nil
+}
+
+// RestoreOptions The options of Restore.
+type RestoreOptions struct {
+	// Zone restores only the zone of the snapshot with this name.
+	Zone string
+
+	// As restores the zone (Zone is required) under this name.
+	As string
+
+	// Prune deletes the live records that are not in the snapshot.
+	Prune bool
+}
+
+// RestoreResult The changes made by Restore.
+type RestoreResult struct {
+	Zones []ZoneRestore
+}
+
+// ZoneRestore The changes made in a zone by Restore.
+type ZoneRestore struct {
+	// Zone the live zone.
+	Zone Zone
+
+	// Created true if the zone has been created.
+	Created bool
+
+	// HealthChecks the IDs of the live health checks, by ID of the health checks of the snapshot.
+	HealthChecks map[string]string
+
+	// Diff the changes applied to the records.
+	Diff *RecordDiff
+}
+
+// Restore Restores the zones of a snapshot.
+//
+// The missing zones are created.
+// The health checks of the snapshot are matched with the live health checks (on all their fields but the ID),
+// the missing ones are created, and the health check IDs of the records are remapped to the live IDs.
+// The records are restored with the changes from the live records to the records of the snapshot:
+// the live records absent from the snapshot are kept, unless Prune is set.
+//
+// It stops at the first error, and returns the changes made until then.
+func (c *Client) Restore(ctx context.Context, snapshot *Snapshot, options RestoreOptions) (*RestoreResult, error) {
+	if options.As != "" && options.Zone == "" {
+		return nil, errors.New("restoring under another name requires a zone")
+	}
+
+	zones := snapshot.Zones
+
+	if options.Zone != "" {
+		i := slices.IndexFunc(zones, func(z ZoneSnapshot) bool {
+			return NormalizeZoneName(z.Zone.Name) == NormalizeZoneName(options.Zone)
+		})
+		if i < 0 {
+			return nil, fmt.Errorf("zone %s not found in the snapshot", options.Zone)
+		}
+
+		zones = zones[i : i+1]
+	}
+
+	liveZones, _, err := c.ListZonesWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &RestoreResult{}
+
+	for _, zone := range zones {
+		name := zone.Zone.Name
+		if options.As != "" {
+			name = options.As
+		}
+
+		restored, err := c.restoreZone(ctx, liveZones, name, zone, options.Prune)
+		if restored != nil {
+			result.Zones = append(result.Zones, *restored)
+		}
+
+		if err != nil {
+			return result, fmt.Errorf("zone %s: %w", name, err)
+		}
+	}
+
+	return result, nil
+}
+
+func (c *Client) restoreZone(ctx context.Context, liveZones []Zone, name string, snapshot ZoneSnapshot, prune bool) (*ZoneRestore, error) {
+	restored := &ZoneRestore{HealthChecks: make(map[string]string)}
+
+	i := slices.IndexFunc(liveZones, func(z Zone) bool {
+		return NormalizeZoneName(z.Name) == NormalizeZoneName(name)
+	})
+
+	if i >= 0 {
+		restored.Zone = liveZones[i]
+	} else {
+		zone, _, err := c.CreateZoneWithContext(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+
+		restored.Zone = *zone
+		restored.Created = true
+	}
+
+	err := c.restoreHealthChecks(ctx, restored, snapshot.HealthChecks)
+	if err != nil {
+		return restored, err
+	}
+
+	desired := make([]Record, 0, len(snapshot.Records))
+
+	for _, record := range snapshot.Records {
+		if id, ok := restored.HealthChecks[record.HealthCheckID]; ok {
+			record.HealthCheckID = id
+		}
+
+		desired = append(desired, record)
+	}
+
+	diff, err := c.planZone(ctx, restored.Zone, desired)
+	if err != nil {
+		return restored, err
+	}
+
+	if !prune {
+		diff.Removed = nil
+	}
+
+	restored.Diff = diff
+
+	return restored, c.applyDiff(ctx, restored.Zone.ID, diff)
+}
+
+func (c *Client) restoreHealthChecks(ctx context.Context, restored *ZoneRestore, healthChecks []HealthCheck) error {
+	if len(healthChecks) == 0 {
+		return nil
+	}
+
+	live, err := c.listHealthChecks(ctx, restored.Zone.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, healthCheck := range healthChecks {
+		i := slices.IndexFunc(live, func(h HealthCheck) bool {
+			h.ID = healthCheck.ID
+			return h == healthCheck
+		})
+
+		if i >= 0 {
+			restored.HealthChecks[healthCheck.ID] = live[i].ID
+
+			// a live health check matches a single health check of the snapshot.
+			live = slices.Delete(live, i, i+1)
+
+			continue
+		}
+
+		request := healthCheck
+		request.ID = ""
+
+		created, err := c.createHealthCheck(ctx, restored.Zone.ID, request)
+		if err != nil {
+			return err
+		}
+
+		restored.HealthChecks[healthCheck.ID] = created.ID
+	}
+
+	return nil
+}
+
+func snapshotFileName(zone Zone) string {
+	name := NormalizeZoneName(zone.Name)
+	if name == "" {
+		name = zone.ID
+	}
+
+	return path.Join(snapshotZonesDir, name+".json")
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.n += int64(n)
+
+	return n, err
+}
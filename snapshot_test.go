@@ -0,0 +1,212 @@
+package auroradns
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/nrdcg/auroradns/internal/fakeapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupSnapshotTest(t *testing.T) (*Client, *fakeapi.Server) {
+	t.Helper()
+
+	client, server := setupFakeAPI(t)
+
+	zoneID := server.AddZone("example.org")
+	server.AddRecord(zoneID, fakeapi.Record{Type: "A", Name: "www", Content: "192.0.2.1", TTL: 300})
+
+	zoneID = server.AddZone("example.com")
+	healthCheckID := server.AddHealthCheck(zoneID, fakeapi.HealthCheck{"type": "HTTP", "ip_address": "192.0.2.1", "port": 80})
+	server.AddRecord(zoneID, fakeapi.Record{Type: "TXT", Name: "", Content: "v=spf1 -all", TTL: 300})
+	server.AddRecord(zoneID, fakeapi.Record{Type: "A", Name: "www", Content: "192.0.2.1", TTL: 300, HealthCheckID: healthCheckID})
+
+	return client, server
+}
+
+func TestClient_Snapshot(t *testing.T) {
+	client, _ := setupSnapshotTest(t)
+
+	snapshot, err := client.Snapshot(t.Context())
+	require.NoError(t, err)
+
+	assert.Equal(t, SnapshotVersion, snapshot.Version)
+	assert.WithinDuration(t, time.Now(), snapshot.Timestamp, time.Minute)
+
+	require.Len(t, snapshot.Zones, 2)
+
+	assert.Equal(t, Zone{ID: "zone-5", Name: "example.com"}, snapshot.Zones[0].Zone)
+	assert.Equal(t, Zone{ID: "zone-1", Name: "example.org"}, snapshot.Zones[1].Zone)
+
+	expected := []Record{
+		{ID: "record-10", RecordType: RecordTypeA, Name: "www", Content: "192.0.2.1", TTL: 300, HealthCheckID: "health-check-8"},
+		{ID: "record-7", RecordType: RecordTypeNS, Name: "", Content: "ns1.auroradns.eu.", TTL: 4800},
+		{ID: "record-6", RecordType: RecordTypeSOA, Name: "", Content: "ns1.auroradns.eu. hostmaster.example.com. 1 86400 7200 604800 300", TTL: 4800},
+		{ID: "record-9", RecordType: RecordTypeTXT, Name: "", Content: "v=spf1 -all", TTL: 300},
+	}
+
+	// sorted by name, then type.
+	assert.Equal(t, expected[1:], snapshot.Zones[0].Records[:3])
+	assert.Equal(t, expected[0], snapshot.Zones[0].Records[3])
+
+	assert.Equal(t, []HealthCheck{{ID: "health-check-8", Type: HealthCheckTypeHTTP, IPAddress: "192.0.2.1", Port: 80}}, snapshot.Zones[0].HealthChecks)
+}
+
+func TestSnapshot_WriteTo(t *testing.T) {
+	client, _ := setupSnapshotTest(t)
+
+	snapshot, err := client.Snapshot(t.Context())
+	require.NoError(t, err)
+
+	snapshot.Timestamp = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	buf := new(bytes.Buffer)
+
+	n, err := snapshot.WriteTo(buf)
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(buf.Len()), n)
+
+	// deterministic.
+	other := new(bytes.Buffer)
+
+	_, err = snapshot.WriteTo(other)
+	require.NoError(t, err)
+
+	assert.Equal(t, buf.Bytes(), other.Bytes())
+
+	var names []string
+
+	tr := tar.NewReader(bytes.NewReader(buf.Bytes()))
+
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		require.NoError(t, err)
+
+		assert.Equal(t, snapshot.Timestamp, header.ModTime.UTC())
+
+		names = append(names, header.Name)
+	}
+
+	assert.Equal(t, []string{"manifest.json", "zones/example.com.json", "zones/example.org.json"}, names)
+
+	read, err := ReadSnapshot(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	assert.Equal(t, snapshot, read)
+}
+
+func TestReadSnapshot_checksum(t *testing.T) {
+	snapshot := &Snapshot{
+		Version:   SnapshotVersion,
+		Timestamp: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Zones: []ZoneSnapshot{
+			{Zone: Zone{ID: "zone-1", Name: "example.com"}, Records: []Record{{RecordType: RecordTypeA, Name: "www", Content: "192.0.2.1"}}},
+		},
+	}
+
+	buf := new(bytes.Buffer)
+
+	_, err := snapshot.WriteTo(buf)
+	require.NoError(t, err)
+
+	corrupted := bytes.Replace(buf.Bytes(), []byte("192.0.2.1"), []byte("192.0.2.9"), 1)
+
+	_, err = ReadSnapshot(bytes.NewReader(corrupted))
+	require.EqualError(t, err, "invalid snapshot: checksum mismatch for zones/example.com.json")
+}
+
+func TestClient_Restore(t *testing.T) {
+	client, server := setupSnapshotTest(t)
+
+	snapshot, err := client.Snapshot(t.Context())
+	require.NoError(t, err)
+
+	// lose a zone, change another.
+	_, _, err = client.DeleteZoneWithContext(t.Context(), "zone-5")
+	require.NoError(t, err)
+
+	server.AddRecord("zone-1", fakeapi.Record{Type: "A", Name: "api", Content: "192.0.2.2", TTL: 300})
+
+	result, err := client.Restore(t.Context(), snapshot, RestoreOptions{})
+	require.NoError(t, err)
+
+	require.Len(t, result.Zones, 2)
+
+	restored := result.Zones[0]
+	assert.True(t, restored.Created)
+	assert.Equal(t, "example.com", restored.Zone.Name)
+	assert.Equal(t, map[string]string{"health-check-8": "health-check-15"}, restored.HealthChecks)
+
+	records := server.Records(restored.Zone.ID)
+	require.Len(t, records, 4)
+	assert.Equal(t, "health-check-15", records[3].HealthCheckID)
+
+	// the records absent from the snapshot are kept.
+	assert.False(t, result.Zones[1].Created)
+	assert.True(t, result.Zones[1].Diff.IsEmpty())
+	assert.Len(t, server.Records("zone-1"), 4)
+
+	// idempotent.
+	before := len(server.Mutations())
+
+	_, err = client.Restore(t.Context(), snapshot, RestoreOptions{})
+	require.NoError(t, err)
+
+	assert.Len(t, server.Mutations(), before)
+}
+
+func TestClient_Restore_prune(t *testing.T) {
+	client, server := setupSnapshotTest(t)
+
+	snapshot, err := client.Snapshot(t.Context())
+	require.NoError(t, err)
+
+	server.AddRecord("zone-1", fakeapi.Record{Type: "A", Name: "api", Content: "192.0.2.2", TTL: 300})
+
+	result, err := client.Restore(t.Context(), snapshot, RestoreOptions{Zone: "example.org.", Prune: true})
+	require.NoError(t, err)
+
+	require.Len(t, result.Zones, 1)
+	assert.Len(t, result.Zones[0].Diff.Removed, 1)
+
+	assert.Len(t, server.Records("zone-1"), 3)
+}
+
+func TestClient_Restore_as(t *testing.T) {
+	client, server := setupSnapshotTest(t)
+
+	snapshot, err := client.Snapshot(t.Context())
+	require.NoError(t, err)
+
+	result, err := client.Restore(t.Context(), snapshot, RestoreOptions{Zone: "example.com", As: "example.net"})
+	require.NoError(t, err)
+
+	require.Len(t, result.Zones, 1)
+
+	restored := result.Zones[0]
+	assert.True(t, restored.Created)
+	assert.Equal(t, "example.net", restored.Zone.Name)
+
+	records := server.Records(restored.Zone.ID)
+	require.Len(t, records, 4)
+
+	assert.Len(t, server.Zones(), 3)
+	assert.Len(t, server.HealthChecks(restored.Zone.ID), 1)
+	assert.Len(t, server.HealthChecks("zone-5"), 1)
+
+	_, err = client.Restore(t.Context(), snapshot, RestoreOptions{As: "example.net"})
+	require.EqualError(t, err, "restoring under another name requires a zone")
+
+	_, err = client.Restore(t.Context(), snapshot, RestoreOptions{Zone: "example.net"})
+	require.EqualError(t, err, "zone example.net not found in the snapshot")
+}
@@ -0,0 +1,144 @@
+// Package tracing traces the calls of an auroradns.Client with OpenTelemetry.
+//
+// Each call creates a client span named after the operation (e.g. "auroradns.CreateRecord"),
+// and the trace context is injected in the headers of the request.
+// The Aurora DNS signature only covers the method, the path and the date,
+// so the injected headers do not break the signing done by auroradns.TokenTransport.
+//
+//	client, err := auroradns.NewClient(tr.Client(), tracing.WithTracing())
+package tracing
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/nrdcg/auroradns"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/nrdcg/auroradns/tracing"
+
+// Attribute keys.
+const (
+	AttrOperation  = attribute.Key("auroradns.operation")
+	AttrZoneID     = attribute.Key("auroradns.zone.id")
+	AttrRecordID   = attribute.Key("auroradns.record.id")
+	AttrRecordType = attribute.Key("auroradns.record.type")
+	AttrRetryCount = attribute.Key("auroradns.retry.count")
+	AttrErrorCode  = attribute.Key("auroradns.error.code")
+
+	// OpenTelemetry semantic conventions.
+
+	attrHTTPMethod     = attribute.Key("http.request.method")
+	attrHTTPStatusCode = attribute.Key("http.response.status_code")
+	attrServerAddress  = attribute.Key("server.address")
+	attrURLFull        = attribute.Key("url.full")
+	attrErrorType      = attribute.Key("error.type")
+)
+
+type config struct {
+	tracerProvider trace.TracerProvider
+	propagator     propagation.TextMapPropagator
+}
+
+// Option Type of a tracing option.
+type Option func(*config)
+
+// WithTracerProvider Allows to define the tracer provider (otel.GetTracerProvider by default).
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(c *config) {
+		c.tracerProvider = provider
+	}
+}
+
+// WithPropagator Allows to define the propagator used to inject the trace context (otel.GetTextMapPropagator by default).
+func WithPropagator(propagator propagation.TextMapPropagator) Option {
+	return func(c *config) {
+		c.propagator = propagator
+	}
+}
+
+// WithTracing Traces the calls of the client (see Middleware).
+func WithTracing(opts ...Option) auroradns.Option {
+	return auroradns.WithMiddleware(Middleware(opts...))
+}
+
+// Middleware Creates a middleware that creates a span for each call.
+func Middleware(opts ...Option) auroradns.Middleware {
+	cfg := &config{}
+
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	if cfg.tracerProvider == nil {
+		cfg.tracerProvider = otel.GetTracerProvider()
+	}
+
+	if cfg.propagator == nil {
+		cfg.propagator = otel.GetTextMapPropagator()
+	}
+
+	tracer := cfg.tracerProvider.Tracer(instrumentationName)
+
+	return func(next auroradns.Handler) auroradns.Handler {
+		return func(call *auroradns.Call) (*http.Response, error) {
+			req := call.Request
+
+			attrs := []attribute.KeyValue{
+				AttrOperation.String(call.Operation),
+				attrHTTPMethod.String(req.Method),
+				attrServerAddress.String(req.URL.Hostname()),
+				attrURLFull.String(req.URL.String()),
+			}
+
+			if call.ZoneID != "" {
+				attrs = append(attrs, AttrZoneID.String(call.ZoneID))
+			}
+
+			if call.RecordID != "" {
+				attrs = append(attrs, AttrRecordID.String(call.RecordID))
+			}
+
+			if call.RecordType != "" {
+				attrs = append(attrs, AttrRecordType.String(call.RecordType))
+			}
+
+			ctx, span := tracer.Start(req.Context(), "auroradns."+call.Operation,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(attrs...),
+			)
+			defer span.End()
+
+			call.Request = req.WithContext(ctx)
+
+			cfg.propagator.Inject(ctx, propagation.HeaderCarrier(call.Request.Header))
+
+			resp, err := next(call)
+
+			span.SetAttributes(AttrRetryCount.Int(call.Retries))
+
+			if resp != nil {
+				span.SetAttributes(attrHTTPStatusCode.Int(resp.StatusCode))
+			}
+
+			if err != nil {
+				var apiErr *auroradns.ResponseError
+				if errors.As(err, &apiErr) {
+					span.SetAttributes(AttrErrorCode.String(apiErr.ErrorCode), attrErrorType.String(apiErr.ErrorCode))
+				} else {
+					span.SetAttributes(attrErrorType.String("error"))
+				}
+
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+
+			return resp, err
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package tracing
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/nrdcg/auroradns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func setupTest(t *testing.T, handler http.HandlerFunc) (*auroradns.Client, *tracetest.SpanRecorder) {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	tr, err := auroradns.NewTokenTransport("key", "secret")
+	require.NoError(t, err)
+
+	client, err := auroradns.NewClient(tr.Client(),
+		auroradns.WithBaseURL(server.URL),
+		auroradns.WithRetry(1, time.Millisecond),
+		WithTracing(WithTracerProvider(provider), WithPropagator(propagation.TraceContext{})),
+	)
+	require.NoError(t, err)
+
+	return client, recorder
+}
+
+// checkSignature verifies the Authorization header of a request.
+func checkSignature(req *http.Request) error {
+	timestamp, err := time.Parse("20060102T150405Z", req.Header.Get("X-AuroraDNS-Date"))
+	if err != nil {
+		return err
+	}
+
+	tr, err := auroradns.NewTokenTransport("key", "secret")
+	if err != nil {
+		return err
+	}
+
+	expected := httptest.NewRequest(req.Method, req.URL.String(), http.NoBody)
+
+	err = tr.Sign(expected, timestamp)
+	if err != nil {
+		return err
+	}
+
+	if expected.Header.Get("Authorization") != req.Header.Get("Authorization") {
+		return fmt.Errorf("invalid signature: %s", req.Header.Get("Authorization"))
+	}
+
+	return nil
+}
+
+func TestWithTracing(t *testing.T) {
+	var traceparent string
+
+	calls := 0
+
+	client, recorder := setupTest(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+
+		err := checkSignature(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		traceparent = r.Header.Get("Traceparent")
+
+		if calls == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":"identifier-record-1","type":"TXT","name":"foo","ttl":300}`))
+	})
+
+	record := auroradns.Record{RecordType: auroradns.RecordTypeTXT, Name: "foo", Content: "bar", TTL: 300}
+
+	_, _, err := client.CreateRecordWithContext(t.Context(), "identifier-zone-1", record)
+	require.NoError(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+
+	span := spans[0]
+
+	assert.Equal(t, "auroradns.CreateRecord", span.Name())
+	assert.Equal(t, codes.Unset, span.Status().Code)
+
+	attrs := attribute.NewSet(span.Attributes()...)
+
+	assertAttribute(t, attrs, AttrOperation, "CreateRecord")
+	assertAttribute(t, attrs, AttrZoneID, "identifier-zone-1")
+	assertAttribute(t, attrs, AttrRecordType, "TXT")
+	assertAttribute(t, attrs, AttrRetryCount, int64(1))
+	assertAttribute(t, attrs, attrHTTPStatusCode, int64(http.StatusCreated))
+	assertAttribute(t, attrs, attrHTTPMethod, http.MethodPost)
+
+	expected := fmt.Sprintf("00-%s-%s-01", span.SpanContext().TraceID(), span.SpanContext().SpanID())
+	assert.Equal(t, expected, traceparent)
+}
+
+func TestWithTracing_error(t *testing.T) {
+	client, recorder := setupTest(t, func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error":"NotFoundError","errormsg":"zone not found"}`))
+	})
+
+	_, _, err := client.DeleteZoneWithContext(t.Context(), "identifier-zone-1")
+	require.Error(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+
+	span := spans[0]
+
+	assert.Equal(t, "auroradns.DeleteZone", span.Name())
+	assert.Equal(t, codes.Error, span.Status().Code)
+	assert.Equal(t, "NotFoundError - zone not found", span.Status().Description)
+
+	attrs := attribute.NewSet(span.Attributes()...)
+
+	assertAttribute(t, attrs, AttrErrorCode, "NotFoundError")
+	assertAttribute(t, attrs, AttrRetryCount, int64(0))
+	assertAttribute(t, attrs, attrHTTPStatusCode, int64(http.StatusNotFound))
+}
+
+func assertAttribute(t *testing.T, attrs attribute.Set, key attribute.Key, expected any) {
+	t.Helper()
+
+	value, ok := attrs.Value(key)
+	require.True(t, ok, "missing attribute %s", key)
+
+	assert.Equal(t, expected, value.AsInterface())
+}
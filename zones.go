@@ -35,7 +35,7 @@ func (c *Client) CreateZoneWithContext(ctx context.Context, domain string) (*Zon
 
 	zone := new(Zone)
 
-	resp, err := c.do(req, zone)
+	resp, err := c.do(&Call{Operation: OperationCreateZone, Request: req}, zone)
 	if err != nil {
 		return nil, resp, err
 	}
@@ -57,7 +57,7 @@ func (c *Client) DeleteZoneWithContext(ctx context.Context, zoneID string) (bool
 		return false, nil, err
 	}
 
-	resp, err := c.do(req, nil)
+	resp, err := c.do(&Call{Operation: OperationDeleteZone, ZoneID: zoneID, Request: req}, nil)
 	if err != nil {
 		return false, resp, err
 	}
@@ -81,7 +81,7 @@ func (c *Client) ListZonesWithContext(ctx context.Context) ([]Zone, *http.Respon
 
 	var zones []Zone
 
-	resp, err := c.do(req, &zones)
+	resp, err := c.do(&Call{Operation: OperationListZones, Request: req}, &zones)
 	if err != nil {
 		return nil, resp, err
 	}